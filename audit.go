@@ -0,0 +1,116 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// EmitAudit emits an audit-level event named action. Audit events are never
+// sampled, ship at PriorityCritical, and form a per-monitor hash chain: each
+// carries audit_prev_hash (the previous audit event's audit_hash, empty for the
+// first) and
+//
+//	audit_hash = hex(SHA-256(audit_prev_hash + canonical))
+//
+// where canonical is the event's JSON without the audit_hash field, with object
+// keys sorted, no insignificant whitespace, numbers as written, and no HTML
+// escaping of <, >, and &. A verifier recomputes each hash and follows the
+// audit_prev_hash links; an altered event fails its hash and a missing one
+// breaks the chain. Events emitted with WithLevel(LevelAudit) are chained too.
+//
+// The hash is computed after Processors and FlattenData run, so it covers the
+// event as output.
+func EmitAudit(ctx context.Context, action string, data map[string]any) {
+	defaultMonitor.emitAudit(ctx, action, data, 2)
+}
+
+// EmitAudit emits an audit event through m, chained with m's other audit
+// events, like the package-level EmitAudit.
+func (m *Monitor) EmitAudit(ctx context.Context, action string, data map[string]any) {
+	m.emitAudit(ctx, action, data, 2)
+}
+
+// emitAudit implements EmitAudit for callers at the given depth.
+func (m *Monitor) emitAudit(ctx context.Context, action string, data map[string]any, callerDepth int) {
+	cfg := m.activeConfig()
+	if cfg == nil {
+		return
+	}
+	m.counts.add(action)
+	if !m.hasSinks(cfg) {
+		nextRequestSeq(ctx)
+		return
+	}
+
+	var eventData any
+	if data != nil {
+		eventData = data
+	}
+	event := newEvent(ctx, cfg, action, eventData, LevelAudit)
+
+	if captureSourceEnabled(cfg) {
+		attachSourceLocation(&event, callerDepth+1)
+	}
+	if cfg.CaptureCaller {
+		event.Caller = callerLocation(callerDepth + 1)
+	}
+
+	m.dispatchEvent(event)
+}
+
+// chainAudit links event into m's audit hash chain, setting AuditPrevHash and
+// AuditHash. Concurrent audit events each link to a distinct predecessor.
+func (m *Monitor) chainAudit(event *Event) {
+	for {
+		prevPtr := m.lastAuditHash.Load()
+		prev := ""
+		if prevPtr != nil {
+			prev = *prevPtr
+		}
+
+		event.AuditPrevHash = prev
+		event.AuditHash = ""
+		canonical, err := canonicalJSON(event)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monitor: failed to hash audit event: %v\n", err)
+			return
+		}
+		sum := sha256.Sum256(append([]byte(prev), canonical...))
+		hash := hex.EncodeToString(sum[:])
+
+		if m.lastAuditHash.CompareAndSwap(prevPtr, &hash) {
+			event.AuditHash = hash
+			return
+		}
+	}
+}
+
+// canonicalJSON returns the event's JSON with object keys sorted, no
+// insignificant whitespace, numbers as written, and no HTML escaping.
+func canonicalJSON(event *Event) ([]byte, error) {
+	raw, err := event.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	// Round-trip through generic values, whose map keys encoding/json sorts
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+// verifyAuditLine recomputes an audit event's hash from its shipped JSON line
+// the way an external verifier would, returning the stated prev hash, the
+// stated hash, and the recomputed hash.
+func verifyAuditLine(t *testing.T, line []byte) (prev, stated, computed string) {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		t.Fatalf("decode %s: %v", line, err)
+	}
+	stated, _ = fields["audit_hash"].(string)
+	prev, _ = fields["audit_prev_hash"].(string)
+	delete(fields, "audit_hash")
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(fields); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	sum := sha256.Sum256(append([]byte(prev), bytes.TrimSuffix(buf.Bytes(), []byte("\n"))...))
+	return prev, stated, hex.EncodeToString(sum[:])
+}
+
+func TestEmitAudit(t *testing.T) {
+	if err := Init(Config{Service: "test-audit", DisableStdout: true, SampleRate: 0.0001}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	ctx := WithUserID(context.Background(), "admin-1")
+	events := Captured(func() {
+		EmitAudit(ctx, "user.role_changed", map[string]any{"role": "<admin>", "count": 12345678901234567})
+		Emit(ctx, "test.regular", nil)
+		EmitAudit(ctx, "user.deleted", nil)
+	})
+
+	var audits []Event
+	for _, e := range events {
+		if e.Level == LevelAudit {
+			audits = append(audits, e)
+		}
+	}
+	if len(audits) != 2 {
+		t.Fatalf("got %d audit events, want 2 (audit events are never sampled)", len(audits))
+	}
+	if audits[0].Name != "user.role_changed" || audits[0].priority != PriorityCritical {
+		t.Errorf("first audit = %+v, want user.role_changed at PriorityCritical", audits[0])
+	}
+	if audits[1].AuditPrevHash != audits[0].AuditHash {
+		t.Errorf("second audit_prev_hash = %q, want first audit_hash %q", audits[1].AuditPrevHash, audits[0].AuditHash)
+	}
+
+	for i, e := range audits {
+		line, err := e.ToJSON()
+		if err != nil {
+			t.Fatalf("ToJSON() error = %v", err)
+		}
+		_, stated, computed := verifyAuditLine(t, line)
+		if stated == "" || stated != computed {
+			t.Errorf("audit %d: audit_hash = %q, recomputed %q", i, stated, computed)
+		}
+	}
+
+	t.Run("tampering detected", func(t *testing.T) {
+		tampered := audits[0]
+		tampered.Data = map[string]any{"role": "viewer", "count": 12345678901234567}
+		line, _ := tampered.ToJSON()
+		_, stated, computed := verifyAuditLine(t, line)
+		if stated == computed {
+			t.Error("altered audit event still verifies")
+		}
+	})
+}
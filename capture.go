@@ -0,0 +1,135 @@
+package monitor
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// globalCapture receives events instead of stdout and the shipper while a
+// Capture is installed.
+var globalCapture atomic.Pointer[Capture]
+
+// Capture records dispatched events in place of stdout and the shipper. It is
+// created by NewCapture; Captured uses one for the duration of a function.
+type Capture struct {
+	mu     sync.Mutex
+	events []Event
+
+	// changed is closed and replaced whenever an event is added, waking WaitFor.
+	changed chan struct{}
+
+	prev *Capture
+	once sync.Once
+}
+
+// NewCapture installs and returns a Capture. Until Close is called, events
+// are recorded instead of being written to stdout or shipped. Events are
+// taken after processors, redaction, and FlattenData, just before they would
+// be serialized, so they match what would have been sent.
+//
+// Init must have been called. A Capture swaps global state, capturing events
+// from every Monitor, and is meant for tests: don't use it from parallel
+// tests, and Close captures in the reverse order they were created.
+//
+//	c := monitor.NewCapture()
+//	defer c.Close()
+//	handler.ServeHTTP(rec, req)
+//	if got := c.Named("user.created"); len(got) != 1 {
+//	    t.Errorf("user.created emitted %d times, want 1", len(got))
+//	}
+func NewCapture() *Capture {
+	c := &Capture{changed: make(chan struct{})}
+	c.prev = globalCapture.Swap(c)
+	return c
+}
+
+// Close uninstalls the capture, restoring the sink that was active when it
+// was created. Recorded events remain readable. Calling Close again does nothing.
+func (c *Capture) Close() {
+	c.once.Do(func() {
+		globalCapture.Store(c.prev)
+	})
+}
+
+func (c *Capture) add(event Event) {
+	c.mu.Lock()
+	c.events = append(c.events, event)
+	close(c.changed)
+	c.changed = make(chan struct{})
+	c.mu.Unlock()
+}
+
+// Events returns a copy of the recorded events, in order.
+func (c *Capture) Events() []Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Event(nil), c.events...)
+}
+
+// Named returns the recorded events with the given name, in order.
+func (c *Capture) Named(name string) []Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []Event
+	for _, e := range c.events {
+		if e.Name == name {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Reset discards the recorded events.
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	c.events = nil
+	c.mu.Unlock()
+}
+
+// WaitFor returns the first recorded event with the given name, waiting up to
+// timeout for one to be emitted (e.g., from a background goroutine). It
+// reports false if none arrived in time.
+func (c *Capture) WaitFor(name string, timeout time.Duration) (Event, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		for _, e := range c.events {
+			if e.Name == name {
+				c.mu.Unlock()
+				return e, true
+			}
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return Event{}, false
+		}
+	}
+}
+
+// Captured runs fn and returns the events it emitted, in order. While fn runs,
+// events are captured instead of being written to stdout or shipped; the
+// previous sink is restored when fn returns. Processors and FlattenData still
+// apply, so captured events match what would have been sent.
+//
+// Init must have been called. Captured swaps global state, capturing events
+// from every Monitor, and is meant for tests: do not call it concurrently, or
+// while other goroutines emit events you do not want captured. Use NewCapture
+// to record across a test rather than around one function.
+//
+//	events := monitor.Captured(func() {
+//	    handler.ServeHTTP(rec, req)
+//	})
+func Captured(fn func()) []Event {
+	c := NewCapture()
+	defer c.Close()
+
+	fn()
+
+	return c.Events()
+}
@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCaptured(t *testing.T) {
+	rt := &recordingTransport{}
+	if err := Init(Config{Service: "test-captured", DisableStdout: true, Transport: rt}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	ctx := WithRequestID(context.Background(), "req-captured")
+	events := Captured(func() {
+		Emit(ctx, "test.first", map[string]any{"n": 1})
+		Emit(ctx, "test.second", nil, WithLevel(LevelWarn))
+	})
+
+	if len(events) != 2 {
+		t.Fatalf("Captured() returned %d events, want 2", len(events))
+	}
+	if events[0].Name != "test.first" || events[1].Name != "test.second" {
+		t.Errorf("names = %q, %q, want test.first, test.second", events[0].Name, events[1].Name)
+	}
+	if events[0].Service != "test-captured" || events[0].RequestID != "req-captured" {
+		t.Errorf("event = %+v, want service and request_id resolved", events[0])
+	}
+	if events[1].Level != LevelWarn {
+		t.Errorf("level = %v, want %v", events[1].Level, LevelWarn)
+	}
+
+	// Captured events are not shipped; the transport sees only later events
+	Emit(ctx, "test.after", nil)
+	Flush()
+	if got := rt.eventCount(); got != 1 {
+		t.Errorf("transport received %d events, want 1 (only the event after Captured)", got)
+	}
+	if globalCapture.Load() != nil {
+		t.Error("capture sink not restored after Captured returned")
+	}
+}
+
+func TestCapturedNested(t *testing.T) {
+	if err := Init(Config{Service: "test-captured", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	var inner []Event
+	outer := Captured(func() {
+		Emit(context.Background(), "test.outer", nil)
+		inner = Captured(func() {
+			Emit(context.Background(), "test.inner", nil)
+		})
+		Emit(context.Background(), "test.outer", nil)
+	})
+
+	if len(inner) != 1 || inner[0].Name != "test.inner" {
+		t.Errorf("inner = %v, want [test.inner]", inner)
+	}
+	if len(outer) != 2 {
+		t.Errorf("outer captured %d events, want 2", len(outer))
+	}
+}
+
+func TestNewCapture(t *testing.T) {
+	rt := &recordingTransport{}
+	if err := Init(Config{Service: "test-capture", Transport: rt}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	c := NewCapture()
+	defer c.Close()
+
+	Emit(context.Background(), "user.created", map[string]any{"id": 1})
+	Emit(context.Background(), "user.updated", nil)
+	Emit(context.Background(), "user.created", map[string]any{"id": 2})
+
+	if got := c.Events(); len(got) != 3 || got[1].Name != "user.updated" {
+		t.Fatalf("Events() = %v, want 3 events in order", got)
+	}
+	if got := c.Named("user.created"); len(got) != 2 || got[1].Data.(map[string]any)["id"] != 2 {
+		t.Errorf("Named(user.created) = %v, want both user.created events", got)
+	}
+
+	c.Reset()
+	if got := c.Events(); len(got) != 0 {
+		t.Errorf("Events() after Reset = %d events, want 0", len(got))
+	}
+
+	Flush()
+	if got := rt.eventCount(); got != 0 {
+		t.Errorf("transport received %d events while capturing, want 0", got)
+	}
+
+	c.Close()
+	c.Close()
+	if globalCapture.Load() != nil {
+		t.Error("capture sink not restored after Close")
+	}
+}
+
+func TestCaptureWaitFor(t *testing.T) {
+	if err := Init(Config{Service: "test-capture", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	c := NewCapture()
+	defer c.Close()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		Emit(context.Background(), "test.other", nil)
+		Emit(context.Background(), "job.done", map[string]any{"ok": true})
+	}()
+
+	event, ok := c.WaitFor("job.done", 5*time.Second)
+	if !ok {
+		t.Fatal("WaitFor(job.done) timed out")
+	}
+	if event.Data.(map[string]any)["ok"] != true {
+		t.Errorf("WaitFor returned %+v, want the job.done event", event)
+	}
+
+	start := time.Now()
+	if _, ok := c.WaitFor("never.emitted", 50*time.Millisecond); ok {
+		t.Error("WaitFor(never.emitted) reported an event")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("WaitFor returned after %v, want it to wait out the 50ms timeout", elapsed)
+	}
+}
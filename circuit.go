@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states reported in ShipperStats.CircuitState.
+const (
+	// CircuitClosed: batches are shipped normally.
+	CircuitClosed = "closed"
+
+	// CircuitOpen: the endpoint failed CircuitThreshold times in a row, so
+	// batches are spilled or dropped without being sent until CircuitCooldown
+	// has passed.
+	CircuitOpen = "open"
+
+	// CircuitHalfOpen: the cooldown has passed and the next batch is sent as a
+	// single-attempt probe. Success closes the circuit; failure reopens it.
+	CircuitHalfOpen = "half_open"
+)
+
+// defaultCircuitCooldown is the open period when Config.CircuitCooldown is unset.
+const defaultCircuitCooldown = 30 * time.Second
+
+// ErrCircuitOpen is reported as ShipperStats.LastFlushError for batches the
+// circuit breaker kept from the endpoint.
+var ErrCircuitOpen = errors.New("monitor: ingest circuit breaker open")
+
+// circuitBreaker stops delivery attempts to an endpoint that keeps failing.
+// A nil *circuitBreaker (Config.CircuitThreshold unset) always allows
+// delivery.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+}
+
+// newCircuitBreaker returns the breaker configured by cfg, or nil if
+// CircuitThreshold is not positive.
+func newCircuitBreaker(cfg *Config) *circuitBreaker {
+	if cfg.CircuitThreshold <= 0 {
+		return nil
+	}
+	cooldown := cfg.CircuitCooldown
+	if cooldown <= 0 {
+		cooldown = defaultCircuitCooldown
+	}
+	return &circuitBreaker{threshold: cfg.CircuitThreshold, cooldown: cooldown, state: CircuitClosed}
+}
+
+// allow reports whether a delivery attempt may be made. Once the cooldown has
+// passed, an open circuit goes half-open and allows one probe.
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen {
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+	}
+	return true
+}
+
+// success records an attempt the endpoint answered, closing the circuit.
+func (b *circuitBreaker) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != CircuitClosed {
+		fmt.Fprintf(os.Stderr, "monitor: ingest circuit closed\n")
+	}
+	b.state = CircuitClosed
+	b.failures = 0
+}
+
+// failure records a failed attempt, opening the circuit once threshold
+// consecutive attempts have failed or a half-open probe fails.
+func (b *circuitBreaker) failure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.failures >= b.threshold) {
+		fmt.Fprintf(os.Stderr, "monitor: ingest circuit open after %d consecutive failures, pausing delivery for %v\n", b.failures, b.cooldown)
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// currentState returns the breaker's state, reporting an open circuit whose
+// cooldown has passed as half-open.
+func (b *circuitBreaker) currentState() string {
+	if b == nil {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// shortCircuit handles a batch the open circuit kept from the endpoint: it
+// is spilled to disk when SpillDir is set, otherwise dropped.
+func (s *shipper) shortCircuit(batch []Event) error {
+	s.attemptErr = ErrCircuitOpen
+	s.deliveryOK.Store(false)
+	if s.spill != nil {
+		var unspilled []Event
+		for _, event := range batch {
+			evicted, err := s.spill.write(event)
+			if len(evicted) > 0 {
+				s.emitBatchDropped(DropReasonSpillFull, evicted)
+				s.dropped(DropReasonSpillFull, evicted...)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "monitor: failed to spill event: %v\n", err)
+				unspilled = append(unspilled, event)
+			}
+		}
+		if batch = unspilled; len(batch) == 0 {
+			return nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "monitor: ingest circuit open, dropping %d events\n", len(batch))
+	s.emitBatchDropped(DropReasonCircuitOpen, batch)
+	s.dropped(DropReasonCircuitOpen, batch...)
+	return nil
+}
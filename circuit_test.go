@@ -0,0 +1,193 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var posts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var circuitDrops atomic.Int32
+	cfg := &Config{
+		Service:          "test-circuit",
+		IngestURL:        server.URL,
+		BatchSize:        1,
+		MaxRetries:       5,
+		RetryBaseDelay:   time.Millisecond,
+		CircuitThreshold: 3,
+		CircuitCooldown:  100 * time.Millisecond,
+		OnDrop: func(event Event, reason string) {
+			if reason == DropReasonCircuitOpen {
+				circuitDrops.Add(1)
+			}
+		},
+	}
+	s := newShipper(defaultMonitor, cfg)
+	ship := func(name string) {
+		s.events.push(Event{Name: name, Level: LevelInfo})
+		_ = s.doFlush(context.Background())
+	}
+
+	// Three failed attempts trip the breaker mid-retry, dropping the batch
+	ship("test.trip")
+	if got := posts.Load(); got != 3 {
+		t.Errorf("posts = %d, want 3 before the circuit opened", got)
+	}
+	if got := s.breaker.currentState(); got != CircuitOpen {
+		t.Fatalf("state = %q, want %q", got, CircuitOpen)
+	}
+	if got := circuitDrops.Load(); got != 1 {
+		t.Errorf("circuit_open drops = %d, want 1", got)
+	}
+
+	// While open, batches are dropped without reaching the endpoint
+	ship("test.open")
+	if got := posts.Load(); got != 3 {
+		t.Errorf("posts = %d, want no requests while open", got)
+	}
+	if got := circuitDrops.Load(); got != 2 {
+		t.Errorf("circuit_open drops = %d, want 2", got)
+	}
+	if last := s.lastFlush.Load(); last == nil || !errors.Is(last.err, ErrCircuitOpen) {
+		t.Errorf("last flush = %+v, want ErrCircuitOpen", last)
+	}
+
+	// After the cooldown a failed probe gets one attempt and reopens the circuit
+	time.Sleep(150 * time.Millisecond)
+	if got := s.breaker.currentState(); got != CircuitHalfOpen {
+		t.Errorf("state after cooldown = %q, want %q", got, CircuitHalfOpen)
+	}
+	ship("test.probe-fail")
+	if got := posts.Load(); got != 4 {
+		t.Errorf("posts = %d, want a single probe attempt", got)
+	}
+	if got := s.breaker.currentState(); got != CircuitOpen {
+		t.Errorf("state after failed probe = %q, want %q", got, CircuitOpen)
+	}
+
+	// A successful probe closes it again
+	time.Sleep(150 * time.Millisecond)
+	healthy.Store(true)
+	ship("test.probe-ok")
+	if got := s.breaker.currentState(); got != CircuitClosed {
+		t.Errorf("state after successful probe = %q, want %q", got, CircuitClosed)
+	}
+	if got := s.totalShipped.Load(); got != 1 {
+		t.Errorf("shipped = %d, want the probe delivered", got)
+	}
+}
+
+func TestCircuitBreakerTransport(t *testing.T) {
+	rt := &recordingTransport{}
+	s := newShipper(defaultMonitor, &Config{
+		Service:          "test-circuit",
+		Transport:        rt,
+		BatchSize:        1,
+		MaxRetries:       -1,
+		CircuitThreshold: 2,
+		CircuitCooldown:  50 * time.Millisecond,
+	})
+	ship := func(name string) {
+		s.events.push(Event{Name: name, Level: LevelInfo})
+		_ = s.doFlush(context.Background())
+	}
+
+	// A success resets the count, so failures that aren't consecutive don't trip it
+	rt.failN = 1
+	ship("test.fail")
+	ship("test.ok")
+	rt.failN = 1
+	ship("test.fail")
+	if got := s.breaker.currentState(); got != CircuitClosed {
+		t.Fatalf("state = %q, want %q after non-consecutive failures", got, CircuitClosed)
+	}
+
+	rt.failN = 1
+	ship("test.trip")
+	if got := s.breaker.currentState(); got != CircuitOpen {
+		t.Fatalf("state = %q, want %q", got, CircuitOpen)
+	}
+
+	// A successful half-open probe closes it again
+	time.Sleep(100 * time.Millisecond)
+	if got := s.breaker.currentState(); got != CircuitHalfOpen {
+		t.Fatalf("state after cooldown = %q, want %q", got, CircuitHalfOpen)
+	}
+	ship("test.probe-ok")
+	if got := s.breaker.currentState(); got != CircuitClosed {
+		t.Errorf("state after successful probe = %q, want %q", got, CircuitClosed)
+	}
+	if got := rt.eventCount(); got != 2 {
+		t.Errorf("delivered %d events, want test.ok and the probe", got)
+	}
+}
+
+func TestCircuitBreakerSpills(t *testing.T) {
+	rt := &recordingTransport{failN: 2}
+	s := newShipper(defaultMonitor, &Config{
+		Service:          "test-circuit",
+		Transport:        rt,
+		BatchSize:        10,
+		MaxRetries:       -1,
+		CircuitThreshold: 2,
+		CircuitCooldown:  time.Hour,
+		SpillDir:         t.TempDir(),
+	})
+	defer s.spill.close()
+
+	for i := 0; i < 3; i++ {
+		s.events.push(Event{Name: "test.spill", Level: LevelInfo})
+		_ = s.doFlush(context.Background())
+	}
+	if st := s.breaker.currentState(); st != CircuitOpen {
+		t.Fatalf("state = %q, want %q", st, CircuitOpen)
+	}
+	if _, events, ok := s.spill.takeOldest(); !ok || len(events) != 1 {
+		t.Errorf("spilled %d events, want the batch sent while open", len(events))
+	}
+	if got := s.totalDropped.Load(); got != 2 {
+		t.Errorf("dropped = %d, want only the 2 failed batches", got)
+	}
+}
+
+func TestStatsCircuitState(t *testing.T) {
+	if err := Init(Config{
+		Service:          "test-circuit",
+		DisableStdout:    true,
+		Transport:        &recordingTransport{failN: 1},
+		CircuitThreshold: 1,
+		CircuitCooldown:  time.Hour,
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+	if st := Stats(); st.CircuitState != CircuitClosed {
+		t.Errorf("CircuitState = %q, want %q", st.CircuitState, CircuitClosed)
+	}
+
+	Emit(context.Background(), "test.circuit", nil)
+	Flush()
+	st := Stats()
+	if st.CircuitState != CircuitOpen {
+		t.Errorf("CircuitState = %q, want %q", st.CircuitState, CircuitOpen)
+	}
+	if !errors.Is(st.LastFlushError, ErrCircuitOpen) {
+		t.Errorf("LastFlushError = %v, want ErrCircuitOpen", st.LastFlushError)
+	}
+}
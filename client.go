@@ -10,11 +10,17 @@ import (
 // to automatically emit "http.client_request" events for every outbound request.
 // If client is nil, http.DefaultClient is used as the base.
 func WrapHTTPClient(client *http.Client) *http.Client {
+	return defaultMonitor.WrapHTTPClient(client)
+}
+
+// WrapHTTPClient returns a client whose requests are reported through m, like
+// the package-level WrapHTTPClient.
+func (m *Monitor) WrapHTTPClient(client *http.Client) *http.Client {
 	if client == nil {
 		client = http.DefaultClient
 	}
 	return &http.Client{
-		Transport:     WrapTransport(client.Transport),
+		Transport:     m.WrapTransport(client.Transport),
 		CheckRedirect: client.CheckRedirect,
 		Jar:           client.Jar,
 		Timeout:       client.Timeout,
@@ -24,13 +30,20 @@ func WrapHTTPClient(client *http.Client) *http.Client {
 // WrapTransport wraps an http.RoundTripper to emit monitoring events for each request.
 // If rt is nil, http.DefaultTransport is used.
 func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return defaultMonitor.WrapTransport(rt)
+}
+
+// WrapTransport wraps rt to report each request through m, like the
+// package-level WrapTransport.
+func (m *Monitor) WrapTransport(rt http.RoundTripper) http.RoundTripper {
 	if rt == nil {
 		rt = http.DefaultTransport
 	}
-	return &monitorTransport{base: rt}
+	return &monitorTransport{m: m, base: rt}
 }
 
 type monitorTransport struct {
+	m    *Monitor
 	base http.RoundTripper
 }
 
@@ -38,13 +51,7 @@ func (t *monitorTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	ctx := req.Context()
 	start := time.Now()
 
-	// Propagate trace_id and request_id into outbound headers
-	if traceID := TraceID(ctx); traceID != "" {
-		req.Header.Set(HeaderTraceID, traceID)
-	}
-	if requestID := RequestID(ctx); requestID != "" {
-		req.Header.Set(HeaderRequestID, requestID)
-	}
+	t.m.InjectHeaders(ctx, req.Header)
 
 	resp, err := t.base.RoundTrip(req)
 	duration := time.Since(start)
@@ -67,19 +74,97 @@ func (t *monitorTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		}
 	}
 
-	emitInternal(ctx, "http.client_request", data, level)
+	t.m.emitInternal(ctx, "http.client_request", data, level)
 
 	return resp, err
 }
 
+// InjectHeaders writes ctx's request ID, trace ID, traceparent (when ctx has
+// a span), and sampling decision (see WithSampled) into h, for propagating
+// them to a downstream service by hand. The caller's job ID, from ctx or else
+// Config.JobID, is sent as HeaderParentJobID, so the downstream service
+// records it as parent_job_id. IDs missing from ctx are left untouched. It is
+// the client-side counterpart of Middleware, which reads these headers.
+func InjectHeaders(ctx context.Context, h http.Header) {
+	defaultMonitor.InjectHeaders(ctx, h)
+}
+
+// InjectHeaders writes ctx's IDs into h like the package-level
+// InjectHeaders, falling back to m's Config.JobID for the job ID.
+func (m *Monitor) InjectHeaders(ctx context.Context, h http.Header) {
+	if traceID := TraceID(ctx); traceID != "" {
+		h.Set(HeaderTraceID, traceID)
+	}
+	if requestID := RequestID(ctx); requestID != "" {
+		h.Set(HeaderRequestID, requestID)
+	}
+	jobID := JobID(ctx)
+	if cfg := m.config.Load(); jobID == "" && cfg != nil {
+		jobID = cfg.JobID
+	}
+	if jobID != "" {
+		h.Set(HeaderParentJobID, jobID)
+	}
+	if tp := formatTraceparent(TraceID(ctx), SpanID(ctx)); tp != "" {
+		h.Set(HeaderTraceparent, tp)
+	}
+	if sampled, ok := Sampled(ctx); ok {
+		h.Set(HeaderSampled, formatSampledHeader(sampled))
+	}
+}
+
+// IDsFromResponse returns the request and trace IDs a downstream service
+// echoed in resp's headers, as Middleware does unless
+// Config.EchoResponseHeaders is false. Each is "" if absent.
+//
+//	resp, err := client.Do(req)
+//	requestID, traceID := monitor.IDsFromResponse(resp)
+func IDsFromResponse(resp *http.Response) (requestID, traceID string) {
+	if resp == nil {
+		return "", ""
+	}
+	return resp.Header.Get(HeaderRequestID), resp.Header.Get(HeaderTraceID)
+}
+
+// NewTransport wraps base to inject the request's context IDs into every
+// outbound request with InjectHeaders. Unlike WrapTransport, it emits no
+// events. If base is nil, http.DefaultTransport is used.
+//
+//	client := &http.Client{Transport: monitor.NewTransport(nil)}
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	return defaultMonitor.NewTransport(base)
+}
+
+// NewTransport wraps base to inject IDs with m.InjectHeaders, like the
+// package-level NewTransport.
+func (m *Monitor) NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &propagatingTransport{m: m, base: base}
+}
+
+type propagatingTransport struct {
+	m    *Monitor
+	base http.RoundTripper
+}
+
+func (t *propagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	t.m.InjectHeaders(req.Context(), req.Header)
+	return t.base.RoundTrip(req)
+}
+
 // emitInternal emits an event without source location capture, used by
 // internal SDK components where caller location is not meaningful.
-func emitInternal(ctx context.Context, name string, data any, level string) {
-	cfg := globalConfig.Load()
+func (m *Monitor) emitInternal(ctx context.Context, name string, data any, level string) {
+	cfg := m.activeConfig()
 	if cfg == nil {
 		return
 	}
+	m.counts.add(name)
 
-	event := newEvent(ctx, name, data, level)
-	dispatchEvent(event)
+	event := newEvent(ctx, cfg, name, data, level)
+	m.dispatchEvent(event)
 }
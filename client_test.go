@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -83,3 +84,185 @@ func TestWrapTransport(t *testing.T) {
 		}
 	})
 }
+
+func TestInjectHeaders(t *testing.T) {
+	ctx := WithJobID(WithRequestID(context.Background(), "req-xyz"), "job-current")
+	ctx = WithParentJobID(ctx, "job-parent")
+	h := http.Header{HeaderTraceID: {"upstream"}}
+	InjectHeaders(ctx, h)
+	if got := h.Get(HeaderRequestID); got != "req-xyz" {
+		t.Errorf("%s = %q, want req-xyz", HeaderRequestID, got)
+	}
+	if got := h.Get(HeaderParentJobID); got != "job-current" {
+		t.Errorf("%s = %q, want the caller's own job-current", HeaderParentJobID, got)
+	}
+	if got := h.Get(HeaderTraceID); got != "upstream" {
+		t.Errorf("%s = %q, want it untouched without a trace ID in ctx", HeaderTraceID, got)
+	}
+
+	t.Run("falls back to Config.JobID", func(t *testing.T) {
+		m, err := New(Config{Service: "test-inject", JobID: "job-config", DisableStdout: true})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer m.Shutdown()
+		h := http.Header{}
+		m.InjectHeaders(context.Background(), h)
+		if got := h.Get(HeaderParentJobID); got != "job-config" {
+			t.Errorf("%s = %q, want job-config", HeaderParentJobID, got)
+		}
+	})
+}
+
+func TestParentJobIDRoundTrip(t *testing.T) {
+	var outB lockedBuffer
+	b, err := New(Config{Service: "service-b", JobID: "job-b", Output: &outB})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer b.Shutdown()
+	server := httptest.NewServer(b.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.Emit(r.Context(), "b.handled", nil)
+	})))
+	defer server.Close()
+
+	a, err := New(Config{Service: "service-a", JobID: "job-a", DisableStdout: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer a.Shutdown()
+	resp, err := a.WrapHTTPClient(nil).Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+
+	lines := outB.lines()
+	if len(lines) != 1 {
+		t.Fatalf("service B output = %v, want one event", lines)
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if event.JobID != "job-b" || event.ParentJobID != "job-a" {
+		t.Errorf("job_id = %q, parent_job_id = %q, want job-b and service A's job-a", event.JobID, event.ParentJobID)
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := WithTraceID(context.Background(), "trace-abc")
+	ctx = WithRequestID(ctx, "req-xyz")
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/test", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("client.Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got.Get(HeaderTraceID) != "trace-abc" || got.Get(HeaderRequestID) != "req-xyz" {
+		t.Errorf("outgoing %s = %q, %s = %q, want trace-abc and req-xyz",
+			HeaderTraceID, got.Get(HeaderTraceID), HeaderRequestID, got.Get(HeaderRequestID))
+	}
+	if req.Header.Get(HeaderTraceID) != "" {
+		t.Error("NewTransport modified the caller's request headers")
+	}
+}
+
+func TestIDsFromResponse(t *testing.T) {
+	if err := Init(Config{Service: "test-ids-from-response", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	server := httptest.NewServer(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer server.Close()
+
+	ctx := WithTraceID(WithRequestID(context.Background(), "req-xyz"), "trace-abc")
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := (&http.Client{Transport: NewTransport(nil)}).Do(req)
+	if err != nil {
+		t.Fatalf("client.Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if requestID, traceID := IDsFromResponse(resp); requestID != "req-xyz" || traceID != "trace-abc" {
+		t.Errorf("IDsFromResponse() = %q, %q; want req-xyz, trace-abc", requestID, traceID)
+	}
+	if requestID, traceID := IDsFromResponse(nil); requestID != "" || traceID != "" {
+		t.Errorf("IDsFromResponse(nil) = %q, %q; want empty", requestID, traceID)
+	}
+}
+
+func TestStartChildSpan(t *testing.T) {
+	if err := Init(Config{Service: "test-child-span", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	const traceID = "4bf92f35-77b3-4da6-a3ce-929d0e0e4736"
+	parent := WithSpanID(WithTraceID(context.Background(), traceID), "00f067aa0ba902b7")
+	child := StartChildSpan(parent)
+
+	t.Run("span lineage", func(t *testing.T) {
+		if got := TraceID(child); got != traceID {
+			t.Errorf("TraceID() = %v, want %v", got, traceID)
+		}
+		if got := ParentSpanID(child); got != "00f067aa0ba902b7" {
+			t.Errorf("ParentSpanID() = %v, want 00f067aa0ba902b7", got)
+		}
+		if got := SpanID(child); len(got) != 16 || got == "00f067aa0ba902b7" {
+			t.Errorf("SpanID() = %v, want a fresh 16-hex span", got)
+		}
+		if got := ParentSpanID(parent); got != "" {
+			t.Errorf("parent context ParentSpanID() = %v, want empty", got)
+		}
+
+		event := newEvent(child, defaultMonitor.config.Load(), "test.child", nil, "info")
+		if event.ParentSpanID != "00f067aa0ba902b7" || event.SpanID != SpanID(child) {
+			t.Errorf("event spans = (%v, %v), want (%v, 00f067aa0ba902b7)", event.SpanID, event.ParentSpanID, SpanID(child))
+		}
+	})
+
+	t.Run("grandchild", func(t *testing.T) {
+		grandchild := StartChildSpan(child)
+		if got := ParentSpanID(grandchild); got != SpanID(child) {
+			t.Errorf("ParentSpanID() = %v, want %v", got, SpanID(child))
+		}
+	})
+
+	t.Run("traceparent on outbound request", func(t *testing.T) {
+		var got string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get(HeaderTraceparent)
+		}))
+		defer server.Close()
+
+		req, _ := http.NewRequestWithContext(child, "GET", server.URL, nil)
+		resp, err := WrapHTTPClient(&http.Client{}).Do(req)
+		if err != nil {
+			t.Fatalf("client.Do() error = %v", err)
+		}
+		resp.Body.Close()
+
+		want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + SpanID(child) + "-01"
+		if got != want {
+			t.Errorf("traceparent = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("no traceparent for non-W3C trace IDs", func(t *testing.T) {
+		if got := formatTraceparent("trace-abc", "00f067aa0ba902b7"); got != "" {
+			t.Errorf("formatTraceparent() = %v, want empty", got)
+		}
+		if got := formatTraceparent("4bf92f3577b34da6a3ce929d0e0e4736", ""); got != "" {
+			t.Errorf("formatTraceparent() without span = %v, want empty", got)
+		}
+	})
+}
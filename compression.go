@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Compression algorithms for Config.Compression.
+const (
+	// CompressionNone ships uncompressed NDJSON. This is the default.
+	CompressionNone = "none"
+
+	// CompressionGzip compresses with gzip (Content-Encoding: gzip).
+	CompressionGzip = "gzip"
+
+	// CompressionZstd compresses with zstd (Content-Encoding: zstd). It is
+	// provided by the zstdcompress module, which must be imported to register it.
+	CompressionZstd = "zstd"
+)
+
+// Compressor compresses shipped payloads. Register one with
+// RegisterCompressor to make it available as a Config.Compression value,
+// which is also sent as the Content-Encoding header.
+type Compressor interface {
+	// Compress returns payload compressed at level, where 0 selects the
+	// algorithm's default. It must be safe for concurrent use.
+	Compress(payload []byte, level int) ([]byte, error)
+
+	// ValidLevel reports whether level is usable; Init rejects others.
+	ValidLevel(level int) bool
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[string]Compressor{CompressionGzip: gzipCompressor{}}
+)
+
+// RegisterCompressor makes c available as Config.Compression name. It is
+// meant to be called from an init function, like database/sql drivers, and
+// panics if c is nil or name is already registered.
+func RegisterCompressor(name string, c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	if c == nil {
+		panic("monitor: RegisterCompressor compressor is nil")
+	}
+	if _, dup := compressors[name]; dup || name == CompressionNone {
+		panic("monitor: RegisterCompressor called twice for " + name)
+	}
+	compressors[name] = c
+}
+
+// lookupCompressor returns the compressor registered as name.
+func lookupCompressor(name string) (Compressor, bool) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	c, ok := compressors[name]
+	return c, ok
+}
+
+// compressionName resolves Config.Compression, honoring the deprecated
+// GzipEnabled when Compression is unset.
+func compressionName(cfg *Config) string {
+	if cfg.Compression != "" {
+		return cfg.Compression
+	}
+	if cfg.GzipEnabled {
+		return CompressionGzip
+	}
+	return CompressionNone
+}
+
+// validateCompression checks Config.Compression and CompressionLevel for Init.
+func validateCompression(cfg *Config) error {
+	name := compressionName(cfg)
+	if name == CompressionNone {
+		return nil
+	}
+	c, ok := lookupCompressor(name)
+	if !ok {
+		if name == CompressionZstd {
+			return fmt.Errorf("monitor: Compression %q requires importing github.com/aidenappl/go-monitor/zstdcompress", name)
+		}
+		return fmt.Errorf("monitor: unknown Compression %q", name)
+	}
+	if !c.ValidLevel(cfg.CompressionLevel) {
+		return fmt.Errorf("monitor: invalid CompressionLevel %d for %s", cfg.CompressionLevel, name)
+	}
+	return nil
+}
+
+// compressPayload compresses payload as configured, or returns it unchanged
+// when compression is off.
+func compressPayload(cfg *Config, payload []byte) ([]byte, error) {
+	name := compressionName(cfg)
+	if name == CompressionNone {
+		return payload, nil
+	}
+	c, ok := lookupCompressor(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown compression %q", name)
+	}
+	return c.Compress(payload, cfg.CompressionLevel)
+}
+
+// contentEncoding returns the Content-Encoding header for compressed
+// payloads, or "" when compression is off.
+func contentEncoding(cfg *Config) string {
+	if name := compressionName(cfg); name != CompressionNone {
+		return name
+	}
+	return ""
+}
+
+// gzipCompressor is the built-in gzip Compressor. Levels are those of
+// compress/gzip: HuffmanOnly (-2) through BestCompression (9).
+type gzipCompressor struct{}
+
+// gzipWriterPools reuse gzip.Writers across flushes, one pool per level, so
+// each batch doesn't reallocate the compressor state. Index is level+2.
+var gzipWriterPools [gzip.BestCompression + 3]sync.Pool
+
+// ValidLevel implements Compressor.
+func (gzipCompressor) ValidLevel(level int) bool {
+	return level >= gzip.HuffmanOnly && level <= gzip.BestCompression
+}
+
+// Compress implements Compressor with a pooled gzip.Writer. Level 0
+// (gzip.NoCompression) selects gzip.DefaultCompression; use Compression
+// "none" to ship uncompressed.
+func (gzipCompressor) Compress(payload []byte, level int) ([]byte, error) {
+	if level == gzip.NoCompression {
+		level = gzip.DefaultCompression
+	}
+	pool := &gzipWriterPools[level+2]
+	gw, _ := pool.Get().(*gzip.Writer)
+	if gw == nil {
+		// Level was validated, so NewWriterLevel can't fail
+		gw, _ = gzip.NewWriterLevel(io.Discard, level)
+	}
+	defer pool.Put(gw)
+
+	var buf bytes.Buffer
+	gw.Reset(&buf)
+	if _, err := gw.Write(payload); err != nil {
+		return nil, fmt.Errorf("gzip write failed: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("gzip close failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// gzipPayload compresses the full NDJSON payload with a pooled gzip.Writer at
+// the default level.
+func gzipPayload(payload []byte) ([]byte, error) {
+	return gzipCompressor{}.Compress(payload, 0)
+}
@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	type request struct {
+		encoding string
+		body     []byte
+	}
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{r.Header.Get("Content-Encoding"), body}
+	}))
+	defer server.Close()
+
+	ship := func(t *testing.T, cfg Config) request {
+		t.Helper()
+		cfg.Service = "test-compression"
+		cfg.IngestURL = server.URL
+		cfg.DisableStdout = true
+		if err := Init(cfg); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+		Emit(context.Background(), "test.compression", map[string]any{"key": strings.Repeat("v", 200)})
+		Flush()
+		return <-requests
+	}
+	gunzip := func(t *testing.T, body []byte) string {
+		t.Helper()
+		gr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		got, err := io.ReadAll(gr)
+		if err != nil {
+			t.Fatalf("decompress error = %v", err)
+		}
+		return string(got)
+	}
+
+	t.Run("none by default", func(t *testing.T) {
+		req := ship(t, Config{})
+		if req.encoding != "" {
+			t.Errorf("Content-Encoding = %q, want none", req.encoding)
+		}
+		if !strings.Contains(string(req.body), `"name":"test.compression"`) {
+			t.Errorf("body = %s, want plain NDJSON", req.body)
+		}
+	})
+
+	for _, level := range []int{0, gzip.BestSpeed, gzip.BestCompression} {
+		t.Run(fmt.Sprintf("gzip level %d", level), func(t *testing.T) {
+			req := ship(t, Config{Compression: CompressionGzip, CompressionLevel: level})
+			if req.encoding != "gzip" {
+				t.Errorf("Content-Encoding = %q, want gzip", req.encoding)
+			}
+			if got := gunzip(t, req.body); !strings.Contains(got, `"name":"test.compression"`) || !strings.HasSuffix(got, "\n") {
+				t.Errorf("decompressed = %s, want the NDJSON event", got)
+			}
+		})
+	}
+
+	t.Run("GzipEnabled alias", func(t *testing.T) {
+		req := ship(t, Config{GzipEnabled: true})
+		if req.encoding != "gzip" {
+			t.Errorf("Content-Encoding = %q, want gzip", req.encoding)
+		}
+		gunzip(t, req.body)
+	})
+
+	t.Run("Compression overrides GzipEnabled", func(t *testing.T) {
+		req := ship(t, Config{GzipEnabled: true, Compression: CompressionNone})
+		if req.encoding != "" {
+			t.Errorf("Content-Encoding = %q, want none", req.encoding)
+		}
+	})
+}
+
+func TestCompressionValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"unknown algorithm", Config{Compression: "brotli"}, `unknown Compression "brotli"`},
+		{"zstd not imported", Config{Compression: CompressionZstd}, "zstdcompress"},
+		{"gzip level too high", Config{Compression: CompressionGzip, CompressionLevel: 10}, "invalid CompressionLevel 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Service = "test-compression"
+			err := Init(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Init() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
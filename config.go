@@ -0,0 +1,176 @@
+package monitor
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+)
+
+// redacted replaces secret config values in the monitor.config event.
+const redacted = "[REDACTED]"
+
+// effectiveConfig returns the resolved configuration as event data for
+// EmitConfigOnInit. Secrets are redacted: APIKey entirely, and any password
+// in IngestURL. Functions and writers are reported by presence or type only.
+func effectiveConfig(cfg *Config) map[string]any {
+	data := map[string]any{
+		"service":                     cfg.Service,
+		"env":                         cfg.Env,
+		"job_id":                      cfg.JobID,
+		"batch_size":                  cfg.BatchSize,
+		"max_event_age":               cfg.MaxEventAge.String(),
+		"max_batch_bytes":             cfg.MaxBatchBytes,
+		"group_by_trace":              cfg.GroupByTrace,
+		"queue_size":                  cfg.QueueSize,
+		"flush_every":                 cfg.FlushEvery.String(),
+		"gzip_enabled":                cfg.GzipEnabled,
+		"compression":                 compressionName(cfg),
+		"compression_level":           cfg.CompressionLevel,
+		"disable_stdout":              cfg.DisableStdout,
+		"sync_stdout":                 syncStdoutEnabled(cfg),
+		"stdout_queue_size":           cfg.StdoutQueueSize,
+		"capture_source":              captureSourceEnabled(cfg),
+		"capture_stack":               cfg.CaptureStack,
+		"capture_caller":              cfg.CaptureCaller,
+		"repanic_after_recover":       cfg.RepanicAfterRecover,
+		"emit_http_requests":          cfg.EmitHTTPRequests,
+		"debug":                       cfg.Debug,
+		"flatten_data":                cfg.FlattenData,
+		"flatten_arrays":              cfg.FlattenArrays,
+		"processors":                  len(cfg.Processors),
+		"include_uptime":              cfg.IncludeUptime,
+		"timestamp_format":            cfg.TimestampFormat,
+		"include_deadline":            cfg.IncludeDeadline,
+		"handle_signals":              cfg.HandleSignals,
+		"ring_buffer_size":            cfg.RingBufferSize,
+		"strict_schema":               cfg.StrictSchema,
+		"min_level":                   cfg.MinLevel,
+		"reject_unmarshalable":        cfg.RejectUnmarshalable,
+		"runtime_stats_interval":      cfg.RuntimeStatsInterval.String(),
+		"max_events_per_second":       cfg.MaxEventsPerSecond,
+		"rate_limit_burst":            cfg.RateLimitBurst,
+		"sample_rate":                 cfg.SampleRate,
+		"max_data_bytes":              cfg.MaxDataBytes,
+		"dedup_window":                cfg.DedupWindow.String(),
+		"default_sample_rate":         cfg.DefaultSampleRate,
+		"trust_sampled_header":        cfg.TrustSampledHeader,
+		"echo_response_headers":       echoResponseHeadersEnabled(cfg),
+		"correlate_request_and_trace": cfg.CorrelateRequestAndTrace,
+		"ingest_protocol":             cfg.IngestProtocol,
+		"ingest_content_type":         cfg.IngestContentType,
+		"export_format":               cfg.ExportFormat,
+	}
+	if cfg.IngestContentType == "" {
+		data["ingest_content_type"] = ingestContentType(cfg)
+	}
+	if cfg.TimestampFormat == "" {
+		data["timestamp_format"] = TimestampRFC3339Nano
+	}
+	if cfg.ExportFormat == "" {
+		data["export_format"] = ExportFormatNDJSON
+	}
+	if cfg.APIKey != "" {
+		data["api_key"] = redacted
+		data["auth_header"] = authHeader(cfg)
+		data["auth_scheme"] = cfg.AuthScheme
+	}
+	if len(cfg.ExtraHeaders) > 0 {
+		// Values may be credentials, so only the names are reported
+		data["extra_headers"] = slices.Sorted(maps.Keys(cfg.ExtraHeaders))
+	}
+	if cfg.IngestURL != "" {
+		data["ingest_url"] = redactURL(cfg.IngestURL)
+	}
+	if len(cfg.IngestURLs) > 0 {
+		urls := make([]string, len(cfg.IngestURLs))
+		for i, u := range cfg.IngestURLs {
+			urls[i] = redactURL(u)
+		}
+		data["ingest_urls"] = urls
+	}
+	if len(cfg.Endpoints) > 0 {
+		endpoints := make([]map[string]any, len(cfg.Endpoints))
+		for i, ep := range cfg.Endpoints {
+			endpoints[i] = map[string]any{"url": redactURL(ep.URL)}
+			if ep.APIKey != "" {
+				endpoints[i]["api_key"] = redacted
+			}
+		}
+		data["endpoints"] = endpoints
+	}
+	if cfg.Transport != nil {
+		data["transport"] = fmt.Sprintf("%T", cfg.Transport)
+	}
+	if cfg.Encoder != nil {
+		data["encoder"] = fmt.Sprintf("%T", cfg.Encoder)
+	}
+	if cfg.HTTPClient != nil {
+		data["http_client"] = true
+	}
+	if cfg.Output != nil {
+		data["output"] = fmt.Sprintf("%T", cfg.Output)
+	}
+	if cfg.SpanExporter != nil {
+		data["span_exporter"] = fmt.Sprintf("%T", cfg.SpanExporter)
+	}
+	if cfg.OnTruncate != nil {
+		data["on_truncate"] = true
+	}
+	if cfg.OnDrop != nil {
+		data["on_drop"] = true
+	}
+	if cfg.IDGenerator != nil {
+		data["id_generator"] = true
+	}
+	if cfg.RequestIDGenerator != nil {
+		data["request_id_generator"] = true
+	}
+	if cfg.TraceIDGenerator != nil {
+		data["trace_id_generator"] = true
+	}
+	if len(cfg.SampleRates) > 0 {
+		data["sample_rates"] = cfg.SampleRates
+	}
+	if len(cfg.RedactKeys) > 0 {
+		data["redact_keys"] = cfg.RedactKeys
+	}
+	if len(cfg.DefaultLevels) > 0 {
+		data["default_levels"] = cfg.DefaultLevels
+	}
+	if len(cfg.StaticFields) > 0 {
+		data["static_fields"] = cfg.StaticFields
+	}
+	if cfg.SpillDir != "" {
+		data["spill_dir"] = cfg.SpillDir
+		data["max_spill_bytes"] = cfg.MaxSpillBytes
+	}
+	if cfg.CircuitThreshold > 0 {
+		data["circuit_threshold"] = cfg.CircuitThreshold
+		data["circuit_cooldown"] = cfg.CircuitCooldown.String()
+	}
+	if cfg.XRayHeader != "" {
+		data["xray_header"] = cfg.XRayHeader
+	}
+	if cfg.IDFormat != "" {
+		data["id_format"] = cfg.IDFormat
+	}
+	if cfg.SyslogNetwork != "" || cfg.SyslogAddr != "" {
+		data["syslog_network"] = cfg.SyslogNetwork
+		data["syslog_addr"] = cfg.SyslogAddr
+	}
+	return data
+}
+
+// redactURL hides the password and query string of an ingest URL, either of
+// which may carry credentials.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = redacted
+	}
+	return u.Redacted()
+}
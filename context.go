@@ -1,6 +1,9 @@
 package monitor
 
-import "context"
+import (
+	"context"
+	"sync/atomic"
+)
 
 // Context keys for storing IDs.
 type ctxKey int
@@ -10,6 +13,15 @@ const (
 	ctxKeyRequestID
 	ctxKeyTraceID
 	ctxKeyUserID
+	ctxKeyCorrelations
+	ctxKeySpanID
+	ctxKeyRequestSeq
+	ctxKeyParentSpanID
+	ctxKeySampleRate
+	ctxKeyFields
+	ctxKeySampled
+	ctxKeyParentJobID
+	ctxKeySessionID
 )
 
 // WithJobID returns a new context with the given job ID.
@@ -27,6 +39,28 @@ func WithTraceID(ctx context.Context, traceID string) context.Context {
 	return context.WithValue(ctx, ctxKeyTraceID, traceID)
 }
 
+// WithSpanID returns a new context with the given span ID.
+func WithSpanID(ctx context.Context, spanID string) context.Context {
+	return context.WithValue(ctx, ctxKeySpanID, spanID)
+}
+
+// ParentSpanID returns the parent span ID from the context, or empty string if not set.
+func ParentSpanID(ctx context.Context) string {
+	if v, ok := ctx.Value(ctxKeyParentSpanID).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// StartChildSpan returns a new context for a downstream call: the trace ID is
+// kept, the current span ID becomes the parent span ID, and a fresh span ID is
+// generated. Pass the result to requests made through WrapTransport so the
+// downstream service receives the new span as its parent via traceparent.
+func StartChildSpan(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, ctxKeyParentSpanID, SpanID(ctx))
+	return WithSpanID(ctx, generateSpanID())
+}
+
 // JobID returns the job ID from the context, or empty string if not set.
 func JobID(ctx context.Context) string {
 	if v, ok := ctx.Value(ctxKeyJobID).(string); ok {
@@ -35,6 +69,24 @@ func JobID(ctx context.Context) string {
 	return ""
 }
 
+// WithParentJobID returns a new context recording the job that spawned the
+// current one, emitted as parent_job_id, so sub-jobs can be assembled into a
+// tree. Typically paired with WithJobID for the sub-job's own ID:
+//
+//	ctx = monitor.WithParentJobID(ctx, monitor.JobID(ctx))
+//	ctx = monitor.WithJobID(ctx, subJobID)
+func WithParentJobID(ctx context.Context, parentJobID string) context.Context {
+	return context.WithValue(ctx, ctxKeyParentJobID, parentJobID)
+}
+
+// ParentJobID returns the parent job ID from the context, or empty string if not set.
+func ParentJobID(ctx context.Context) string {
+	if v, ok := ctx.Value(ctxKeyParentJobID).(string); ok {
+		return v
+	}
+	return ""
+}
+
 // RequestID returns the request ID from the context, or empty string if not set.
 func RequestID(ctx context.Context) string {
 	if v, ok := ctx.Value(ctxKeyRequestID).(string); ok {
@@ -51,6 +103,14 @@ func TraceID(ctx context.Context) string {
 	return ""
 }
 
+// SpanID returns the span ID from the context, or empty string if not set.
+func SpanID(ctx context.Context) string {
+	if v, ok := ctx.Value(ctxKeySpanID).(string); ok {
+		return v
+	}
+	return ""
+}
+
 // WithUserID returns a new context with the given user ID.
 func WithUserID(ctx context.Context, userID string) context.Context {
 	return context.WithValue(ctx, ctxKeyUserID, userID)
@@ -63,3 +123,110 @@ func UserID(ctx context.Context) string {
 	}
 	return ""
 }
+
+// WithSessionID returns a new context with the given session ID, emitted as
+// session_id to group a user's events by session.
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, ctxKeySessionID, sessionID)
+}
+
+// SessionID returns the session ID from the context, or empty string if not set.
+func SessionID(ctx context.Context) string {
+	if v, ok := ctx.Value(ctxKeySessionID).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// WithCorrelation returns a new context carrying a named business correlation
+// value (e.g., an order number) that is emitted in the event's correlations map.
+// Multiple names can be set; setting an existing name replaces its value.
+func WithCorrelation(ctx context.Context, key, value string) context.Context {
+	existing, _ := ctx.Value(ctxKeyCorrelations).(map[string]string)
+	correlations := make(map[string]string, len(existing)+1)
+	for k, v := range existing {
+		correlations[k] = v
+	}
+	correlations[key] = value
+	return context.WithValue(ctx, ctxKeyCorrelations, correlations)
+}
+
+// Correlation returns the named correlation value from the context, or empty string if not set.
+func Correlation(ctx context.Context, key string) string {
+	if m, ok := ctx.Value(ctxKeyCorrelations).(map[string]string); ok {
+		return m[key]
+	}
+	return ""
+}
+
+// Correlations returns a copy of all correlation values in the context, or nil if none are set.
+func Correlations(ctx context.Context) map[string]string {
+	m, ok := ctx.Value(ctxKeyCorrelations).(map[string]string)
+	if !ok || len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// WithFields returns a new context carrying fields that are added to the data
+// of every event emitted with it, like logging baggage (e.g., a tenant ID set
+// once per request). Fields accumulate across calls; a later call replaces a
+// key set by an earlier one. Data passed to Emit takes precedence over a field
+// with the same key, and non-map data is wrapped under "_data".
+//
+// Unlike WithField, which adds a top-level key to one event, these go in data
+// and apply to every event emitted with the context.
+func WithFields(ctx context.Context, fields map[string]any) context.Context {
+	existing, _ := ctx.Value(ctxKeyFields).(map[string]any)
+	merged := make(map[string]any, len(existing)+len(fields))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, ctxKeyFields, merged)
+}
+
+// ContextFields returns a copy of the fields set with WithFields, or nil if none are set.
+func ContextFields(ctx context.Context) map[string]any {
+	m, ok := ctx.Value(ctxKeyFields).(map[string]any)
+	if !ok || len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// WithRequestSeq returns a new context with a fresh event counter. Every event
+// emitted with the context (or one derived from it) gets the next value as
+// request_seq, starting at 1. Middleware sets one up for each request; call it
+// directly to number events in other units of work such as a job run.
+func WithRequestSeq(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyRequestSeq, new(atomic.Uint64))
+}
+
+// RequestSeq returns how many events have been emitted with the context's
+// counter so far, or 0 if the context has no counter.
+func RequestSeq(ctx context.Context) uint64 {
+	if c, ok := ctx.Value(ctxKeyRequestSeq).(*atomic.Uint64); ok {
+		return c.Load()
+	}
+	return 0
+}
+
+// nextRequestSeq increments the context's event counter and returns the new
+// value, or 0 if the context has no counter.
+func nextRequestSeq(ctx context.Context) uint64 {
+	if c, ok := ctx.Value(ctxKeyRequestSeq).(*atomic.Uint64); ok {
+		return c.Add(1)
+	}
+	return 0
+}
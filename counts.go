@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// eventCounts counts emits by event name for EventCounts. Names are added
+// once and then incremented without a lock.
+type eventCounts struct {
+	counts sync.Map // name -> *atomic.Uint64
+}
+
+// add counts one emit of name.
+func (c *eventCounts) add(name string) {
+	n, ok := c.counts.Load(name)
+	if !ok {
+		n, _ = c.counts.LoadOrStore(name, new(atomic.Uint64))
+	}
+	n.(*atomic.Uint64).Add(1)
+}
+
+// snapshot returns a copy of the counts.
+func (c *eventCounts) snapshot() map[string]uint64 {
+	out := make(map[string]uint64)
+	c.counts.Range(func(name, n any) bool {
+		out[name.(string)] = n.(*atomic.Uint64).Load()
+		return true
+	})
+	return out
+}
+
+// EventCounts returns how many times each event name has been emitted since
+// the process started, as a snapshot the caller may modify. Every emit while
+// initialized is counted, before sampling, deduplication, MinLevel, or rate
+// limiting can drop it, so a name missing here was never emitted at all.
+// Counts persist across Init. Each distinct name holds a counter for the life
+// of the process, so names should not embed unbounded values such as user IDs.
+func EventCounts() map[string]uint64 {
+	return defaultMonitor.EventCounts()
+}
+
+// EventCounts returns m's emit counts by event name, like the package-level
+// EventCounts.
+func (m *Monitor) EventCounts() map[string]uint64 {
+	return m.counts.snapshot()
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestEventCounts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("counts by name", func(t *testing.T) {
+		m, err := New(Config{Service: "test-counts", DisableStdout: true})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer m.Shutdown()
+
+		if got := m.EventCounts(); len(got) != 0 {
+			t.Errorf("EventCounts() = %v, want empty before any emit", got)
+		}
+		for range 3 {
+			m.Emit(ctx, "user.created", nil)
+		}
+		m.Emit(ctx, "user.deleted", nil)
+		// Counted before sampling drops it
+		m.Emit(WithSampleRate(ctx, 0), "user.deleted", nil)
+
+		counts := m.EventCounts()
+		want := map[string]uint64{"user.created": 3, "user.deleted": 2}
+		if !reflect.DeepEqual(counts, want) {
+			t.Errorf("EventCounts() = %v, want %v", counts, want)
+		}
+
+		// The snapshot is a copy
+		counts["user.created"] = 100
+		if got := m.EventCounts()["user.created"]; got != 3 {
+			t.Errorf("user.created count = %d after modifying a snapshot, want 3", got)
+		}
+	})
+
+	t.Run("parallel emits", func(t *testing.T) {
+		m, err := New(Config{Service: "test-counts", DisableStdout: true})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer m.Shutdown()
+
+		names := []string{"a", "b", "c", "d"}
+		const perGoroutine = 500
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Go(func() {
+				for j := range perGoroutine {
+					m.Emit(ctx, names[(i+j)%len(names)], nil)
+				}
+				m.EventCounts()
+			})
+		}
+		wg.Wait()
+
+		counts := m.EventCounts()
+		var total uint64
+		for _, name := range names {
+			if counts[name] != 8*perGoroutine/uint64(len(names)) {
+				t.Errorf("count[%s] = %d, want %d", name, counts[name], 8*perGoroutine/len(names))
+			}
+			total += counts[name]
+		}
+		if total != 8*perGoroutine {
+			t.Errorf("total = %d, want %d", total, 8*perGoroutine)
+		}
+	})
+}
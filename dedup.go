@@ -0,0 +1,149 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// maxDedupEntries bounds the distinct events Config.DedupWindow tracks at
+// once. Beyond it, new events pass through undeduplicated until a window
+// closes.
+const maxDedupEntries = 10000
+
+// deduper suppresses repeats of an event within Config.DedupWindow and emits
+// one summary per window that saw any.
+type deduper struct {
+	monitor *Monitor
+	window  time.Duration
+
+	mu      sync.Mutex
+	entries map[uint64]*dedupEntry
+	stopped bool
+}
+
+// dedupEntry is an open window: the first event, which was emitted, and how
+// many identical events have been suppressed since. name, level, and data
+// are the event's identity as hashed by dedupKey, kept to tell a repeat from
+// a hash collision.
+type dedupEntry struct {
+	event   Event
+	name    string
+	level   string
+	data    []byte
+	repeats int
+	timer   *time.Timer
+}
+
+func newDeduper(m *Monitor, window time.Duration) *deduper {
+	return &deduper{
+		monitor: m,
+		window:  window,
+		entries: make(map[uint64]*dedupEntry),
+	}
+}
+
+// dedupKey hashes the fields that make events identical: name, level, and
+// data, returning the encoded data alongside. Events whose data can't be
+// marshaled are never deduplicated.
+func dedupKey(event *Event) (uint64, []byte, bool) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return 0, nil, false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(event.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(event.Level))
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum64(), data, true
+}
+
+// matches reports whether event, with data encoded as by dedupKey, is
+// identical to e's event rather than a hash collision.
+func (e *dedupEntry) matches(event *Event, data []byte) bool {
+	return e.name == event.Name && e.level == event.Level && bytes.Equal(e.data, data)
+}
+
+// suppress reports whether event repeats one emitted within the window,
+// counting it if so. Otherwise it opens a window for event, which the caller
+// emits as usual. Audit events are never suppressed: each is a record of its
+// own.
+func (d *deduper) suppress(event *Event) bool {
+	if event.Level == LevelAudit {
+		return false
+	}
+	key, data, ok := dedupKey(event)
+	if !ok {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return false
+	}
+	if e, ok := d.entries[key]; ok {
+		if !e.matches(event, data) {
+			// A different event with the same hash passes through, like one
+			// beyond maxDedupEntries
+			return false
+		}
+		e.repeats++
+		return true
+	}
+	if len(d.entries) >= maxDedupEntries {
+		return false
+	}
+	e := &dedupEntry{event: *event, name: event.Name, level: event.Level, data: data}
+	e.timer = time.AfterFunc(d.window, func() { d.expire(key, e) })
+	d.entries[key] = e
+	return false
+}
+
+// expire closes e's window, unless stop already has.
+func (d *deduper) expire(key uint64, e *dedupEntry) {
+	d.mu.Lock()
+	if d.entries[key] != e {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.entries, key)
+	d.mu.Unlock()
+
+	d.summarize(e)
+}
+
+// summarize emits a copy of e's event with data._repeat_count set to the
+// number of repeats suppressed, if there were any.
+func (d *deduper) summarize(e *dedupEntry) {
+	if e.repeats == 0 {
+		return
+	}
+	cfg := d.monitor.activeConfig()
+	if cfg == nil {
+		return
+	}
+	event := e.event
+	event.Timestamp = formatTimestamp(cfg, time.Now())
+	addDataFields(&event, map[string]any{"_repeat_count": e.repeats})
+	d.monitor.deliverEvent(cfg, event)
+}
+
+// stop closes every open window, emitting their summaries, and lets later
+// events through undeduplicated.
+func (d *deduper) stop() {
+	d.mu.Lock()
+	d.stopped = true
+	entries := d.entries
+	d.entries = nil
+	d.mu.Unlock()
+
+	for _, e := range entries {
+		e.timer.Stop()
+		d.summarize(e)
+	}
+}
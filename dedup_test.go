@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDedupWindow(t *testing.T) {
+	emitBurst := func(n int) {
+		for i := 0; i < n; i++ {
+			Emit(context.Background(), "db.timeout", map[string]any{"query": "select 1"})
+		}
+	}
+	repeatCount := func(e Event) any {
+		data, _ := e.Data.(map[string]any)
+		return data["_repeat_count"]
+	}
+
+	t.Run("summarizes when the window closes", func(t *testing.T) {
+		if err := Init(Config{Service: "test-dedup", DisableStdout: true, CaptureSource: new(bool), DedupWindow: 50 * time.Millisecond}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+		c := NewCapture()
+		defer c.Close()
+
+		emitBurst(100)
+		Emit(context.Background(), "db.timeout", map[string]any{"query": "select 2"})
+		if got := len(c.Named("db.timeout")); got != 2 {
+			t.Fatalf("got %d events during the window, want the first of each distinct event", got)
+		}
+
+		deadline := time.Now().Add(2 * time.Second)
+		for len(c.Named("db.timeout")) < 3 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		events := c.Named("db.timeout")
+		if len(events) != 3 {
+			t.Fatalf("got %d events, want 3 (two firsts and one summary)", len(events))
+		}
+		if got := repeatCount(events[2]); got != 99 {
+			t.Errorf("summary _repeat_count = %v, want 99", got)
+		}
+		if repeatCount(events[0]) != nil || repeatCount(events[1]) != nil {
+			t.Error("first events should not carry _repeat_count")
+		}
+
+		// A new window opens after the old one closed
+		c.Reset()
+		emitBurst(1)
+		if got := len(c.Events()); got != 1 {
+			t.Errorf("got %d events after the window, want 1", got)
+		}
+	})
+
+	t.Run("summarizes on Shutdown", func(t *testing.T) {
+		if err := Init(Config{Service: "test-dedup", DisableStdout: true, CaptureSource: new(bool), DedupWindow: time.Hour}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		c := NewCapture()
+		defer c.Close()
+
+		emitBurst(5)
+		Shutdown()
+		events := c.Events()
+		if len(events) != 2 || repeatCount(events[1]) != 4 {
+			t.Errorf("events = %v, want the first and a summary with _repeat_count 4", events)
+		}
+	})
+}
+
+func TestDedupHashCollision(t *testing.T) {
+	m, err := New(Config{Service: "test-dedup", DisableStdout: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+	d := newDeduper(m, time.Hour)
+	defer d.stop()
+
+	// Plant an open window for a different event under the key of the one
+	// being emitted, as a hash collision would
+	event := Event{Name: "db.timeout", Level: LevelInfo, Data: map[string]any{"query": "select 1"}}
+	key, _, _ := dedupKey(&event)
+	d.entries[key] = &dedupEntry{name: "cache.miss", level: LevelInfo, data: []byte("{}"), timer: time.NewTimer(time.Hour)}
+
+	if d.suppress(&event) {
+		t.Error("suppress() = true for an event that only shares a hash, want it passed through")
+	}
+	if got := d.entries[key].repeats; got != 0 {
+		t.Errorf("colliding entry repeats = %d, want 0", got)
+	}
+}
+
+func TestDedupWindowSkipsAudit(t *testing.T) {
+	var out lockedBuffer
+	if err := Init(Config{Service: "test-dedup", Output: &out, CaptureSource: new(bool), DedupWindow: time.Hour}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	data := map[string]any{"actor": "admin-1", "target": "user-2"}
+	EmitAudit(context.Background(), "user.deleted", data)
+	EmitAudit(context.Background(), "user.deleted", data)
+	Flush()
+
+	if lines := out.lines(); len(lines) != 2 {
+		t.Errorf("got %d lines, want both audit events: %v", len(lines), lines)
+	}
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+)
+
+// EventInput is an event to emit, as received by EmitFrom.
+type EventInput struct {
+	Name string
+	Data any
+
+	// Level is the event level. When empty, the configured default for Name
+	// applies (see Config.DefaultLevels).
+	Level string
+}
+
+// EmitFrom starts a goroutine that emits each EventInput received from ch
+// with ctx's IDs, so an existing event channel can feed the monitor without
+// calling Emit. The goroutine exits when ch is closed, ctx is done, or stop is
+// called. stop waits for it to exit and is safe to call more than once; inputs
+// still buffered in ch when it stops are not emitted.
+//
+//	stop := monitor.EmitFrom(ctx, events)
+//	defer stop()
+func EmitFrom(ctx context.Context, ch <-chan EventInput) (stop func()) {
+	return defaultMonitor.EmitFrom(ctx, ch)
+}
+
+// EmitFrom emits each EventInput received from ch through m, like the
+// package-level EmitFrom.
+func (m *Monitor) EmitFrom(ctx context.Context, ch <-chan EventInput) (stop func()) {
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+
+	go func() {
+		defer close(doneCh)
+		for {
+			select {
+			case in, ok := <-ch:
+				if !ok {
+					return
+				}
+				// Source location would point here rather than at the producer
+				m.Emit(ctx, in.Name, in.Data, WithLevel(in.Level), WithoutSource())
+			case <-stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stopCh) })
+		<-doneCh
+	}
+}
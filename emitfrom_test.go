@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEmitFrom(t *testing.T) {
+	if err := Init(Config{Service: "test-emit-from", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	t.Run("drains until closed", func(t *testing.T) {
+		ctx := WithRequestID(context.Background(), "req-producer")
+		ch := make(chan EventInput)
+
+		events := Captured(func() {
+			stop := EmitFrom(ctx, ch)
+			ch <- EventInput{Name: "test.first", Data: map[string]any{"n": 1}}
+			ch <- EventInput{Name: "test.second", Level: LevelError}
+			close(ch)
+			stop()
+		})
+
+		if len(events) != 2 {
+			t.Fatalf("emitted %d events, want 2", len(events))
+		}
+		if events[0].Name != "test.first" || events[0].Level != LevelInfo || events[0].RequestID != "req-producer" {
+			t.Errorf("first event = %+v, want test.first at info with request ID", events[0])
+		}
+		if events[1].Name != "test.second" || events[1].Level != LevelError {
+			t.Errorf("second event = %+v, want test.second at error", events[1])
+		}
+	})
+
+	t.Run("stop exits while channel is open", func(t *testing.T) {
+		stop := EmitFrom(context.Background(), make(chan EventInput))
+
+		done := make(chan struct{})
+		go func() {
+			stop()
+			stop() // idempotent
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("stop() did not return")
+		}
+	})
+
+	t.Run("exits when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		stop := EmitFrom(ctx, make(chan EventInput))
+		cancel()
+
+		done := make(chan struct{})
+		go func() {
+			stop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("goroutine did not exit after context cancellation")
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// EmitSync emits an event like Emit, but instead of queueing it for the next
+// batch, ships it on its own and waits until the ingest endpoint (or
+// Transport) accepts it. It returns the delivery error, or nil once the event
+// is delivered. Use it for events that must be confirmed before the caller
+// proceeds, e.g., critical audit records.
+//
+// Delivery is a single attempt bounded by ctx: a failed event is not retried,
+// spilled, or passed to Config.OnDrop, since the caller gets the error. With
+// several endpoints, every one must accept the event. EmitSync is never
+// sampled, but events below Config.MinLevel are dropped and return nil, and
+// events over Config.MaxEventsPerSecond are dropped and return ErrRateLimited.
+// An event dropped by Config.StrictSchema returns an error wrapping
+// ErrSchemaViolation.
+// Without an ingest endpoint or Transport, the event is only written to
+// stdout and EmitSync returns nil. Before Init or after Shutdown it returns
+// ErrNotInitialized.
+func EmitSync(ctx context.Context, name string, data any, opts ...EmitOption) error {
+	return defaultMonitor.emitSync(ctx, name, data, opts, 2)
+}
+
+// EmitSync emits and ships an event through m, like the package-level EmitSync.
+func (m *Monitor) EmitSync(ctx context.Context, name string, data any, opts ...EmitOption) error {
+	return m.emitSync(ctx, name, data, opts, 2)
+}
+
+// emitSync implements EmitSync for callers at the given depth.
+func (m *Monitor) emitSync(ctx context.Context, name string, data any, opts []EmitOption, callerDepth int) error {
+	cfg := m.activeConfig()
+	if cfg == nil {
+		return ErrNotInitialized
+	}
+	m.counts.add(name)
+
+	o := &emitOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.level = m.checkLevel(o.level)
+	event := newEvent(ctx, cfg, name, data, o.level)
+	o.applyTo(&event)
+	if captureSourceEnabled(cfg) && !o.skipSource {
+		attachSourceLocation(&event, callerDepth+1)
+	}
+	if cfg.CaptureCaller && !o.skipSource {
+		event.Caller = callerLocation(callerDepth + 1)
+	}
+
+	if err := m.outputEvent(cfg, &event); err != nil {
+		if err == errEventCaptured || err == errEventFiltered {
+			return nil
+		}
+		return err
+	}
+	if s := m.shipper.Load(); s != nil {
+		return s.shipSync(ctx, event)
+	}
+	return nil
+}
+
+// shipSync delivers event to s and its peers concurrently, one attempt each,
+// returning their errors joined.
+func (s *shipper) shipSync(ctx context.Context, event Event) error {
+	if len(s.peers) == 0 {
+		return s.shipOne(ctx, event)
+	}
+
+	shippers := s.all()
+	errs := make([]error, len(shippers))
+	var wg sync.WaitGroup
+	for i, sh := range shippers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = sh.shipOne(ctx, event)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// encodeSyncPayload encodes event as a one-event batch in cfg's export format.
+func encodeSyncPayload(cfg *Config, event Event) ([]byte, error) {
+	if cfg.ExportFormat == ExportFormatOTLPLogs {
+		// Converted up front to surface why an event can't be encoded
+		if _, err := otlpRecord(event); err != nil {
+			return nil, err
+		}
+		payload, _, _, err := encodeOTLPLogs([]Event{event})
+		return payload, err
+	}
+	payload, err := encodeEvent(cfg, event)
+	if err != nil {
+		return nil, err
+	}
+	return append(payload, '\n'), nil
+}
+
+// shipOne delivers event as a batch of its own, bypassing the queue. It is
+// safe to call concurrently with the run loop.
+func (s *shipper) shipOne(ctx context.Context, event Event) error {
+	batch := []Event{event}
+	if s.cfg.Transport != nil {
+		if err := s.cfg.Transport.Send(ctx, batch); err != nil {
+			return err
+		}
+		s.delivered(1, false)
+		return nil
+	}
+
+	payload, err := encodeSyncPayload(s.cfg, event)
+	if err != nil {
+		return fmt.Errorf("monitor: failed to marshal event: %w", err)
+	}
+	if payload, err = compressPayload(s.cfg, payload); err != nil {
+		return fmt.Errorf("monitor: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.IngestURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("monitor: failed to create request: %w", err)
+	}
+	setIngestHeaders(req, s.cfg)
+	if encoding := contentEncoding(s.cfg); encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("monitor: failed to ship event: %w", err)
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxPartialFailureBody))
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("monitor: ingest returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode < 300 {
+		failed, ok := s.partialFailures(resp.StatusCode, body, batch)
+		if ok && len(failed) > 0 {
+			return errors.New("monitor: ingest rejected the event")
+		}
+		if !ok && resp.StatusCode == http.StatusMultiStatus {
+			return fmt.Errorf("monitor: ingest returned status %d without failure details", resp.StatusCode)
+		}
+	}
+	s.delivered(1, false)
+	return nil
+}
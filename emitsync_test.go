@@ -0,0 +1,109 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmitSync(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivered before returning", func(t *testing.T) {
+		server := newIngestRecorder(t, http.StatusOK)
+		if err := Init(Config{Service: "test-sync", DisableStdout: true, IngestURL: server.URL, APIKey: "key", FlushEvery: time.Hour}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		if err := EmitSync(ctx, "audit.critical", map[string]any{"id": 1}); err != nil {
+			t.Fatalf("EmitSync() error = %v", err)
+		}
+		body, keys := server.payload()
+		if bytes.Count(body, []byte("\n")) != 1 || !bytes.Contains(body, []byte(`"name":"audit.critical"`)) || keys[0] != "key" {
+			t.Errorf("server got %q with keys %v, want the single event with the API key", body, keys)
+		}
+		if st := Stats(); st.TotalShipped != 1 || st.QueuedEvents != 0 {
+			t.Errorf("Stats() = %+v, want 1 shipped and nothing queued", st)
+		}
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := newIngestRecorder(t, http.StatusInternalServerError)
+		if err := Init(Config{Service: "test-sync", DisableStdout: true, IngestURL: server.URL}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		err := EmitSync(ctx, "audit.critical", nil)
+		if err == nil || !strings.Contains(err.Error(), "500") {
+			t.Errorf("EmitSync() error = %v, want status 500", err)
+		}
+		if _, keys := server.payload(); len(keys) != 1 {
+			t.Errorf("server got %d requests, want 1 (no retries)", len(keys))
+		}
+	})
+
+	t.Run("context deadline", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+		if err := Init(Config{Service: "test-sync", DisableStdout: true, IngestURL: server.URL}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		deadlineCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if err := EmitSync(deadlineCtx, "audit.critical", nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("EmitSync() error = %v, want context.DeadlineExceeded", err)
+		}
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		if err := EmitSync(canceled, "audit.critical", nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("EmitSync() error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("Transport error", func(t *testing.T) {
+		rt := &recordingTransport{failN: 1}
+		if err := Init(Config{Service: "test-sync", DisableStdout: true, Transport: rt}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		if err := EmitSync(ctx, "audit.critical", nil); err == nil {
+			t.Error("EmitSync() should return the Transport's error")
+		}
+		if err := EmitSync(ctx, "audit.critical", nil); err != nil || rt.eventCount() != 1 {
+			t.Errorf("EmitSync() error = %v with %d events sent, want nil and 1", err, rt.eventCount())
+		}
+	})
+
+	t.Run("stdout only", func(t *testing.T) {
+		out := &lockedBuffer{}
+		if err := Init(Config{Service: "test-sync", Output: out}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if err := EmitSync(ctx, "audit.critical", nil); err != nil {
+			t.Errorf("EmitSync() error = %v, want nil without an ingest endpoint", err)
+		}
+		if lines := out.lines(); len(lines) != 1 {
+			t.Errorf("output = %v, want the event", lines)
+		}
+
+		Shutdown()
+		if err := EmitSync(ctx, "audit.critical", nil); err != ErrNotInitialized {
+			t.Errorf("EmitSync() after Shutdown error = %v, want ErrNotInitialized", err)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Encoder serializes events for stdout and the shipper, replacing the default
+// JSON encoding via Config.Encoder. Encode returns one event as a single line,
+// without the trailing newline. If the Encoder also has a ContentType() string
+// method, shipped batches are sent with that Content-Type unless
+// Config.IngestContentType overrides it.
+type Encoder interface {
+	Encode(event Event) ([]byte, error)
+}
+
+// contentTyper is implemented by Encoders that know their batch Content-Type.
+type contentTyper interface {
+	ContentType() string
+}
+
+// JSONEncoder encodes each event as a JSON object, the same as Event.ToJSON.
+// It is the default Encoder.
+type JSONEncoder struct{}
+
+// Encode implements Encoder.
+func (JSONEncoder) Encode(event Event) ([]byte, error) {
+	return event.ToJSON()
+}
+
+// ContentType returns "application/x-ndjson".
+func (JSONEncoder) ContentType() string {
+	return defaultIngestContentType
+}
+
+// logfmtContentType is the Content-Type of LogfmtEncoder batches.
+const logfmtContentType = "text/plain; charset=utf-8"
+
+// logfmtLeadingKeys are written first, in this order, so lines scan easily.
+// The remaining keys follow sorted.
+var logfmtLeadingKeys = []string{"timestamp", "level", "name", "service"}
+
+// LogfmtEncoder encodes each event as a logfmt line of key=value pairs, for
+// aggregators that ingest logfmt instead of JSON:
+//
+//	timestamp=2024-05-01T12:00:00Z level=info name=user.created service=api data.plan=pro data.user_id=42
+//
+// Keys are the event's JSON keys, with nested objects (data, correlations)
+// flattened into dotted keys. Arrays are written as JSON strings, and values
+// containing spaces, quotes, or "=" are quoted.
+type LogfmtEncoder struct{}
+
+// Encode implements Encoder.
+func (LogfmtEncoder) Encode(event Event) ([]byte, error) {
+	raw, err := event.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	// Reuse the JSON field names, omitempty rules, and Fields handling
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, key := range logfmtLeadingKeys {
+		if v, ok := fields[key]; ok {
+			writeLogfmt(&buf, key, v)
+			delete(fields, key)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		writeLogfmt(&buf, key, fields[key])
+	}
+	return buf.Bytes(), nil
+}
+
+// ContentType returns "text/plain; charset=utf-8".
+func (LogfmtEncoder) ContentType() string {
+	return logfmtContentType
+}
+
+// writeLogfmt appends key=v to buf, flattening objects into dotted keys.
+func writeLogfmt(buf *bytes.Buffer, key string, v any) {
+	if obj, ok := v.(map[string]any); ok {
+		for _, k := range slices.Sorted(maps.Keys(obj)) {
+			writeLogfmt(buf, key+"."+k, obj[k])
+		}
+		return
+	}
+
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+	switch x := v.(type) {
+	case nil:
+	case string:
+		buf.WriteString(logfmtValue(x))
+	case json.Number:
+		buf.WriteString(x.String())
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	default:
+		// Already decoded from JSON, so this can't fail
+		b, _ := json.Marshal(x)
+		buf.WriteString(logfmtValue(string(b)))
+	}
+}
+
+// logfmtKey replaces the characters a logfmt key can't contain with '_'.
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue returns s, quoted if it is empty or contains a space, '=', '"',
+// or a control character.
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == 0x7f
+	}) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// encodeEvent encodes event with cfg's Encoder, or as JSON by default.
+func encodeEvent(cfg *Config, event Event) ([]byte, error) {
+	if cfg.Encoder != nil {
+		return cfg.Encoder.Encode(event)
+	}
+	return event.ToJSON()
+}
@@ -0,0 +1,147 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseLogfmt decodes a logfmt line into its key/value pairs, in order.
+func parseLogfmt(t *testing.T, line string) (keys []string, values map[string]string) {
+	t.Helper()
+	values = make(map[string]string)
+	for line != "" {
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			t.Fatalf("logfmt pair without '=': %q", line)
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				t.Fatalf("bad quoted value %q: %v", rest, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+			rest = " " + rest
+		}
+		keys = append(keys, key)
+		values[key] = value
+		line = strings.TrimPrefix(rest, " ")
+	}
+	return keys, values
+}
+
+func testEncoderEvent() Event {
+	return Event{
+		Timestamp:    "2024-05-01T12:00:00Z",
+		Service:      "api",
+		RequestID:    "req-1",
+		Name:         "user.created",
+		Level:        LevelInfo,
+		Data:         map[string]any{"user_id": 42, "note": `say "hi" a=b`, "empty": "", "tags": []string{"a", "b"}},
+		Correlations: map[string]string{"order": "ord-1"},
+		Fields:       map[string]any{"region": "us-east-1"},
+	}
+}
+
+func TestJSONEncoder(t *testing.T) {
+	event := testEncoderEvent()
+	line, err := JSONEncoder{}.Encode(event)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	var got Event
+	if err := json.Unmarshal(line, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	// Data comes back with JSON types
+	want := event
+	want.Data = map[string]any{"user_id": 42.0, "note": `say "hi" a=b`, "empty": "", "tags": []any{"a", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	line, err := LogfmtEncoder{}.Encode(testEncoderEvent())
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if bytes.ContainsRune(line, '\n') {
+		t.Fatalf("Encode() = %q, want a single line", line)
+	}
+
+	keys, got := parseLogfmt(t, string(line))
+	want := map[string]string{
+		"timestamp":          "2024-05-01T12:00:00Z",
+		"level":              "info",
+		"name":               "user.created",
+		"service":            "api",
+		"request_id":         "req-1",
+		"correlations.order": "ord-1",
+		"data.user_id":       "42",
+		"data.note":          `say "hi" a=b`,
+		"data.empty":         "",
+		"data.tags":          `["a","b"]`,
+		"region":             "us-east-1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("logfmt pairs = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(keys[:4], []string{"timestamp", "level", "name", "service"}) {
+		t.Errorf("leading keys = %v, want timestamp, level, name, service", keys[:4])
+	}
+}
+
+func TestConfigEncoder(t *testing.T) {
+	var gotContentType, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		var buf bytes.Buffer
+		buf.ReadFrom(r.Body)
+		gotBody = buf.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var out lockedBuffer
+	m, err := New(Config{
+		Service:    "test-encoder",
+		IngestURL:  server.URL,
+		Encoder:    LogfmtEncoder{},
+		Output:     &out,
+		FlushEvery: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+
+	m.Emit(t.Context(), "test.encoder", map[string]any{"n": 1})
+	m.Flush()
+
+	lines := out.lines()
+	if len(lines) != 1 || !strings.Contains(lines[0], "name=test.encoder") || !strings.Contains(lines[0], "data.n=1") {
+		t.Errorf("stdout = %v, want one logfmt line", lines)
+	}
+	if gotContentType != logfmtContentType {
+		t.Errorf("Content-Type = %q, want %q", gotContentType, logfmtContentType)
+	}
+	if !strings.Contains(gotBody, "name=test.encoder") {
+		t.Errorf("shipped body = %q, want a logfmt line", gotBody)
+	}
+
+	if _, err := New(Config{Service: "test-encoder", Encoder: LogfmtEncoder{}, ExportFormat: ExportFormatOTLPLogs}); err == nil {
+		t.Error("New() should reject Encoder with ExportFormatOTLPLogs")
+	}
+}
@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"sync"
+)
+
+// Endpoint is an additional ingest endpoint that receives every batch. See
+// Config.Endpoints.
+type Endpoint struct {
+	// URL is the URL to POST NDJSON batches to. Required.
+	URL string
+
+	// APIKey authenticates with this endpoint. Config.APIKey is never sent to
+	// it, so a vendor doesn't receive your own ingest key. Optional.
+	APIKey string
+
+	// AuthHeader, AuthScheme, and ExtraHeaders are this endpoint's own
+	// settings, as for the Config fields of the same names; the Config values
+	// are never sent to it. Optional.
+	AuthHeader   string
+	AuthScheme   string
+	ExtraHeaders map[string]string
+}
+
+// extraEndpoints returns the endpoints in IngestURLs and Endpoints, which
+// receive every batch in addition to IngestURL or Transport. IngestURLs are
+// the primary ingest's own, so they share its credentials and headers.
+func extraEndpoints(cfg *Config) []Endpoint {
+	endpoints := make([]Endpoint, 0, len(cfg.IngestURLs)+len(cfg.Endpoints))
+	for _, u := range cfg.IngestURLs {
+		endpoints = append(endpoints, Endpoint{
+			URL:          u,
+			APIKey:       cfg.APIKey,
+			AuthHeader:   cfg.AuthHeader,
+			AuthScheme:   cfg.AuthScheme,
+			ExtraHeaders: cfg.ExtraHeaders,
+		})
+	}
+	return append(endpoints, cfg.Endpoints...)
+}
+
+// endpointConfig returns a copy of cfg for a shipper that delivers to ep
+// alone, with ep's credentials and headers in place of cfg's. When
+// spillSubdir is set, its spilled events go in that subdirectory of SpillDir
+// so endpoints never replay each other's files.
+func endpointConfig(cfg *Config, ep Endpoint, spillSubdir string) *Config {
+	c := *cfg
+	c.IngestURL, c.APIKey = ep.URL, ep.APIKey
+	c.AuthHeader, c.AuthScheme, c.ExtraHeaders = ep.AuthHeader, ep.AuthScheme, ep.ExtraHeaders
+	c.Transport = nil
+	c.IngestURLs, c.Endpoints = nil, nil
+	if c.SpillDir != "" && spillSubdir != "" {
+		c.SpillDir = filepath.Join(c.SpillDir, spillSubdir)
+	}
+	return &c
+}
+
+// endpointSpillSubdir names an endpoint's spill subdirectory after its URL,
+// so reordering endpoints keeps each one's spilled events with it.
+func endpointSpillSubdir(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "endpoint-" + hex.EncodeToString(sum[:8])
+}
+
+// newFanoutShipper creates the shipper for cfg. It delivers to Transport or
+// IngestURL (or, without either, the first extra endpoint), and each further
+// endpoint gets a peer shipper with its own queue, retries, and spill.
+func newFanoutShipper(m *Monitor, cfg *Config) *shipper {
+	extra := extraEndpoints(cfg)
+	primary := cfg
+	if cfg.Transport == nil && cfg.IngestURL == "" {
+		primary = endpointConfig(cfg, extra[0], "")
+		extra = extra[1:]
+	}
+
+	s := newShipper(m, primary)
+	for _, ep := range extra {
+		s.peers = append(s.peers, newShipper(m, endpointConfig(cfg, ep, endpointSpillSubdir(ep.URL))))
+	}
+	return s
+}
+
+// all returns s followed by its peers.
+func (s *shipper) all() []*shipper {
+	return append([]*shipper{s}, s.peers...)
+}
+
+// flushContext flushes s and its peers concurrently, so a slow endpoint
+// doesn't delay the others. It returns ctx.Err() if ctx ended, otherwise
+// every endpoint's flush error joined.
+func (s *shipper) flushContext(ctx context.Context) error {
+	if len(s.peers) == 0 {
+		return s.flushOwn(ctx)
+	}
+
+	shippers := s.all()
+	errs := make([]error, len(shippers))
+	var wg sync.WaitGroup
+	for i, sh := range shippers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = sh.flushOwn(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
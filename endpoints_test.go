@@ -0,0 +1,223 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ingestRecorder is an httptest ingest endpoint that records request bodies
+// and API keys, replying with status.
+type ingestRecorder struct {
+	*httptest.Server
+	mu      sync.Mutex
+	bodies  [][]byte
+	keys    []string
+	headers http.Header
+}
+
+func newIngestRecorder(t *testing.T, status int) *ingestRecorder {
+	rec := &ingestRecorder{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.bodies = append(rec.bodies, body)
+		rec.keys = append(rec.keys, r.Header.Get("X-Api-Key"))
+		rec.headers = r.Header.Clone()
+		rec.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+func (rec *ingestRecorder) payload() ([]byte, []string) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return bytes.Join(rec.bodies, nil), append([]string(nil), rec.keys...)
+}
+
+func TestFanoutEndpoints(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("every endpoint receives the same payload", func(t *testing.T) {
+		primary := newIngestRecorder(t, http.StatusOK)
+		extra := newIngestRecorder(t, http.StatusOK)
+		vendor := newIngestRecorder(t, http.StatusOK)
+		if err := Init(Config{
+			Service:       "test-fanout",
+			DisableStdout: true,
+			IngestURL:     primary.URL,
+			APIKey:        "primary-key",
+			IngestURLs:    []string{extra.URL},
+			Endpoints:     []Endpoint{{URL: vendor.URL, APIKey: "vendor-key"}},
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		for _, name := range []string{"test.one", "test.two", "test.three"} {
+			Emit(ctx, name, nil)
+		}
+		if err := FlushContext(ctx); err != nil {
+			t.Fatalf("FlushContext() error = %v", err)
+		}
+
+		want, keys := primary.payload()
+		if bytes.Count(want, []byte("\n")) != 3 || len(keys) != 1 || keys[0] != "primary-key" {
+			t.Fatalf("primary got %q with keys %v, want 3 events with primary-key", want, keys)
+		}
+		if got, keys := extra.payload(); !bytes.Equal(got, want) || keys[0] != "primary-key" {
+			t.Errorf("IngestURLs endpoint got %q with keys %v, want the primary payload with primary-key", got, keys)
+		}
+		if got, keys := vendor.payload(); !bytes.Equal(got, want) || keys[0] != "vendor-key" {
+			t.Errorf("vendor got %q with keys %v, want the primary payload with vendor-key only", got, keys)
+		}
+		if st := Stats(); st.TotalShipped != 9 || st.TotalBatches != 3 {
+			t.Errorf("Stats() = %+v, want 9 shipped in 3 batches", st)
+		}
+	})
+
+	t.Run("headers stay with their endpoint", func(t *testing.T) {
+		primary := newIngestRecorder(t, http.StatusOK)
+		extra := newIngestRecorder(t, http.StatusOK)
+		vendor := newIngestRecorder(t, http.StatusOK)
+		if err := Init(Config{
+			Service:       "test-fanout",
+			DisableStdout: true,
+			IngestURL:     primary.URL,
+			APIKey:        "primary-key",
+			AuthHeader:    "Authorization",
+			AuthScheme:    "Bearer",
+			ExtraHeaders:  map[string]string{"X-Tenant": "acme"},
+			IngestURLs:    []string{extra.URL},
+			Endpoints: []Endpoint{{
+				URL:          vendor.URL,
+				APIKey:       "vendor-key",
+				AuthHeader:   "X-Vendor-Token",
+				ExtraHeaders: map[string]string{"X-Vendor-Source": "acme"},
+			}},
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		Emit(ctx, "test.one", nil)
+		if err := FlushContext(ctx); err != nil {
+			t.Fatalf("FlushContext() error = %v", err)
+		}
+
+		for name, rec := range map[string]*ingestRecorder{"primary": primary, "IngestURLs": extra} {
+			rec.mu.Lock()
+			h := rec.headers
+			rec.mu.Unlock()
+			if h.Get("Authorization") != "Bearer primary-key" || h.Get("X-Tenant") != "acme" {
+				t.Errorf("%s headers = %v, want the primary credentials and extra headers", name, h)
+			}
+		}
+		vendor.mu.Lock()
+		h := vendor.headers
+		vendor.mu.Unlock()
+		if h.Get("X-Vendor-Token") != "vendor-key" || h.Get("X-Vendor-Source") != "acme" {
+			t.Errorf("vendor headers = %v, want its own credentials and extra headers", h)
+		}
+		if h.Get("Authorization") != "" || h.Get("X-Tenant") != "" {
+			t.Errorf("vendor headers = %v, want none of the primary's", h)
+		}
+	})
+
+	t.Run("IngestURLs without IngestURL", func(t *testing.T) {
+		first := newIngestRecorder(t, http.StatusOK)
+		second := newIngestRecorder(t, http.StatusOK)
+		if err := Init(Config{Service: "test-fanout", DisableStdout: true, IngestURLs: []string{first.URL, second.URL}}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		Emit(ctx, "test.one", nil)
+		Flush()
+		a, _ := first.payload()
+		b, _ := second.payload()
+		if len(a) == 0 || !bytes.Equal(a, b) {
+			t.Errorf("payloads = %q and %q, want the same event at both", a, b)
+		}
+	})
+
+	t.Run("a failing endpoint doesn't stop the others", func(t *testing.T) {
+		primary := newIngestRecorder(t, http.StatusOK)
+		vendor := newIngestRecorder(t, http.StatusServiceUnavailable)
+		if err := Init(Config{
+			Service:        "test-fanout",
+			DisableStdout:  true,
+			IngestURL:      primary.URL,
+			Endpoints:      []Endpoint{{URL: vendor.URL}},
+			MaxRetries:     1,
+			RetryBaseDelay: time.Millisecond,
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		Emit(ctx, "test.one", nil)
+		Emit(ctx, "test.two", nil)
+		Flush()
+
+		if got, _ := primary.payload(); bytes.Count(got, []byte("\n")) != 2 {
+			t.Errorf("primary got %q, want both events", got)
+		}
+		st := Stats()
+		if st.TotalShipped != 2 || st.TotalDropped != 2 || st.LastFlushError == nil {
+			t.Errorf("Stats() = %+v, want 2 shipped, 2 dropped by the vendor, and its flush error", st)
+		}
+	})
+
+	t.Run("a slow endpoint doesn't block the others", func(t *testing.T) {
+		primary := newIngestRecorder(t, http.StatusOK)
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+
+		if err := Init(Config{
+			Service:       "test-fanout",
+			DisableStdout: true,
+			IngestURL:     primary.URL,
+			IngestURLs:    []string{slow.URL},
+			BatchSize:     1,
+			FlushEvery:    time.Hour,
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		// Deferred calls run last to first: unblock the stalled endpoint, then shut down
+		defer slow.Close()
+		defer Shutdown()
+		defer close(release)
+
+		Emit(ctx, "test.one", nil)
+		Emit(ctx, "test.two", nil)
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			if got, _ := primary.payload(); bytes.Count(got, []byte("\n")) == 2 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("primary did not receive events while the other endpoint was stalled")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	t.Run("empty URL", func(t *testing.T) {
+		if err := Init(Config{Service: "test-fanout", Endpoints: []Endpoint{{APIKey: "key"}}}); err == nil {
+			t.Error("Init() with an Endpoint without URL should fail")
+		}
+		if err := Init(Config{Service: "test-fanout", IngestURLs: []string{""}}); err == nil {
+			t.Error("Init() with an empty IngestURLs entry should fail")
+		}
+	})
+}
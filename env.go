@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by ConfigFromEnv and InitFromEnv.
+const (
+	EnvService    = "MONITOR_SERVICE"
+	EnvEnv        = "MONITOR_ENV"
+	EnvIngestURL  = "MONITOR_INGEST_URL"
+	EnvAPIKey     = "MONITOR_API_KEY"
+	EnvBatchSize  = "MONITOR_BATCH_SIZE"  // integer, e.g. "500"
+	EnvFlushEvery = "MONITOR_FLUSH_EVERY" // Go duration, e.g. "2s"
+	EnvGzip       = "MONITOR_GZIP"        // boolean, e.g. "true" or "1"
+)
+
+// InitFromEnv initializes the default monitor from the MONITOR_* environment
+// variables, so one binary can be deployed to many environments. It is
+// shorthand for Init with the result of ConfigFromEnv(Config{}).
+func InitFromEnv() error {
+	cfg, err := ConfigFromEnv(Config{})
+	if err != nil {
+		return err
+	}
+	return Init(cfg)
+}
+
+// ConfigFromEnv returns base with the fields set by MONITOR_* environment
+// variables overridden: Service, Env, IngestURL, APIKey, BatchSize,
+// FlushEvery, and GzipEnabled. Unset or empty variables leave base's value.
+// Malformed values are reported together in the returned error.
+//
+// Usage:
+//
+//	cfg, err := monitor.ConfigFromEnv(monitor.Config{Service: "api", RedactKeys: keys})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = monitor.Init(cfg)
+func ConfigFromEnv(base Config) (Config, error) {
+	cfg := base
+	if v := os.Getenv(EnvService); v != "" {
+		cfg.Service = v
+	}
+	if v := os.Getenv(EnvEnv); v != "" {
+		cfg.Env = v
+	}
+	if v := os.Getenv(EnvIngestURL); v != "" {
+		cfg.IngestURL = v
+	}
+	if v := os.Getenv(EnvAPIKey); v != "" {
+		cfg.APIKey = v
+	}
+
+	var errs []error
+	if v := os.Getenv(EnvBatchSize); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			errs = append(errs, fmt.Errorf("monitor: %s=%q is not a positive integer", EnvBatchSize, v))
+		} else {
+			cfg.BatchSize = n
+		}
+	}
+	if v := os.Getenv(EnvFlushEvery); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("monitor: %s=%q is not a positive duration (e.g., \"2s\")", EnvFlushEvery, v))
+		} else {
+			cfg.FlushEvery = d
+		}
+	}
+	if v := os.Getenv(EnvGzip); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("monitor: %s=%q is not a boolean", EnvGzip, v))
+		} else {
+			cfg.GzipEnabled = b
+		}
+	}
+	if len(errs) > 0 {
+		return base, errors.Join(errs...)
+	}
+	return cfg, nil
+}
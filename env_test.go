@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Run("parses every variable", func(t *testing.T) {
+		t.Setenv(EnvService, "env-service")
+		t.Setenv(EnvEnv, "staging")
+		t.Setenv(EnvIngestURL, "https://ingest.example.com/events")
+		t.Setenv(EnvAPIKey, "secret")
+		t.Setenv(EnvBatchSize, "500")
+		t.Setenv(EnvFlushEvery, "2s")
+		t.Setenv(EnvGzip, "true")
+
+		cfg, err := ConfigFromEnv(Config{})
+		if err != nil {
+			t.Fatalf("ConfigFromEnv() error = %v", err)
+		}
+		if cfg.Service != "env-service" || cfg.Env != "staging" || cfg.IngestURL != "https://ingest.example.com/events" || cfg.APIKey != "secret" {
+			t.Errorf("string fields = %q/%q/%q/%q", cfg.Service, cfg.Env, cfg.IngestURL, cfg.APIKey)
+		}
+		if cfg.BatchSize != 500 || cfg.FlushEvery != 2*time.Second || !cfg.GzipEnabled {
+			t.Errorf("BatchSize/FlushEvery/GzipEnabled = %d/%v/%v, want 500/2s/true", cfg.BatchSize, cfg.FlushEvery, cfg.GzipEnabled)
+		}
+	})
+
+	t.Run("overrides base and keeps unset fields", func(t *testing.T) {
+		t.Setenv(EnvService, "")
+		t.Setenv(EnvEnv, "prod")
+		base := Config{Service: "base-service", Env: "dev", BatchSize: 50, Debug: true}
+
+		cfg, err := ConfigFromEnv(base)
+		if err != nil {
+			t.Fatalf("ConfigFromEnv() error = %v", err)
+		}
+		if cfg.Service != "base-service" {
+			t.Errorf("Service = %q, want base value when the variable is empty", cfg.Service)
+		}
+		if cfg.Env != "prod" {
+			t.Errorf("Env = %q, want the environment to override base", cfg.Env)
+		}
+		if cfg.BatchSize != 50 || !cfg.Debug {
+			t.Errorf("BatchSize/Debug = %d/%v, want base values", cfg.BatchSize, cfg.Debug)
+		}
+	})
+
+	t.Run("reports malformed values", func(t *testing.T) {
+		t.Setenv(EnvBatchSize, "lots")
+		t.Setenv(EnvFlushEvery, "5")
+		t.Setenv(EnvGzip, "maybe")
+
+		_, err := ConfigFromEnv(Config{Service: "base-service"})
+		if err == nil {
+			t.Fatal("ConfigFromEnv() should fail on malformed values")
+		}
+		for _, want := range []string{`MONITOR_BATCH_SIZE="lots"`, `MONITOR_FLUSH_EVERY="5"`, `MONITOR_GZIP="maybe"`} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error = %v, want it to mention %s", err, want)
+			}
+		}
+	})
+
+	t.Run("rejects non-positive values", func(t *testing.T) {
+		t.Setenv(EnvBatchSize, "0")
+		t.Setenv(EnvFlushEvery, "-1s")
+		if _, err := ConfigFromEnv(Config{}); err == nil {
+			t.Error("ConfigFromEnv() should reject a zero batch size and negative interval")
+		}
+	})
+}
+
+func TestInitFromEnv(t *testing.T) {
+	t.Run("initializes with defaults", func(t *testing.T) {
+		t.Setenv(EnvService, "env-init")
+		if err := InitFromEnv(); err != nil {
+			t.Fatalf("InitFromEnv() error = %v", err)
+		}
+		defer Shutdown()
+
+		cfg := defaultMonitor.config.Load()
+		if cfg.Service != "env-init" {
+			t.Errorf("Service = %q, want env-init", cfg.Service)
+		}
+		if cfg.BatchSize != 200 || cfg.FlushEvery != time.Second {
+			t.Errorf("BatchSize/FlushEvery = %d/%v, want Init defaults 200/1s", cfg.BatchSize, cfg.FlushEvery)
+		}
+	})
+
+	t.Run("requires a service", func(t *testing.T) {
+		t.Setenv(EnvService, "")
+		if err := InitFromEnv(); err != ErrServiceRequired {
+			t.Errorf("InitFromEnv() error = %v, want ErrServiceRequired", err)
+		}
+	})
+
+	t.Run("fails on malformed values", func(t *testing.T) {
+		t.Setenv(EnvService, "env-init")
+		t.Setenv(EnvFlushEvery, "soon")
+		if err := InitFromEnv(); err == nil {
+			t.Error("InitFromEnv() should fail on a malformed duration")
+		}
+	})
+}
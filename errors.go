@@ -2,14 +2,28 @@ package monitor
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"runtime"
+	"strings"
 )
 
 // CaptureError emits an error-level event named "error.captured" with error details,
-// stack trace, and optional additional data.
+// stack trace, and optional additional data. A nil err, including a nil
+// pointer of an error type, emits nothing.
 func CaptureError(ctx context.Context, err error, data ...map[string]any) {
-	if err == nil {
+	defaultMonitor.captureError(ctx, err, data, 2)
+}
+
+// CaptureError emits an "error.captured" event through m, like the
+// package-level CaptureError.
+func (m *Monitor) CaptureError(ctx context.Context, err error, data ...map[string]any) {
+	m.captureError(ctx, err, data, 2)
+}
+
+// captureError implements CaptureError for callers at the given depth.
+func (m *Monitor) captureError(ctx context.Context, err error, data []map[string]any, callerDepth int) {
+	if isNilError(err) {
 		return
 	}
 
@@ -31,5 +45,153 @@ func CaptureError(ctx context.Context, err error, data ...map[string]any) {
 		}
 	}
 
-	emitWithCallerDepth(ctx, "error.captured", eventData, LevelError, 2)
+	m.emitWithCallerDepth(ctx, "error.captured", eventData, LevelError, callerDepth+1)
+}
+
+// maxStackFrames caps the frames EmitError records under data.stack.
+const maxStackFrames = 32
+
+// EmitError emits an error-level event named name for err, with err.Error()
+// under data.error. If err wraps other errors (Unwrap() error or
+// Unwrap() []error), every error in the chain, err first, is listed under
+// data.error_chain. When Config.CaptureStack is true, the caller's stack is
+// recorded under data.stack. opts apply as for Emit; WithLevel overrides the
+// level. A nil err, including a nil pointer of an error type, emits nothing.
+func EmitError(ctx context.Context, name string, err error, opts ...EmitOption) {
+	defaultMonitor.emitError(ctx, name, err, opts, 2)
+}
+
+// EmitError emits an error event through m, like the package-level EmitError.
+func (m *Monitor) EmitError(ctx context.Context, name string, err error, opts ...EmitOption) {
+	m.emitError(ctx, name, err, opts, 2)
+}
+
+// emitError implements EmitError for callers at the given depth.
+func (m *Monitor) emitError(ctx context.Context, name string, err error, opts []EmitOption, callerDepth int) {
+	if isNilError(err) {
+		return
+	}
+	cfg := m.activeConfig()
+	if cfg == nil {
+		return
+	}
+
+	data := map[string]any{"error": err.Error()}
+	if chain := errorChain(nil, err); len(chain) > 1 {
+		data["error_chain"] = chain
+	}
+	if cfg.CaptureStack {
+		data["stack"] = callerStack(callerDepth + 1)
+	}
+
+	opts = append([]EmitOption{WithLevel(LevelError)}, opts...)
+	m.emitWithOptions(ctx, name, data, opts, callerDepth+1)
+}
+
+// errorChain appends the messages of err and every error it wraps, depth first.
+func errorChain(dst []string, err error) []string {
+	dst = append(dst, err.Error())
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := u.Unwrap(); !isNilError(next) {
+			dst = errorChain(dst, next)
+		}
+	case interface{ Unwrap() []error }:
+		for _, next := range u.Unwrap() {
+			if !isNilError(next) {
+				dst = errorChain(dst, next)
+			}
+		}
+	}
+	return dst
+}
+
+// callerStack formats the stack starting skip frames above callerStack, in
+// the "function\n\tfile:line" form of runtime.Stack, without the goroutine
+// header and capped at maxStackFrames.
+func callerStack(skip int) string {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// EmitErrors emits a single error-level event named name listing every non-nil
+// error in errs, instead of one event per error. Errors combined with
+// errors.Join (or any error with an Unwrap() []error method) are expanded into
+// their parts. The event data is data plus "errors" (messages), "error_types",
+// and "error_count". Nil pointers of error types count as nil. If no error is
+// non-nil, nothing is emitted.
+func EmitErrors(ctx context.Context, name string, errs []error, data map[string]any) {
+	defaultMonitor.emitErrors(ctx, name, errs, data, 2)
+}
+
+// EmitErrors emits a combined error event through m, like the package-level
+// EmitErrors.
+func (m *Monitor) EmitErrors(ctx context.Context, name string, errs []error, data map[string]any) {
+	m.emitErrors(ctx, name, errs, data, 2)
+}
+
+// emitErrors implements EmitErrors for callers at the given depth.
+func (m *Monitor) emitErrors(ctx context.Context, name string, errs []error, data map[string]any, callerDepth int) {
+	flat := flattenErrors(nil, errs)
+	if len(flat) == 0 {
+		return
+	}
+
+	messages := make([]string, len(flat))
+	types := make([]string, len(flat))
+	for i, err := range flat {
+		messages[i] = err.Error()
+		types[i] = reflect.TypeOf(err).String()
+	}
+
+	eventData := make(map[string]any, len(data)+3)
+	for k, v := range data {
+		eventData[k] = v
+	}
+	eventData["errors"] = messages
+	eventData["error_types"] = types
+	eventData["error_count"] = len(flat)
+
+	m.emitWithCallerDepth(ctx, name, eventData, LevelError, callerDepth+1)
+}
+
+// isNilError reports whether err is nil or a nil pointer (or other nilable
+// value) of a concrete error type, such as a nil *MyError returned as error.
+// Calling Error on those would usually panic, so they count as no error.
+func isNilError(err error) bool {
+	if err == nil {
+		return true
+	}
+	v := reflect.ValueOf(err)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// flattenErrors appends the non-nil errors in errs to dst, expanding joined errors.
+func flattenErrors(dst []error, errs []error) []error {
+	for _, err := range errs {
+		if isNilError(err) {
+			continue
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			dst = flattenErrors(dst, joined.Unwrap())
+			continue
+		}
+		dst = append(dst, err)
+	}
+	return dst
 }
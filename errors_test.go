@@ -3,6 +3,8 @@ package monitor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -40,9 +42,164 @@ func TestCaptureError(t *testing.T) {
 	})
 
 	t.Run("error before init", func(t *testing.T) {
-		globalConfig.Store(nil)
+		defaultMonitor.config.Store(nil)
 		err := errors.New("test error")
 		// Should not panic
 		CaptureError(context.Background(), err)
 	})
 }
+
+// pathError dereferences its receiver, so a nil *pathError panics in Error.
+type pathError struct{ path string }
+
+func (e *pathError) Error() string { return "bad path " + e.path }
+
+func TestEmitErrors(t *testing.T) {
+	if err := Init(Config{Service: "test-errors", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	t.Run("aggregates into one event", func(t *testing.T) {
+		errRow := errors.New("row 2: missing email")
+		joined := errors.Join(errors.New("row 5: bad date"), nil, errors.New("row 9: duplicate"))
+
+		events := Captured(func() {
+			EmitErrors(context.Background(), "import.validation_failed",
+				[]error{errRow, nil, joined}, map[string]any{"file": "users.csv"})
+		})
+
+		if len(events) != 1 {
+			t.Fatalf("emitted %d events, want 1", len(events))
+		}
+		e := events[0]
+		if e.Name != "import.validation_failed" || e.Level != LevelError {
+			t.Errorf("event = %s at %s, want import.validation_failed at error", e.Name, e.Level)
+		}
+		data := e.Data.(map[string]any)
+		want := []string{"row 2: missing email", "row 5: bad date", "row 9: duplicate"}
+		got, _ := data["errors"].([]string)
+		if len(got) != len(want) {
+			t.Fatalf("errors = %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("errors[%d] = %q, want %q", i, got[i], want[i])
+			}
+		}
+		if types, _ := data["error_types"].([]string); len(types) != 3 || types[0] != "*errors.errorString" {
+			t.Errorf("error_types = %v, want 3 types starting with *errors.errorString", types)
+		}
+		if data["error_count"] != 3 || data["file"] != "users.csv" {
+			t.Errorf("data = %v, want error_count 3 and file", data)
+		}
+	})
+
+	t.Run("typed nil skipped", func(t *testing.T) {
+		var typedNil *pathError
+		events := Captured(func() {
+			EmitErrors(context.Background(), "import.validation_failed", []error{typedNil, errors.New("row 1")}, nil)
+			EmitError(context.Background(), "import.failed", typedNil)
+			CaptureError(context.Background(), typedNil)
+		})
+		if len(events) != 1 || events[0].Data.(map[string]any)["error_count"] != 1 {
+			t.Errorf("events = %+v, want one event counting 1 error", events)
+		}
+	})
+
+	t.Run("all nil is no-op", func(t *testing.T) {
+		var typedNil *pathError
+		events := Captured(func() {
+			EmitErrors(context.Background(), "import.validation_failed", []error{nil, typedNil}, nil)
+			EmitErrors(context.Background(), "import.validation_failed", nil, nil)
+		})
+		if len(events) != 0 {
+			t.Errorf("emitted %d events, want 0", len(events))
+		}
+	})
+}
+
+func TestEmitError(t *testing.T) {
+	emitOne := func(t *testing.T, fn func()) Event {
+		t.Helper()
+		events := Captured(fn)
+		if len(events) != 1 {
+			t.Fatalf("captured %d events, want 1", len(events))
+		}
+		return events[0]
+	}
+
+	t.Run("error level and message", func(t *testing.T) {
+		if err := Init(Config{Service: "test-emit-error", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		e := emitOne(t, func() {
+			EmitError(context.Background(), "payment.failed", errors.New("card declined"))
+		})
+		data := e.Data.(map[string]any)
+		if e.Name != "payment.failed" || e.Level != LevelError || data["error"] != "card declined" {
+			t.Errorf("event = %s/%s with data %v, want payment.failed/error with the message", e.Name, e.Level, data)
+		}
+		if _, ok := data["stack"]; ok {
+			t.Error("data.stack set without CaptureStack")
+		}
+		if _, ok := data["error_chain"]; ok {
+			t.Error("data.error_chain set for an unwrapped error")
+		}
+		if data["source_file"] != "errors_test.go" {
+			t.Errorf("source_file = %v, want the caller's file", data["source_file"])
+		}
+	})
+
+	t.Run("error chain", func(t *testing.T) {
+		if err := Init(Config{Service: "test-emit-error", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		root := errors.New("connection refused")
+		err := fmt.Errorf("charge: %w", fmt.Errorf("dial: %w", root))
+		e := emitOne(t, func() {
+			EmitError(context.Background(), "payment.failed", err)
+		})
+		chain, _ := e.Data.(map[string]any)["error_chain"].([]string)
+		want := []string{"charge: dial: connection refused", "dial: connection refused", "connection refused"}
+		if strings.Join(chain, "|") != strings.Join(want, "|") {
+			t.Errorf("error_chain = %q, want %q", chain, want)
+		}
+	})
+
+	t.Run("stack only with CaptureStack", func(t *testing.T) {
+		if err := Init(Config{Service: "test-emit-error", DisableStdout: true, CaptureStack: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		e := emitOne(t, func() {
+			EmitError(context.Background(), "payment.failed", errors.New("card declined"))
+		})
+		stack, _ := e.Data.(map[string]any)["stack"].(string)
+		if !strings.HasPrefix(stack, "github.com/aidenappl/go-monitor.TestEmitError.") {
+			t.Errorf("stack = %q, want it to start at the caller of EmitError", stack)
+		}
+		if strings.Contains(stack, "monitor.EmitError") || strings.Contains(stack, "goroutine ") {
+			t.Errorf("stack = %q, want EmitError and the goroutine header trimmed", stack)
+		}
+	})
+
+	t.Run("options and level override", func(t *testing.T) {
+		if err := Init(Config{Service: "test-emit-error", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		e := emitOne(t, func() {
+			EmitError(context.Background(), "cache.miss", errors.New("stale"), WithLevel(LevelWarn), WithLink("redis", "k1"))
+		})
+		if e.Level != LevelWarn || e.LinkID("redis") != "k1" {
+			t.Errorf("event level = %s, links = %v, want warn with the redis link", e.Level, e.Links)
+		}
+	})
+
+	t.Run("nil error emits nothing", func(t *testing.T) {
+		if err := Init(Config{Service: "test-emit-error", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if events := Captured(func() { EmitError(context.Background(), "noop", nil) }); len(events) != 0 {
+			t.Errorf("captured %d events, want 0", len(events))
+		}
+	})
+}
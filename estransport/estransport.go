@@ -0,0 +1,249 @@
+// Package estransport ships go-monitor batches straight into OpenSearch or
+// Elasticsearch using the _bulk API.
+//
+// Each event becomes a "create" action line followed by the event's JSON as
+// the document source. The target index can include date patterns resolved
+// from the event's timestamp, so "events-%Y.%m.%d" writes to daily indices.
+// "create" works for both regular indices and data streams.
+//
+// Usage:
+//
+//	t, err := estransport.New(estransport.Config{
+//	    URL:   "https://search.example.com:9200",
+//	    Index: "events-%Y.%m.%d",
+//	})
+//	monitor.Init(monitor.Config{Service: "api", Transport: t})
+package estransport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+// bulkPath is the bulk API endpoint.
+const bulkPath = "/_bulk"
+
+// maxResponseBody bounds how much of a bulk response is read.
+const maxResponseBody = 4 << 20
+
+// ErrURLRequired is returned when Config.URL is empty.
+var ErrURLRequired = errors.New("estransport: Config.URL is required")
+
+// Config configures the bulk transport.
+type Config struct {
+	// URL is the cluster base URL (e.g., "https://search.example.com:9200").
+	// The bulk path is appended unless the URL already ends with it. Required.
+	URL string
+
+	// Index is the target index. Supports %Y (year), %m (month), %d (day),
+	// and %H (hour) from the event's UTC timestamp, and %% for a literal '%'.
+	// Default: "events-%Y.%m.%d".
+	Index string
+
+	// Username and Password enable basic auth. Optional.
+	Username string
+	Password string
+
+	// APIKey is sent as "Authorization: ApiKey <APIKey>" (the base64-encoded
+	// id:key credential). Takes precedence over basic auth. Optional.
+	APIKey string
+
+	// HTTPClient is used for bulk requests. Default: a client with a 30s timeout.
+	HTTPClient *http.Client
+}
+
+// Transport implements monitor.Transport for the _bulk API.
+type Transport struct {
+	cfg     Config
+	bulkURL string
+	client  *http.Client
+}
+
+// New creates a bulk transport.
+func New(cfg Config) (*Transport, error) {
+	if cfg.URL == "" {
+		return nil, ErrURLRequired
+	}
+	if cfg.Index == "" {
+		cfg.Index = "events-%Y.%m.%d"
+	}
+
+	bulkURL := strings.TrimSuffix(cfg.URL, "/")
+	if !strings.HasSuffix(bulkURL, bulkPath) {
+		bulkURL += bulkPath
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Transport{cfg: cfg, bulkURL: bulkURL, client: client}, nil
+}
+
+// action is a bulk action/metadata line.
+type action struct {
+	Create struct {
+		Index string `json:"_index"`
+	} `json:"create"`
+}
+
+// bulkResponse is the subset of the bulk response used to detect item failures.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// Send implements monitor.Transport. Items the cluster rejects as retryable
+// (429 or 5xx) fail the whole Send so the shipper retries the batch; note that
+// already-indexed items in that batch are then indexed again. Items rejected
+// permanently (e.g., mapping errors) are reported to stderr and not retried.
+func (t *Transport) Send(ctx context.Context, batch []monitor.Event) error {
+	body, err := t.buildBulk(batch)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.bulkURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("estransport: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	switch {
+	case t.cfg.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+t.cfg.APIKey)
+	case t.cfg.Username != "" || t.cfg.Password != "":
+		req.SetBasicAuth(t.cfg.Username, t.cfg.Password)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("estransport: bulk: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode >= 300 {
+		msg := respBody
+		if len(msg) > 512 {
+			msg = msg[:512]
+		}
+		return fmt.Errorf("estransport: bulk returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return checkItems(respBody)
+}
+
+// buildBulk encodes the batch as alternating action and source lines.
+func (t *Transport) buildBulk(batch []monitor.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, event := range batch {
+		source, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("estransport: marshal event: %w", err)
+		}
+
+		var a action
+		a.Create.Index = indexName(t.cfg.Index, event.Timestamp)
+		meta, err := json.Marshal(a)
+		if err != nil {
+			return nil, fmt.Errorf("estransport: marshal action: %w", err)
+		}
+
+		buf.Write(meta)
+		buf.WriteByte('\n')
+		buf.Write(source)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// checkItems inspects per-item results of a successful bulk request.
+func checkItems(body []byte) error {
+	var resp bulkResponse
+	if err := json.Unmarshal(body, &resp); err != nil || !resp.Errors {
+		return nil
+	}
+
+	var retryable, permanent int
+	var firstReason string
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Error == nil {
+				continue
+			}
+			if firstReason == "" {
+				firstReason = result.Error.Type + ": " + result.Error.Reason
+			}
+			if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+				retryable++
+			} else {
+				permanent++
+			}
+		}
+	}
+
+	if retryable > 0 {
+		return fmt.Errorf("estransport: %d bulk items failed (%s)", retryable+permanent, firstReason)
+	}
+	if permanent > 0 {
+		fmt.Fprintf(os.Stderr, "estransport: %d bulk items rejected (%s)\n", permanent, firstReason)
+	}
+	return nil
+}
+
+// indexName expands date patterns in pattern using the event timestamp,
+// falling back to the current time if it can't be parsed.
+func indexName(pattern, ts string) string {
+	if !strings.Contains(pattern, "%") {
+		return pattern
+	}
+
+	at, err := (monitor.Event{Timestamp: ts}).Time()
+	if err != nil {
+		at = time.Now()
+	}
+	at = at.UTC()
+
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			b.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'Y':
+			fmt.Fprintf(&b, "%04d", at.Year())
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(at.Month()))
+		case 'd':
+			fmt.Fprintf(&b, "%02d", at.Day())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", at.Hour())
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String()
+}
+
+var _ monitor.Transport = (*Transport)(nil)
@@ -0,0 +1,154 @@
+package estransport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{}); err != ErrURLRequired {
+		t.Errorf("New() error = %v, want ErrURLRequired", err)
+	}
+
+	tr, err := New(Config{URL: "http://search:9200/"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tr.bulkURL != "http://search:9200/_bulk" {
+		t.Errorf("bulkURL = %v, want bulk path appended", tr.bulkURL)
+	}
+	if tr.cfg.Index != "events-%Y.%m.%d" {
+		t.Errorf("Index = %v, want default pattern", tr.cfg.Index)
+	}
+}
+
+func TestIndexName(t *testing.T) {
+	ts := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC).Format(time.RFC3339Nano)
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"events", "events"},
+		{"events-%Y.%m.%d", "events-2026.03.07"},
+		{"events-%Y.%m.%d-%H", "events-2026.03.07-09"},
+		{"pct-%%-%q", "pct-%-%q"},
+	}
+	for _, tt := range tests {
+		if got := indexName(tt.pattern, ts); got != tt.want {
+			t.Errorf("indexName(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	var (
+		gotPath, gotType, gotAuth string
+		lines                     []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotType = r.Header.Get("Content-Type")
+		gotAuth = r.Header.Get("Authorization")
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	tr, err := New(Config{URL: server.URL, Index: "app-%Y.%m", APIKey: "a2V5"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Format(time.RFC3339Nano)
+	batch := []monitor.Event{
+		{Timestamp: ts, Service: "api", Level: "info", Name: "a"},
+		{Timestamp: ts, Service: "api", Level: "warn", Name: "b"},
+	}
+	if err := tr.Send(context.Background(), batch); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if gotPath != bulkPath {
+		t.Errorf("path = %v, want %v", gotPath, bulkPath)
+	}
+	if gotType != "application/x-ndjson" {
+		t.Errorf("Content-Type = %v, want application/x-ndjson", gotType)
+	}
+	if gotAuth != "ApiKey a2V5" {
+		t.Errorf("Authorization = %v, want ApiKey a2V5", gotAuth)
+	}
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4 (action + source per event)", len(lines))
+	}
+	if lines[0] != `{"create":{"_index":"app-2026.01"}}` {
+		t.Errorf("action line = %v", lines[0])
+	}
+	var source map[string]any
+	if err := json.Unmarshal([]byte(lines[3]), &source); err != nil {
+		t.Fatalf("source line is not JSON: %v", err)
+	}
+	if source["name"] != "b" {
+		t.Errorf("source name = %v, want b", source["name"])
+	}
+}
+
+func TestSendItemErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name:    "retryable item",
+			body:    `{"errors":true,"items":[{"create":{"status":201}},{"create":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue full"}}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "permanent item",
+			body:    `{"errors":true,"items":[{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad field"}}}]}`,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			tr, _ := New(Config{URL: server.URL})
+			err := tr.Send(context.Background(), []monitor.Event{{Name: "a"}, {Name: "b"}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "queue full") {
+				t.Errorf("Send() error = %v, want first item reason", err)
+			}
+		})
+	}
+
+	t.Run("request rejected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		tr, _ := New(Config{URL: server.URL})
+		if err := tr.Send(context.Background(), []monitor.Event{{Name: "a"}}); err == nil {
+			t.Error("Send() should fail on a non-2xx bulk response")
+		}
+	})
+}
@@ -3,30 +3,113 @@ package monitor
 import (
 	"context"
 	"encoding/json"
+	"maps"
+	"reflect"
+	"strings"
 	"time"
 )
 
 // Event represents a single monitoring event.
 // At least one of job_id, request_id, or trace_id should be present.
 type Event struct {
+	// Timestamp is when the event was emitted, in Config.TimestampFormat: an
+	// RFC 3339 string, or Unix milliseconds or nanoseconds as decimal digits,
+	// which are written to JSON as a number. Use Time to parse it.
 	Timestamp string `json:"timestamp"`
+
 	Service   string `json:"service"`
 	Env       string `json:"env,omitempty"`
 	JobID     string `json:"job_id,omitempty"`
 	RequestID string `json:"request_id,omitempty"`
 	TraceID   string `json:"trace_id,omitempty"`
+	SpanID    string `json:"span_id,omitempty"`
 	UserID    string `json:"user_id,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
 	Name      string `json:"name"`
 	Level     string `json:"level"`
 	Data      any    `json:"data,omitempty"`
+
+	// ParentSpanID is the span that started this one. Set via StartChildSpan.
+	ParentSpanID string `json:"parent_span_id,omitempty"`
+
+	// ParentJobID is the job that spawned this event's job. Set via
+	// WithParentJobID.
+	ParentJobID string `json:"parent_job_id,omitempty"`
+
+	// RequestSeq is the event's position among events emitted within the same
+	// request (1, 2, ...). Set when the context carries a counter from
+	// Middleware or WithRequestSeq.
+	RequestSeq uint64 `json:"request_seq,omitempty"`
+
+	// Correlations holds named business correlation values set via WithCorrelation.
+	Correlations map[string]string `json:"correlations,omitempty"`
+
+	// Links point to related traces or requests in external systems. Set via WithLink.
+	Links []Link `json:"links,omitempty"`
+
+	// ExpiresAt is an optional RFC3339Nano time after which the event's state
+	// is no longer valid. Set via WithExpiry.
+	ExpiresAt string `json:"expires_at,omitempty"`
+
+	// Caller is the call site that emitted the event, as "dir/file.go:line".
+	// Set when Config.CaptureCaller is true.
+	Caller string `json:"caller,omitempty"`
+
+	// ProcessUptimeMS is the time since Init in milliseconds, measured on the
+	// monotonic clock. Set when Config.IncludeUptime is true.
+	ProcessUptimeMS int64 `json:"process_uptime_ms,omitempty"`
+
+	// AuditPrevHash and AuditHash chain audit-level events. See EmitAudit.
+	AuditPrevHash string `json:"audit_prev_hash,omitempty"`
+	AuditHash     string `json:"audit_hash,omitempty"`
+
+	// Fields are extra top-level JSON keys, set via Config.StaticFields and
+	// WithField. A key that collides with one of the fields above is written
+	// with a "field_" prefix instead (e.g., "field_service").
+	Fields map[string]any `json:"-"`
+
+	// priority orders shipping under backpressure. Derived from Level unless
+	// set via WithPriority, as recorded by explicitPriority; never serialized.
+	priority         int
+	explicitPriority bool
+
+	// queuedAt is when the event first entered the shipper's buffer, kept
+	// across requeues so Config.MaxEventAge counts from the original enqueue.
+	queuedAt time.Time
+
+	// internal marks the SDK's own monitor.* events, which are dropped
+	// quietly rather than reported when they can't be shipped, so a failing
+	// pipeline doesn't feed itself.
+	internal bool
+
+	// line caches the event's Config.Encoder encoding while it waits in the
+	// shipper's buffer when Config.MaxBatchBytes is set, so batches can be
+	// sized without marshaling twice. Nil if not yet encoded.
+	line []byte
 }
 
-// newEvent creates a new Event with required fields populated.
-// IDs are taken from context or global config but not auto-generated.
-func newEvent(ctx context.Context, name string, data any, level string) Event {
-	cfg := globalConfig.Load()
+// Link identifies a trace or request in an external system (e.g., a Stripe
+// request ID or an upstream vendor's trace ID).
+type Link struct {
+	System string `json:"system"`
+	ID     string `json:"id"`
+}
 
-	// Get IDs from context, fall back to global job ID only
+// LinkID returns the ID of the first link to system, or empty string if the
+// event has none.
+func (e Event) LinkID(system string) string {
+	for _, l := range e.Links {
+		if l.System == system {
+			return l.ID
+		}
+	}
+	return ""
+}
+
+// newEvent creates a new Event with required fields populated.
+// IDs are taken from context or cfg but not auto-generated. cfg may be nil.
+func newEvent(ctx context.Context, cfg *Config, name string, data any, level string) Event {
+	// Get IDs from context, fall back to the config's job ID only
 	jobID := JobID(ctx)
 	if jobID == "" && cfg != nil {
 		jobID = cfg.JobID
@@ -34,7 +117,9 @@ func newEvent(ctx context.Context, name string, data any, level string) Event {
 
 	requestID := RequestID(ctx)
 	traceID := TraceID(ctx)
+	spanID := SpanID(ctx)
 	userID := UserID(ctx)
+	sessionID := SessionID(ctx)
 
 	service := ""
 	env := ""
@@ -44,27 +129,192 @@ func newEvent(ctx context.Context, name string, data any, level string) Event {
 	}
 
 	if level == "" {
-		level = "info"
+		level = defaultLevelFor(cfg, name)
+	}
+
+	now := time.Now()
+	var uptimeMS int64
+	if cfg != nil && cfg.IncludeUptime {
+		uptimeMS = now.Sub(cfg.startedAt).Milliseconds()
 	}
 
-	return Event{
-		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+	event := Event{
+		Timestamp: formatTimestamp(cfg, now),
 		Service:   service,
 		Env:       env,
 		JobID:     jobID,
 		RequestID: requestID,
 		TraceID:   traceID,
+		SpanID:    spanID,
 		UserID:    userID,
+		SessionID: sessionID,
 		Name:      name,
 		Level:     level,
 		Data:      data,
+
+		ParentSpanID: ParentSpanID(ctx),
+		ParentJobID:  ParentJobID(ctx),
+		RequestSeq:   nextRequestSeq(ctx),
+		Correlations: Correlations(ctx),
+
+		ProcessUptimeMS: uptimeMS,
+
+		priority: levelPriority(level),
+	}
+	if fields, ok := ctx.Value(ctxKeyFields).(map[string]any); ok && len(fields) > 0 {
+		addContextFields(&event, fields)
+	}
+	if cfg != nil && len(cfg.StaticFields) > 0 {
+		event.Fields = maps.Clone(cfg.StaticFields)
+	}
+	if cfg != nil && cfg.IncludeDeadline {
+		annotateDeadline(ctx, &event)
 	}
+	return event
 }
 
-// MarshalJSON implements json.Marshaler for Event.
+// addDataFields sets fields in the event's data. Non-map data is wrapped
+// under "_data", as for source location; the caller's map is copied, not
+// modified.
+func addDataFields(event *Event, fields map[string]any) {
+	data := make(map[string]any)
+	switch d := event.Data.(type) {
+	case map[string]any:
+		maps.Copy(data, d)
+	case nil:
+	default:
+		data["_data"] = d
+	}
+	maps.Copy(data, fields)
+	event.Data = data
+}
+
+// addContextFields merges fields set with WithFields into the event's data.
+// Keys already in the data win; non-map data is wrapped under "_data". The
+// caller's map is copied, not modified.
+func addContextFields(event *Event, fields map[string]any) {
+	data := maps.Clone(fields)
+	switch d := event.Data.(type) {
+	case map[string]any:
+		maps.Copy(data, d)
+	case nil:
+	default:
+		data["_data"] = d
+	}
+	event.Data = data
+}
+
+// annotateDeadline records how long ctx had left under
+// data._deadline_remaining_ms and, once ctx is done, its error under
+// data._ctx_err. It does nothing for a context without either.
+func annotateDeadline(ctx context.Context, event *Event) {
+	fields := make(map[string]any, 2)
+	if deadline, ok := ctx.Deadline(); ok {
+		fields["_deadline_remaining_ms"] = max(time.Until(deadline).Milliseconds(), 0)
+	}
+	if err := ctx.Err(); err != nil {
+		fields["_ctx_err"] = err.Error()
+	}
+	if len(fields) > 0 {
+		addDataFields(event, fields)
+	}
+}
+
+// reservedFieldPrefix is prepended to Fields keys that collide with the
+// event's own JSON keys.
+const reservedFieldPrefix = "field_"
+
+// reservedFields holds the JSON keys of Event's struct fields.
+var reservedFields = eventJSONKeys()
+
+// eventJSONKeys returns the JSON keys of Event's struct fields.
+func eventJSONKeys() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeFor[Event]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// MarshalJSON implements json.Marshaler for Event. A numeric Timestamp is
+// written as a JSON number, and Fields are merged in as top-level keys after
+// the struct fields.
 func (e Event) MarshalJSON() ([]byte, error) {
 	type EventAlias Event
-	return json.Marshal(EventAlias(e))
+	var b []byte
+	var err error
+	if numericTimestamp(e.Timestamp) {
+		// The outer field shadows EventAlias.Timestamp
+		b, err = json.Marshal(struct {
+			Timestamp json.Number `json:"timestamp"`
+			EventAlias
+		}{json.Number(e.Timestamp), EventAlias(e)})
+	} else {
+		b, err = json.Marshal(EventAlias(e))
+	}
+	if err != nil || len(e.Fields) == 0 {
+		return b, err
+	}
+
+	fields := make(map[string]any, len(e.Fields))
+	for k, v := range e.Fields {
+		if reservedFields[k] {
+			k = reservedFieldPrefix + k
+		}
+		fields[k] = v
+	}
+	extra, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	// Splice {"a":1} and {"b":2} into {"a":1,"b":2}
+	out := make([]byte, 0, len(b)+len(extra))
+	out = append(out, b[:len(b)-1]...)
+	out = append(out, ',')
+	return append(out, extra[1:]...), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler for Event, accepting a string
+// or numeric timestamp and collecting top-level keys that aren't struct
+// fields into Fields, so events read back (e.g., from SpillDir) keep them.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	type EventAlias Event
+	aux := struct {
+		Timestamp json.RawMessage `json:"timestamp"`
+		*EventAlias
+	}{EventAlias: (*EventAlias)(e)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	if ts := aux.Timestamp; len(ts) > 0 && ts[0] == '"' {
+		if err := json.Unmarshal(ts, &e.Timestamp); err != nil {
+			return err
+		}
+	} else if numericTimestamp(string(ts)) {
+		e.Timestamp = string(ts)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	for k, v := range raw {
+		if reservedFields[k] {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal(v, &value); err != nil {
+			return err
+		}
+		if e.Fields == nil {
+			e.Fields = make(map[string]any)
+		}
+		e.Fields[k] = value
+	}
+	return nil
 }
 
 // ToJSON returns the event as a JSON byte slice.
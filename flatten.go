@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// flattenData returns a copy of data with nested map[string]any values folded
+// into dotted keys ({"a":{"b":1}} -> {"a.b":1}). When indexArrays is set,
+// []any values are folded too ({"a":[1,2]} -> {"a.0":1,"a.1":2}); otherwise
+// they are kept as-is. Data that isn't a map[string]any is returned unchanged.
+// The caller's map is never modified.
+//
+// Keys are visited in sorted order and a later key overwrites an earlier one
+// that flattens to the same name, so collisions resolve the same way every
+// time: {"a":{"b":1},"a.b":2} -> {"a.b":2}.
+func flattenData(data any, indexArrays bool) any {
+	m, ok := data.(map[string]any)
+	if !ok {
+		return data
+	}
+	out := make(map[string]any, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		flattenInto(out, k, m[k], indexArrays)
+	}
+	return out
+}
+
+func flattenInto(out map[string]any, key string, value any, indexArrays bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			out[key] = v
+			return
+		}
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			flattenInto(out, key+"."+k, v[k], indexArrays)
+		}
+	case []any:
+		if !indexArrays || len(v) == 0 {
+			out[key] = v
+			return
+		}
+		for i, nested := range v {
+			flattenInto(out, key+"."+strconv.Itoa(i), nested, indexArrays)
+		}
+	default:
+		out[key] = value
+	}
+}
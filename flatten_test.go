@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFlattenData(t *testing.T) {
+	input := map[string]any{
+		"user": map[string]any{
+			"id":      42,
+			"profile": map[string]any{"plan": "pro"},
+		},
+		"tags":  []any{"a", map[string]any{"k": "v"}},
+		"empty": map[string]any{},
+		"count": 3,
+	}
+
+	tests := []struct {
+		name        string
+		indexArrays bool
+		want        map[string]any
+	}{
+		{
+			name: "arrays kept",
+			want: map[string]any{
+				"user.id":           42,
+				"user.profile.plan": "pro",
+				"tags":              []any{"a", map[string]any{"k": "v"}},
+				"empty":             map[string]any{},
+				"count":             3,
+			},
+		},
+		{
+			name:        "arrays indexed",
+			indexArrays: true,
+			want: map[string]any{
+				"user.id":           42,
+				"user.profile.plan": "pro",
+				"tags.0":            "a",
+				"tags.1.k":          "v",
+				"empty":             map[string]any{},
+				"count":             3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := flattenData(input, tt.indexArrays)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flattenData() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("input not modified", func(t *testing.T) {
+		if _, ok := input["user"].(map[string]any); !ok {
+			t.Error("flattenData() modified the caller's map")
+		}
+	})
+
+	t.Run("collisions are deterministic", func(t *testing.T) {
+		data := map[string]any{
+			"a":   map[string]any{"b": 1, "c": map[string]any{"d": 1}, "c.d": 2},
+			"a.b": 2,
+		}
+		want := map[string]any{"a.b": 2, "a.c.d": 2}
+		for i := 0; i < 50; i++ {
+			if got := flattenData(data, false); !reflect.DeepEqual(got, want) {
+				t.Fatalf("flattenData() = %v, want %v", got, want)
+			}
+		}
+	})
+
+	t.Run("non-map data unchanged", func(t *testing.T) {
+		if got := flattenData("plain", true); got != "plain" {
+			t.Errorf("flattenData() = %v, want plain", got)
+		}
+	})
+}
+
+func TestFlattenDataConfig(t *testing.T) {
+	rt := &recordingTransport{}
+	captureSource := false
+	if err := Init(Config{
+		Service:       "test-flatten",
+		DisableStdout: true,
+		Transport:     rt,
+		FlattenData:   true,
+		CaptureSource: &captureSource,
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	Emit(context.Background(), "test.flatten", map[string]any{"http": map[string]any{"status": 200}})
+	Flush()
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if len(rt.batches) != 1 || len(rt.batches[0]) != 1 {
+		t.Fatalf("batches = %v, want one event", rt.batches)
+	}
+	data, _ := rt.batches[0][0].Data.(map[string]any)
+	if data["http.status"] != 200 {
+		t.Errorf("data = %v, want http.status = 200", data)
+	}
+}
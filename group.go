@@ -0,0 +1,32 @@
+package monitor
+
+import "context"
+
+// Group runs functions in goroutines. *errgroup.Group from
+// golang.org/x/sync/errgroup satisfies it, so Go works with errgroup without
+// this package depending on it.
+type Group interface {
+	Go(f func() error)
+}
+
+// Go runs fn on g with a context that carries ctx's monitor IDs, so events
+// emitted in the goroutine correlate with the caller. Each goroutine gets its
+// own span: the span ID in ctx becomes its parent span ID.
+//
+// ctx's cancellation is kept. Pass the context from errgroup.WithContext so a
+// failing goroutine cancels its siblings; for work that must outlive the
+// request, pass context.WithoutCancel(ctx), which keeps the IDs.
+//
+//	g, gctx := errgroup.WithContext(r.Context())
+//	for _, id := range ids {
+//	    monitor.Go(gctx, g, func(ctx context.Context) error {
+//	        return fetch(ctx, id)
+//	    })
+//	}
+//	err := g.Wait()
+func Go(ctx context.Context, g Group, fn func(context.Context) error) {
+	ctx = StartChildSpan(ctx)
+	g.Go(func() error {
+		return fn(ctx)
+	})
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// waitGroup is a minimal Group, standing in for errgroup.Group.
+type waitGroup struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+func (g *waitGroup) Go(f func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := f(); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func (g *waitGroup) Wait() error {
+	g.wg.Wait()
+	return errors.Join(g.errs...)
+}
+
+func TestGo(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-group")
+	ctx = WithTraceID(ctx, "trace-group")
+	ctx = WithSpanID(ctx, "aaaaaaaaaaaaaaaa")
+
+	g := &waitGroup{}
+	var mu sync.Mutex
+	spans := map[string]bool{}
+	errFail := errors.New("fail")
+
+	for i := 0; i < 3; i++ {
+		Go(ctx, g, func(ctx context.Context) error {
+			if RequestID(ctx) != "req-group" || TraceID(ctx) != "trace-group" {
+				t.Errorf("goroutine IDs = %q/%q, want req-group/trace-group", RequestID(ctx), TraceID(ctx))
+			}
+			if ParentSpanID(ctx) != "aaaaaaaaaaaaaaaa" {
+				t.Errorf("ParentSpanID = %q, want caller's span", ParentSpanID(ctx))
+			}
+			mu.Lock()
+			spans[SpanID(ctx)] = true
+			mu.Unlock()
+			return errFail
+		})
+	}
+
+	if err := g.Wait(); !errors.Is(err, errFail) {
+		t.Errorf("Wait() error = %v, want %v", err, errFail)
+	}
+	if len(spans) != 3 {
+		t.Errorf("got %d distinct span IDs, want 3 (one per goroutine)", len(spans))
+	}
+}
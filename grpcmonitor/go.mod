@@ -0,0 +1,18 @@
+module github.com/aidenappl/go-monitor/grpcmonitor
+
+go 1.25.5
+
+require (
+	github.com/aidenappl/go-monitor v0.0.0-20260206144105-41b30528e24e
+	google.golang.org/grpc v1.83.1
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	golang.org/x/net v0.55.0 // indirect
+	golang.org/x/sys v0.45.0 // indirect
+	golang.org/x/text v0.37.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260526163538-3dc84a4a5aaa // indirect
+)
+
+replace github.com/aidenappl/go-monitor => ../
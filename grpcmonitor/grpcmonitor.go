@@ -0,0 +1,97 @@
+// Package grpcmonitor provides gRPC server interceptors that do for gRPC what
+// monitor.Middleware does for HTTP: every call gets a request_id, trace_id,
+// and span_id in its context, read from incoming metadata when the client
+// sent them and generated otherwise.
+//
+// Usage:
+//
+//	srv := grpc.NewServer(
+//	    grpc.UnaryInterceptor(grpcmonitor.UnaryServerInterceptor()),
+//	    grpc.StreamInterceptor(grpcmonitor.StreamServerInterceptor()),
+//	)
+//
+// The request and trace IDs are also returned to the client as trailer
+// metadata (x-request-id, x-trace-id), unless Config.EchoResponseHeaders is
+// false.
+//
+// This package lives in its own module so the gRPC dependency stays out of
+// the core go-monitor module.
+package grpcmonitor
+
+import (
+	"context"
+	"strings"
+
+	monitor "github.com/aidenappl/go-monitor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// MetadataRequestID is the gRPC metadata key for the request ID.
+	MetadataRequestID = "x-request-id"
+
+	// MetadataTraceID is the gRPC metadata key for the trace ID.
+	MetadataTraceID = "x-trace-id"
+)
+
+// UnaryServerInterceptor returns an interceptor that propagates IDs into the
+// context of each unary call and sets them as trailer metadata.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, trailer := propagate(ctx)
+		if len(trailer) > 0 {
+			_ = grpc.SetTrailer(ctx, trailer)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that propagates IDs into the
+// context of each streaming call and sets them as trailer metadata.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, trailer := propagate(ss.Context())
+		if len(trailer) > 0 {
+			ss.SetTrailer(trailer)
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// propagate runs monitor.PropagateIDs over the incoming metadata and returns
+// the IDs to echo as trailer metadata.
+func propagate(ctx context.Context) (context.Context, metadata.MD) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	trailer := metadata.MD{}
+	ctx = monitor.PropagateIDs(ctx,
+		func(key string) string { return lookup(md, key) },
+		func(key, value string) { trailer.Set(key, value) },
+	)
+	return ctx, trailer
+}
+
+// lookup returns the first value for key, ignoring case. gRPC lowercases
+// incoming keys, but metadata built by hand (e.g., in tests or in-process
+// proxies) may not be.
+func lookup(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	for k, v := range md {
+		if strings.EqualFold(k, key) && len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// serverStream overrides Context so handlers see the propagated IDs.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
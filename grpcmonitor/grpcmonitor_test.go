@@ -0,0 +1,208 @@
+package grpcmonitor
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	monitor "github.com/aidenappl/go-monitor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ids are the IDs a handler found in its context.
+type ids struct {
+	requestID, traceID, spanID, jobID string
+}
+
+func idsFrom(ctx context.Context) ids {
+	return ids{monitor.RequestID(ctx), monitor.TraceID(ctx), monitor.SpanID(ctx), monitor.JobID(ctx)}
+}
+
+// echoServer records the IDs seen by its unary and streaming handlers.
+type echoServer struct {
+	seen chan ids
+}
+
+var echoService = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Unary",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				srv.(*echoServer).seen <- idsFrom(ctx)
+				return req, nil
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Unary"}, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Stream",
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			srv.(*echoServer).seen <- idsFrom(stream.Context())
+			for {
+				in := new(wrapperspb.StringValue)
+				if err := stream.RecvMsg(in); err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+				if err := stream.SendMsg(in); err != nil {
+					return err
+				}
+			}
+		},
+	}},
+}
+
+func startServer(t *testing.T) (*echoServer, *grpc.ClientConn) {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	es := &echoServer{seen: make(chan ids, 1)}
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor()),
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+	)
+	srv.RegisterService(&echoService, es)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return es, conn
+}
+
+func initMonitor(t *testing.T, cfg monitor.Config) {
+	t.Helper()
+	cfg.Service = "test-grpcmonitor"
+	cfg.DisableStdout = true
+	cfg.JobID = "job-1"
+	if err := monitor.Init(cfg); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(monitor.Shutdown)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	initMonitor(t, monitor.Config{})
+	es, conn := startServer(t)
+
+	t.Run("uses incoming IDs", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(),
+			"X-Request-Id", "req-123", "x-trace-id", "trace-456")
+		var trailer metadata.MD
+		out := new(wrapperspb.StringValue)
+		if err := conn.Invoke(ctx, "/test.Echo/Unary", wrapperspb.String("hi"), out, grpc.Trailer(&trailer)); err != nil {
+			t.Fatalf("Invoke() error = %v", err)
+		}
+
+		got := <-es.seen
+		if got.requestID != "req-123" || got.traceID != "trace-456" {
+			t.Errorf("handler IDs = %+v, want request req-123 and trace trace-456", got)
+		}
+		if len(got.spanID) != 16 || got.jobID != "job-1" {
+			t.Errorf("handler IDs = %+v, want a 16-char span ID and job job-1", got)
+		}
+		if v := trailer.Get(MetadataRequestID); len(v) != 1 || v[0] != "req-123" {
+			t.Errorf("trailer %s = %v, want [req-123]", MetadataRequestID, v)
+		}
+		if v := trailer.Get(MetadataTraceID); len(v) != 1 || v[0] != "trace-456" {
+			t.Errorf("trailer %s = %v, want [trace-456]", MetadataTraceID, v)
+		}
+	})
+
+	t.Run("generates missing IDs", func(t *testing.T) {
+		var trailer metadata.MD
+		out := new(wrapperspb.StringValue)
+		if err := conn.Invoke(context.Background(), "/test.Echo/Unary", wrapperspb.String("hi"), out, grpc.Trailer(&trailer)); err != nil {
+			t.Fatalf("Invoke() error = %v", err)
+		}
+
+		got := <-es.seen
+		if got.requestID == "" || got.traceID == "" {
+			t.Errorf("handler IDs = %+v, want generated request and trace IDs", got)
+		}
+		if v := trailer.Get(MetadataRequestID); len(v) != 1 || v[0] != got.requestID {
+			t.Errorf("trailer %s = %v, want [%s]", MetadataRequestID, v, got.requestID)
+		}
+	})
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	initMonitor(t, monitor.Config{})
+	es, conn := startServer(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-stream")
+	stream, err := conn.NewStream(ctx, &echoService.Streams[0], "/test.Echo/Stream")
+	if err != nil {
+		t.Fatalf("NewStream() error = %v", err)
+	}
+	if err := stream.SendMsg(wrapperspb.String("hi")); err != nil {
+		t.Fatalf("SendMsg() error = %v", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend() error = %v", err)
+	}
+	for {
+		if err := stream.RecvMsg(new(wrapperspb.StringValue)); err != nil {
+			break
+		}
+	}
+
+	got := <-es.seen
+	if got.requestID != "req-stream" || got.traceID == "" || got.spanID == "" {
+		t.Errorf("handler IDs = %+v, want request req-stream with generated trace and span IDs", got)
+	}
+	if v := stream.Trailer().Get(MetadataTraceID); len(v) != 1 || v[0] != got.traceID {
+		t.Errorf("trailer %s = %v, want [%s]", MetadataTraceID, v, got.traceID)
+	}
+}
+
+func TestEchoResponseHeadersDisabled(t *testing.T) {
+	echo := false
+	initMonitor(t, monitor.Config{EchoResponseHeaders: &echo})
+	es, conn := startServer(t)
+
+	var trailer metadata.MD
+	out := new(wrapperspb.StringValue)
+	if err := conn.Invoke(context.Background(), "/test.Echo/Unary", wrapperspb.String("hi"), out, grpc.Trailer(&trailer)); err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if got := <-es.seen; got.requestID == "" {
+		t.Error("handler has no request ID")
+	}
+	if v := trailer.Get(MetadataRequestID); len(v) != 0 {
+		t.Errorf("trailer %s = %v, want none with EchoResponseHeaders false", MetadataRequestID, v)
+	}
+}
+
+func TestLookupIgnoresCase(t *testing.T) {
+	md := metadata.MD{"X-Trace-Id": []string{"mixed"}}
+	if got := lookup(md, monitor.HeaderTraceID); got != "mixed" {
+		t.Errorf("lookup() = %q, want mixed", got)
+	}
+	if got := lookup(md, monitor.HeaderRequestID); got != "" {
+		t.Errorf("lookup() = %q, want empty", got)
+	}
+}
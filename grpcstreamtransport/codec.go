@@ -0,0 +1,30 @@
+package grpcstreamtransport
+
+import "fmt"
+
+// rawCodec passes pre-encoded JSON frames through unchanged. It is named
+// "json" so the request content-type is application/grpc+json.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	switch b := v.(type) {
+	case []byte:
+		return b, nil
+	case *[]byte:
+		return *b, nil
+	}
+	return nil, fmt.Errorf("grpcstreamtransport: cannot marshal %T", v)
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("grpcstreamtransport: cannot unmarshal into %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "json"
+}
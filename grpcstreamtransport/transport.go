@@ -0,0 +1,321 @@
+// Package grpcstreamtransport ships go-monitor batches over a long-lived gRPC
+// stream instead of repeated HTTP POSTs.
+//
+// Each event is sent as one stream message containing its JSON encoding, using
+// a "json" content-subtype so ingest servers can decode frames without a
+// generated protobuf schema. The stream is opened lazily and reopened after
+// any stream error.
+//
+// Usage:
+//
+//	t, err := grpcstreamtransport.New(grpcstreamtransport.Config{
+//	    Target: "ingest.example.com:443",
+//	    Method: "/ingest.v1.Ingest/StreamEvents",
+//	    DialOptions: []grpc.DialOption{
+//	        grpc.WithTransportCredentials(credentials.NewTLS(nil)),
+//	    },
+//	})
+//	monitor.Init(monitor.Config{Service: "api", Transport: t})
+//	monitor.RegisterShutdownHook(func(ctx context.Context) error { return t.Close() })
+//
+// This package lives in its own module so the gRPC dependency stays out of
+// the core go-monitor module.
+package grpcstreamtransport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrMethodRequired is returned when Config.Method is empty.
+var ErrMethodRequired = errors.New("grpcstreamtransport: Config.Method is required")
+
+// ErrClosed is returned by Send after Close has been called.
+var ErrClosed = errors.New("grpcstreamtransport: transport closed")
+
+// Config configures the gRPC stream transport.
+type Config struct {
+	// Target is the gRPC dial target (e.g., "ingest.example.com:443").
+	// Ignored when Conn is set.
+	Target string
+
+	// Method is the full streaming method name (e.g., "/ingest.v1.Ingest/StreamEvents"). Required.
+	Method string
+
+	// DialOptions are passed to grpc.NewClient when dialing Target.
+	DialOptions []grpc.DialOption
+
+	// Conn is an existing client connection to use instead of dialing Target.
+	// The transport does not close a caller-provided Conn.
+	Conn *grpc.ClientConn
+
+	// Metadata is attached as outgoing metadata when each stream is opened
+	// (e.g., {"x-api-key": "..."}). Optional.
+	Metadata map[string]string
+}
+
+// Stats reports the health of the underlying stream.
+type Stats struct {
+	// Connected is true while a stream is open.
+	Connected bool
+
+	// Reconnects is the number of times a stream was reopened after an error.
+	Reconnects uint64
+
+	// MessagesSent is the total number of event messages sent.
+	MessagesSent uint64
+
+	// LastError is the most recent stream error, or nil.
+	LastError error
+
+	// LastErrorTime is when LastError occurred.
+	LastErrorTime time.Time
+}
+
+// Transport implements monitor.Transport over a gRPC client stream.
+type Transport struct {
+	cfg     Config
+	conn    *grpc.ClientConn
+	ownConn bool
+
+	// sendSem is held by the one Send using the stream, since a gRPC stream
+	// allows a single sender at a time. Waiting for it honors each Send's
+	// context, so a stalled stream can't block later Sends indefinitely.
+	sendSem chan struct{}
+
+	// mu guards the fields below. It is never held across a blocking call.
+	mu     sync.Mutex
+	stream grpc.ClientStream
+	cancel context.CancelFunc
+	opened bool
+	closed bool
+
+	reconnects   atomic.Uint64
+	messagesSent atomic.Uint64
+	lastErr      atomic.Pointer[streamError]
+}
+
+type streamError struct {
+	err error
+	at  time.Time
+}
+
+// New creates a Transport. The stream itself is opened on the first Send.
+func New(cfg Config) (*Transport, error) {
+	if cfg.Method == "" {
+		return nil, ErrMethodRequired
+	}
+
+	t := &Transport{cfg: cfg, conn: cfg.Conn, sendSem: make(chan struct{}, 1)}
+	if t.conn == nil {
+		conn, err := grpc.NewClient(cfg.Target, cfg.DialOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("grpcstreamtransport: dial %s: %w", cfg.Target, err)
+		}
+		t.conn = conn
+		t.ownConn = true
+	}
+	return t, nil
+}
+
+var streamDesc = &grpc.StreamDesc{
+	StreamName:    "StreamEvents",
+	ClientStreams: true,
+	ServerStreams: true,
+}
+
+// Send implements monitor.Transport. Each event is sent as its own stream
+// message. If the stream breaks mid-batch it is reopened once and the events
+// the broken stream didn't accept are resent; a second failure is returned
+// so the shipper can retry with backoff. Send gives up when ctx ends, tearing
+// down a stream stuck mid-send.
+func (t *Transport) Send(ctx context.Context, batch []monitor.Event) error {
+	frames := make([][]byte, 0, len(batch))
+	for _, event := range batch {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("grpcstreamtransport: marshal event: %w", err)
+		}
+		frames = append(frames, b)
+	}
+
+	select {
+	case t.sendSem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-t.sendSem }()
+
+	t.mu.Lock()
+	closed := t.closed
+	t.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	sent, err := t.sendFrames(ctx, frames)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	// Stream broke mid-batch: reopen and resend the rest once
+	if _, err := t.sendFrames(ctx, frames[sent:]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendFrames writes frames to the current stream, opening one if needed, and
+// returns how many the stream accepted. A stream that fails, or is abandoned
+// because ctx ended, is torn down. Must be called with sendSem held.
+func (t *Transport) sendFrames(ctx context.Context, frames [][]byte) (int, error) {
+	t.mu.Lock()
+	stream, cancel := t.stream, t.cancel
+	t.mu.Unlock()
+	if stream == nil {
+		var err error
+		if stream, cancel, err = t.openStream(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	// SendMsg takes no context, so ctx ending cancels the whole stream
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+	for i, frame := range frames {
+		if err := stream.SendMsg(frame); err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			err = fmt.Errorf("grpcstreamtransport: send: %w", err)
+			t.resetStream(stream, err)
+			return i, err
+		}
+		t.messagesSent.Add(1)
+	}
+	return len(frames), nil
+}
+
+// openStream opens a new stream on the connection and makes it current,
+// giving up when ctx ends. The stream itself outlives ctx. Must be called
+// with sendSem held.
+func (t *Transport) openStream(ctx context.Context) (grpc.ClientStream, context.CancelFunc, error) {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	if len(t.cfg.Metadata) > 0 {
+		streamCtx = metadata.NewOutgoingContext(streamCtx, metadata.New(t.cfg.Metadata))
+	}
+
+	stop := context.AfterFunc(ctx, cancel)
+	stream, err := t.conn.NewStream(streamCtx, streamDesc, t.cfg.Method, grpc.ForceCodec(rawCodec{}))
+	stop()
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		cancel()
+		err = fmt.Errorf("grpcstreamtransport: open stream: %w", err)
+		t.lastErr.Store(&streamError{err: err, at: time.Now()})
+		return nil, nil, err
+	}
+
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		cancel()
+		return nil, nil, ErrClosed
+	}
+	if t.opened {
+		t.reconnects.Add(1)
+	}
+	t.opened = true
+	t.stream = stream
+	t.cancel = cancel
+	t.mu.Unlock()
+
+	// Drain server messages (acks) so flow control never stalls, and notice
+	// a server-side close before the next Send.
+	go t.recvLoop(stream)
+	return stream, cancel, nil
+}
+
+// recvLoop discards server messages until the stream ends, then marks it broken.
+func (t *Transport) recvLoop(stream grpc.ClientStream) {
+	for {
+		var ack []byte
+		if err := stream.RecvMsg(&ack); err != nil {
+			t.resetStream(stream, err)
+			return
+		}
+	}
+}
+
+// resetStream records err and tears down stream if it is still current.
+func (t *Transport) resetStream(stream grpc.ClientStream, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stream != stream {
+		return
+	}
+	t.lastErr.Store(&streamError{err: err, at: time.Now()})
+	t.cancel()
+	t.stream = nil
+	t.cancel = nil
+}
+
+// Stats returns a snapshot of the stream's health.
+func (t *Transport) Stats() Stats {
+	t.mu.Lock()
+	connected := t.stream != nil
+	t.mu.Unlock()
+
+	st := Stats{
+		Connected:    connected,
+		Reconnects:   t.reconnects.Load(),
+		MessagesSent: t.messagesSent.Load(),
+	}
+	if le := t.lastErr.Load(); le != nil {
+		st.LastError = le.err
+		st.LastErrorTime = le.at
+	}
+	return st
+}
+
+// Close half-closes the stream and releases the connection if the transport
+// dialed it. A Send in progress is aborted.
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	stream, cancel := t.stream, t.cancel
+	t.stream, t.cancel = nil, nil
+	t.mu.Unlock()
+
+	if stream != nil {
+		// CloseSend must not race a SendMsg, so only half-close an idle stream
+		select {
+		case t.sendSem <- struct{}{}:
+			_ = stream.CloseSend()
+			<-t.sendSem
+		default:
+		}
+		cancel()
+	}
+	if t.ownConn {
+		return t.conn.Close()
+	}
+	return nil
+}
+
+var _ monitor.Transport = (*Transport)(nil)
@@ -0,0 +1,244 @@
+package grpcstreamtransport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testMethod = "/ingest.v1.Ingest/StreamEvents"
+
+// ingestServer is an in-process gRPC server that records received frames.
+type ingestServer struct {
+	mu       sync.Mutex
+	frames   [][]byte
+	apiKeys  []string
+	failNext bool
+
+	// stall, when set, keeps the handler from reading until it is closed,
+	// so flow control eventually blocks the client's sends
+	stall chan struct{}
+}
+
+func (s *ingestServer) handle(srv any, stream grpc.ServerStream) error {
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		s.mu.Lock()
+		s.apiKeys = append(s.apiKeys, md.Get("x-api-key")...)
+		s.mu.Unlock()
+	}
+	if s.stall != nil {
+		<-s.stall
+	}
+	for {
+		var frame []byte
+		if err := stream.RecvMsg(&frame); err != nil {
+			return nil
+		}
+		s.mu.Lock()
+		s.frames = append(s.frames, frame)
+		fail := s.failNext
+		s.failNext = false
+		s.mu.Unlock()
+		if fail {
+			return context.Canceled
+		}
+	}
+}
+
+func (s *ingestServer) frameCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.frames)
+}
+
+func startServer(t *testing.T) (*ingestServer, *grpc.ClientConn) {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	is := &ingestServer{}
+	srv := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(is.handle),
+	)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return is, conn
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{Target: "localhost:0"}); err != ErrMethodRequired {
+		t.Errorf("New() error = %v, want ErrMethodRequired", err)
+	}
+}
+
+func TestSend(t *testing.T) {
+	is, conn := startServer(t)
+
+	tr, err := New(Config{
+		Conn:     conn,
+		Method:   testMethod,
+		Metadata: map[string]string{"x-api-key": "secret"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer tr.Close()
+
+	batch := []monitor.Event{
+		{Name: "test.one", Service: "svc", Level: "info"},
+		{Name: "test.two", Service: "svc", Level: "warn"},
+	}
+	if err := tr.Send(context.Background(), batch); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	waitFor(t, func() bool { return is.frameCount() == 2 })
+
+	is.mu.Lock()
+	var decoded map[string]any
+	if err := json.Unmarshal(is.frames[1], &decoded); err != nil {
+		t.Fatalf("frame is not JSON: %v", err)
+	}
+	keys := is.apiKeys
+	is.mu.Unlock()
+
+	if decoded["name"] != "test.two" {
+		t.Errorf("frame name = %v, want test.two", decoded["name"])
+	}
+	if len(keys) != 1 || keys[0] != "secret" {
+		t.Errorf("x-api-key metadata = %v, want [secret]", keys)
+	}
+
+	st := tr.Stats()
+	if !st.Connected {
+		t.Error("Stats().Connected = false, want true")
+	}
+	if st.MessagesSent != 2 {
+		t.Errorf("Stats().MessagesSent = %d, want 2", st.MessagesSent)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	is, conn := startServer(t)
+
+	tr, err := New(Config{Conn: conn, Method: testMethod})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer tr.Close()
+
+	// Server ends the stream after the first frame
+	is.mu.Lock()
+	is.failNext = true
+	is.mu.Unlock()
+	if err := tr.Send(context.Background(), []monitor.Event{{Name: "test.first"}}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	waitFor(t, func() bool { return !tr.Stats().Connected })
+
+	if err := tr.Send(context.Background(), []monitor.Event{{Name: "test.second"}}); err != nil {
+		t.Fatalf("Send() after stream error = %v", err)
+	}
+	waitFor(t, func() bool { return is.frameCount() == 2 })
+
+	st := tr.Stats()
+	if st.Reconnects != 1 {
+		t.Errorf("Stats().Reconnects = %d, want 1", st.Reconnects)
+	}
+	if st.LastError == nil {
+		t.Error("Stats().LastError should record the stream error")
+	}
+}
+
+func TestSendStalledStream(t *testing.T) {
+	is, conn := startServer(t)
+	is.stall = make(chan struct{})
+	defer close(is.stall)
+
+	tr, err := New(Config{Conn: conn, Method: testMethod})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer tr.Close()
+
+	// Enough data to exhaust the stream's flow-control window
+	big := strings.Repeat("x", 64<<10)
+	batch := make([]monitor.Event, 64)
+	for i := range batch {
+		batch[i] = monitor.Event{Name: "test.big", Data: big}
+	}
+
+	send := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := tr.Send(ctx, batch)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Send() took %v, want it bounded by its context", elapsed)
+		}
+		return err
+	}
+
+	// A second concurrent Send waits for the stalled one only as long as its
+	// own context allows
+	errs := make(chan error, 2)
+	go func() { errs <- send() }()
+	go func() { errs <- send() }()
+	for range 2 {
+		if err := <-errs; !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Send() error = %v, want context.DeadlineExceeded", err)
+		}
+	}
+	if tr.Stats().Connected {
+		t.Error("Stats().Connected = true, want the stalled stream torn down")
+	}
+}
+
+func TestSendAfterClose(t *testing.T) {
+	_, conn := startServer(t)
+
+	tr, err := New(Config{Conn: conn, Method: testMethod})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := tr.Send(context.Background(), []monitor.Event{{Name: "test.closed"}}); err != ErrClosed {
+		t.Errorf("Send() after Close error = %v, want ErrClosed", err)
+	}
+}
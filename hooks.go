@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// shutdownHooks holds the functions registered with RegisterShutdownHook.
+type shutdownHooks struct {
+	mu  sync.Mutex
+	fns []func(context.Context) error
+}
+
+// RegisterShutdownHook registers fn to run during Shutdown/ShutdownContext,
+// after the final flush. Transports and sinks use it to release resources
+// (close producers, streams, files). Hooks run in LIFO order, each once; a
+// hook must be registered again to run on a later Shutdown. Events emitted
+// from a hook are dropped, since the monitor is already shut down.
+func RegisterShutdownHook(fn func(context.Context) error) {
+	defaultMonitor.RegisterShutdownHook(fn)
+}
+
+// RegisterShutdownHook registers fn to run during m's Shutdown, like the
+// package-level RegisterShutdownHook.
+func (m *Monitor) RegisterShutdownHook(fn func(context.Context) error) {
+	if fn == nil {
+		return
+	}
+	m.hooks.mu.Lock()
+	m.hooks.fns = append(m.hooks.fns, fn)
+	m.hooks.mu.Unlock()
+}
+
+// run runs and clears the registered hooks in LIFO order, returning their
+// errors joined. Every hook runs even if an earlier one fails.
+func (h *shutdownHooks) run(ctx context.Context) error {
+	h.mu.Lock()
+	fns := h.fns
+	h.fns = nil
+	h.mu.Unlock()
+
+	var errs []error
+	for i := len(fns) - 1; i >= 0; i-- {
+		if err := fns[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
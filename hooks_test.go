@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	rt := &recordingTransport{}
+	if err := Init(Config{Service: "test-hooks", DisableStdout: true, Transport: rt}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	errFirst := errors.New("first failed")
+	errThird := errors.New("third failed")
+	var order []int
+	var shippedBeforeHooks int
+
+	RegisterShutdownHook(func(ctx context.Context) error {
+		order = append(order, 1)
+		return errFirst
+	})
+	RegisterShutdownHook(func(ctx context.Context) error {
+		order = append(order, 2)
+		return nil
+	})
+	RegisterShutdownHook(func(ctx context.Context) error {
+		order = append(order, 3)
+		shippedBeforeHooks = rt.eventCount()
+		return errThird
+	})
+
+	Emit(context.Background(), "test.before_shutdown", nil)
+	err := ShutdownContext(context.Background())
+
+	if len(order) != 3 || order[0] != 3 || order[1] != 2 || order[2] != 1 {
+		t.Errorf("hook order = %v, want [3 2 1]", order)
+	}
+	if !errors.Is(err, errFirst) || !errors.Is(err, errThird) {
+		t.Errorf("ShutdownContext() error = %v, want both hook errors", err)
+	}
+	if shippedBeforeHooks != 1 {
+		t.Errorf("events shipped before hooks ran = %d, want 1 (final flush first)", shippedBeforeHooks)
+	}
+
+	// Hooks run once
+	order = nil
+	if err := ShutdownContext(context.Background()); err != nil {
+		t.Errorf("second ShutdownContext() error = %v, want nil", err)
+	}
+	if len(order) != 0 {
+		t.Errorf("hooks ran again: %v", order)
+	}
+}
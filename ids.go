@@ -2,20 +2,85 @@ package monitor
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"strings"
 )
 
-// generateID creates a UUID v4 (random) format ID.
-// Format: xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx
-// Uses crypto/rand for secure randomness.
-func generateID() string {
-	return generateUUID()
+// ID formats for Config.IDFormat.
+const (
+	// IDFormatUUID is a UUID v4 with hyphens (36 characters). This is the default.
+	IDFormatUUID = "uuid"
+
+	// IDFormatUUIDNoHyphen is a UUID v4 without hyphens (32 hex characters).
+	IDFormatUUIDNoHyphen = "uuid-nohyphen"
+
+	// IDFormatHex16 is 16 random hex characters.
+	IDFormatHex16 = "hex16"
+
+	// IDFormatHex32 is 32 random hex characters.
+	IDFormatHex32 = "hex32"
+
+	// IDFormatOTel matches OpenTelemetry: trace IDs are 32 hex characters (16
+	// random bytes), and job and request IDs are 16 like span IDs.
+	IDFormatOTel = "otel"
+)
+
+// validIDFormat reports whether format is empty (the default) or a known ID format.
+func validIDFormat(format string) bool {
+	switch format {
+	case "", IDFormatUUID, IDFormatUUIDNoHyphen, IDFormatHex16, IDFormatHex32, IDFormatOTel:
+		return true
+	}
+	return false
+}
+
+// newID creates a job ID, or a request or trace ID without a dedicated
+// generator: Config.IDGenerator if set, otherwise an ID in Config.IDFormat.
+// cfg may be nil.
+func newID(cfg *Config) string {
+	if cfg == nil {
+		return generateIDFormat("")
+	}
+	if cfg.IDGenerator != nil {
+		return cfg.IDGenerator()
+	}
+	return generateIDFormat(cfg.IDFormat)
+}
+
+// newRequestID creates a request ID, preferring Config.RequestIDGenerator.
+func newRequestID(cfg *Config) string {
+	if cfg != nil && cfg.RequestIDGenerator != nil {
+		return cfg.RequestIDGenerator()
+	}
+	return newID(cfg)
 }
 
-// generateShortID creates a UUID v4 (random) format ID.
-// For consistency, all IDs use the same UUID format.
-func generateShortID() string {
-	return generateUUID()
+// newTraceID creates a trace ID, preferring Config.TraceIDGenerator. Under
+// IDFormatOTel, trace IDs are twice as long as other IDs.
+func newTraceID(cfg *Config) string {
+	if cfg != nil && cfg.TraceIDGenerator != nil {
+		return cfg.TraceIDGenerator()
+	}
+	if cfg != nil && cfg.IDGenerator == nil && cfg.IDFormat == IDFormatOTel {
+		return randomHex(16)
+	}
+	return newID(cfg)
+}
+
+// generateIDFormat creates an ID in the given format using crypto/rand. Unknown formats fall
+// back to UUID; Init rejects them before they get here.
+func generateIDFormat(format string) string {
+	switch format {
+	case IDFormatUUIDNoHyphen:
+		return strings.ReplaceAll(generateUUID(), "-", "")
+	case IDFormatHex16, IDFormatOTel:
+		return randomHex(8)
+	case IDFormatHex32:
+		return randomHex(16)
+	default:
+		return generateUUID()
+	}
 }
 
 // generateUUID creates a UUID v4 (random) format string.
@@ -32,3 +97,18 @@ func generateUUID() string {
 	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
 		b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
+
+// generateSpanID creates a 16-hex-character (8 random bytes) span ID,
+// the format used by W3C Trace Context and OpenTelemetry.
+func generateSpanID() string {
+	return randomHex(8)
+}
+
+// randomHex returns n random bytes hex-encoded (2n characters).
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("monitor: failed to generate random ID: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,144 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIDFormat(t *testing.T) {
+	isHex := func(s string) bool {
+		for _, c := range s {
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+				return false
+			}
+		}
+		return true
+	}
+
+	tests := []struct {
+		format       string
+		wantLen      int
+		wantTraceLen int
+		hexOnly      bool
+	}{
+		{"", 36, 36, false},
+		{IDFormatUUID, 36, 36, false},
+		{IDFormatUUIDNoHyphen, 32, 32, true},
+		{IDFormatHex16, 16, 16, true},
+		{IDFormatHex32, 32, 32, true},
+		{IDFormatOTel, 16, 32, true},
+	}
+
+	for _, tt := range tests {
+		t.Run("format "+tt.format, func(t *testing.T) {
+			if err := Init(Config{Service: "test-id-format", DisableStdout: true, IDFormat: tt.format}); err != nil {
+				t.Fatalf("Init() error = %v", err)
+			}
+
+			var requestID, traceID string
+			handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestID = RequestID(r.Context())
+				traceID = TraceID(r.Context())
+			}))
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+			spanCtx, span := StartSpan(context.Background(), "test.span")
+			span.Finish()
+
+			ids := map[string]string{
+				"job_id":        defaultMonitor.config.Load().JobID,
+				"request_id":    requestID,
+				"trace_id":      traceID,
+				"span_trace_id": TraceID(spanCtx),
+			}
+			for field, id := range ids {
+				wantLen := tt.wantLen
+				if strings.HasSuffix(field, "trace_id") {
+					wantLen = tt.wantTraceLen
+				}
+				if len(id) != wantLen {
+					t.Errorf("%s = %q, want length %d", field, id, wantLen)
+				}
+				if tt.hexOnly && !isHex(id) {
+					t.Errorf("%s = %q, want lowercase hex only", field, id)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := Init(Config{Service: "test-id-format", DisableStdout: true, IDFormat: "ulid"}); err == nil {
+			t.Error("Init() error = nil, want error for unknown IDFormat")
+		}
+	})
+}
+
+func TestIDGenerator(t *testing.T) {
+	counter := func(prefix string) func() string {
+		var n atomic.Int64
+		return func() string {
+			return fmt.Sprintf("%s-%d", prefix, n.Add(1))
+		}
+	}
+	serve := func(handler func(http.Handler) http.Handler) (requestID, traceID string) {
+		h := handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID = RequestID(r.Context())
+			traceID = TraceID(r.Context())
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+		return requestID, traceID
+	}
+
+	t.Run("IDGenerator", func(t *testing.T) {
+		if err := Init(Config{Service: "test-id-generator", DisableStdout: true, IDGenerator: counter("id")}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if jobID := defaultMonitor.config.Load().JobID; jobID != "id-1" {
+			t.Errorf("job ID = %q, want id-1", jobID)
+		}
+		if requestID, traceID := serve(Middleware); requestID != "id-2" || traceID != "id-3" {
+			t.Errorf("Middleware IDs = %q, %q, want id-2, id-3", requestID, traceID)
+		}
+		ctx, span := StartSpan(context.Background(), "test.span")
+		span.Finish()
+		if traceID := TraceID(ctx); traceID != "id-4" {
+			t.Errorf("StartSpan trace ID = %q, want id-4", traceID)
+		}
+		if spanID := SpanID(ctx); len(spanID) != 16 {
+			t.Errorf("span ID = %q, want 16 hex characters regardless of IDGenerator", spanID)
+		}
+	})
+
+	t.Run("request and trace generators", func(t *testing.T) {
+		if err := Init(Config{
+			Service:            "test-id-generator",
+			DisableStdout:      true,
+			IDGenerator:        counter("job"),
+			RequestIDGenerator: counter("req"),
+			TraceIDGenerator:   counter("trace"),
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if jobID := defaultMonitor.config.Load().JobID; jobID != "job-1" {
+			t.Errorf("job ID = %q, want job-1", jobID)
+		}
+		if requestID, traceID := serve(Middleware); requestID != "req-1" || traceID != "trace-1" {
+			t.Errorf("Middleware IDs = %q, %q, want req-1, trace-1", requestID, traceID)
+		}
+	})
+
+	t.Run("per Monitor", func(t *testing.T) {
+		m, err := New(Config{Service: "test-id-generator", DisableStdout: true, RequestIDGenerator: counter("tenant")})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer m.Shutdown()
+		if requestID, _ := serve(m.Middleware); requestID != "tenant-1" {
+			t.Errorf("request ID = %q, want tenant-1", requestID)
+		}
+	})
+}
@@ -1,6 +1,12 @@
 package monitor
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
 
 // Log level constants.
 const (
@@ -9,33 +15,185 @@ const (
 	LevelWarn  = "warn"
 	LevelError = "error"
 	LevelFatal = "fatal"
+
+	// LevelAudit marks tamper-evident audit events. See EmitAudit.
+	LevelAudit = "audit"
 )
 
+// levelRanks orders the levels for Config.MinLevel. LevelAudit is unranked:
+// audit events are never filtered, so the hash chain stays intact.
+var levelRanks = map[string]int{
+	LevelDebug: 0,
+	LevelInfo:  1,
+	LevelWarn:  2,
+	LevelError: 3,
+	LevelFatal: 4,
+}
+
+// knownLevel reports whether level is one of the Level constants.
+func knownLevel(level string) bool {
+	_, ok := levelRanks[level]
+	return ok || level == LevelAudit
+}
+
+// belowMinLevel reports whether an event at level is filtered out by
+// Config.MinLevel. Levels outside the known set rank as info.
+func belowMinLevel(cfg *Config, level string) bool {
+	if cfg.MinLevel == "" || level == LevelAudit {
+		return false
+	}
+	rank, ok := levelRanks[level]
+	if !ok {
+		rank = levelRanks[LevelInfo]
+	}
+	return rank < levelRanks[cfg.MinLevel]
+}
+
+// checkLevel returns a level passed to WithLevel, or LevelInfo if it isn't a
+// known level. The first unknown level is reported with a
+// monitor.unknown_level event. Empty is kept, for newEvent to resolve.
+func (m *Monitor) checkLevel(level string) string {
+	if level == "" || knownLevel(level) {
+		return level
+	}
+	m.unknownLevelOnce.Do(func() {
+		fmt.Fprintf(os.Stderr, "monitor: unknown level %q, using %q\n", level, LevelInfo)
+		m.emitSelf("monitor.unknown_level", map[string]any{"level": level}, LevelWarn)
+	})
+	return LevelInfo
+}
+
 // Debug emits a debug-level event. Only emits if Config.Debug is true.
 func Debug(ctx context.Context, name string, data any) {
-	cfg := globalConfig.Load()
+	defaultMonitor.emitDebug(ctx, name, data, 2)
+}
+
+// Debug emits a debug-level event through m if m's Config.Debug is true.
+func (m *Monitor) Debug(ctx context.Context, name string, data any) {
+	m.emitDebug(ctx, name, data, 2)
+}
+
+// emitDebug implements Debug for callers at the given depth.
+func (m *Monitor) emitDebug(ctx context.Context, name string, data any, callerDepth int) {
+	cfg := m.config.Load()
 	if cfg == nil || !cfg.Debug {
 		return
 	}
-	emitWithCallerDepth(ctx, name, data, LevelDebug, 2)
+	m.emitWithCallerDepth(ctx, name, data, LevelDebug, callerDepth+1)
 }
 
 // Info emits an info-level event.
 func Info(ctx context.Context, name string, data any) {
-	emitWithCallerDepth(ctx, name, data, LevelInfo, 2)
+	defaultMonitor.emitWithCallerDepth(ctx, name, data, LevelInfo, 2)
+}
+
+// Info emits an info-level event through m.
+func (m *Monitor) Info(ctx context.Context, name string, data any) {
+	m.emitWithCallerDepth(ctx, name, data, LevelInfo, 2)
 }
 
 // Warn emits a warn-level event.
 func Warn(ctx context.Context, name string, data any) {
-	emitWithCallerDepth(ctx, name, data, LevelWarn, 2)
+	defaultMonitor.emitWithCallerDepth(ctx, name, data, LevelWarn, 2)
+}
+
+// Warn emits a warn-level event through m.
+func (m *Monitor) Warn(ctx context.Context, name string, data any) {
+	m.emitWithCallerDepth(ctx, name, data, LevelWarn, 2)
 }
 
 // Error emits an error-level event.
 func Error(ctx context.Context, name string, data any) {
-	emitWithCallerDepth(ctx, name, data, LevelError, 2)
+	defaultMonitor.emitWithCallerDepth(ctx, name, data, LevelError, 2)
+}
+
+// Error emits an error-level event through m.
+func (m *Monitor) Error(ctx context.Context, name string, data any) {
+	m.emitWithCallerDepth(ctx, name, data, LevelError, 2)
 }
 
 // Fatal emits a fatal-level event.
 func Fatal(ctx context.Context, name string, data any) {
-	emitWithCallerDepth(ctx, name, data, LevelFatal, 2)
+	defaultMonitor.emitWithCallerDepth(ctx, name, data, LevelFatal, 2)
+}
+
+// Fatal emits a fatal-level event through m.
+func (m *Monitor) Fatal(ctx context.Context, name string, data any) {
+	m.emitWithCallerDepth(ctx, name, data, LevelFatal, 2)
+}
+
+// levelRule is a precompiled Config.DefaultLevels entry.
+type levelRule struct {
+	pattern  string
+	parts    []string // pattern split on '*'
+	literals int      // number of non-wildcard characters, used for precedence
+	level    string
+}
+
+// compileLevelRules precompiles DefaultLevels and orders the rules by
+// precedence so the first match wins: exact names, then patterns with more
+// literal characters, then pattern text for a stable order.
+func compileLevelRules(defaults map[string]string) []levelRule {
+	if len(defaults) == 0 {
+		return nil
+	}
+
+	rules := make([]levelRule, 0, len(defaults))
+	for pattern, level := range defaults {
+		parts := strings.Split(pattern, "*")
+		rules = append(rules, levelRule{
+			pattern:  pattern,
+			parts:    parts,
+			literals: len(pattern) - (len(parts) - 1),
+			level:    level,
+		})
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		ei, ej := len(rules[i].parts) == 1, len(rules[j].parts) == 1
+		if ei != ej {
+			return ei
+		}
+		if rules[i].literals != rules[j].literals {
+			return rules[i].literals > rules[j].literals
+		}
+		return rules[i].pattern < rules[j].pattern
+	})
+	return rules
+}
+
+// match reports whether name matches the rule's pattern.
+func (r *levelRule) match(name string) bool {
+	if len(r.parts) == 1 {
+		return name == r.pattern
+	}
+
+	first, last := r.parts[0], r.parts[len(r.parts)-1]
+	if len(name) < len(first)+len(last) || !strings.HasPrefix(name, first) || !strings.HasSuffix(name, last) {
+		return false
+	}
+
+	// Match middle segments in order between the prefix and suffix
+	rest := name[len(first) : len(name)-len(last)]
+	for _, part := range r.parts[1 : len(r.parts)-1] {
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return true
+}
+
+// defaultLevelFor returns the level for an event emitted without an explicit
+// level: the first matching DefaultLevels rule, or info.
+func defaultLevelFor(cfg *Config, name string) string {
+	if cfg != nil {
+		for i := range cfg.levelRules {
+			if cfg.levelRules[i].match(name) {
+				return cfg.levelRules[i].level
+			}
+		}
+	}
+	return LevelInfo
 }
@@ -1,7 +1,10 @@
 package monitor
 
 import (
+	"bytes"
 	"context"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -64,7 +67,7 @@ func TestDebugOnlyEmitsWhenEnabled(t *testing.T) {
 }
 
 func TestConvenienceFunctionsBeforeInit(t *testing.T) {
-	globalConfig.Store(nil)
+	defaultMonitor.config.Store(nil)
 
 	ctx := context.Background()
 
@@ -75,3 +78,146 @@ func TestConvenienceFunctionsBeforeInit(t *testing.T) {
 	Error(ctx, "test.error", nil)
 	Fatal(ctx, "test.fatal", nil)
 }
+
+func TestDefaultLevels(t *testing.T) {
+	if err := Init(Config{
+		Service:       "test-default-levels",
+		DisableStdout: true,
+		DefaultLevels: map[string]string{
+			"*.error":         LevelError,
+			"*.debug":         LevelDebug,
+			"payment.*":       LevelWarn,
+			"payment.*.error": LevelFatal,
+			"payment.refund":  LevelInfo,
+			"cache.*.miss":    LevelDebug,
+		},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"db.error", LevelError},
+		{"worker.debug", LevelDebug},
+		{"payment.created", LevelWarn},
+		{"payment.card.error", LevelFatal}, // more literal characters than *.error and payment.*
+		{"payment.refund", LevelInfo},      // exact name beats payment.*
+		{"cache.users.miss", LevelDebug},
+		{"cache.users.hit", LevelInfo},
+		{"user.created", LevelInfo}, // no match
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := newEvent(context.Background(), defaultMonitor.config.Load(), tt.name, nil, "")
+			if event.Level != tt.want {
+				t.Errorf("level = %v, want %v", event.Level, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown level rejected", func(t *testing.T) {
+		_, err := New(Config{Service: "test-default-levels", DefaultLevels: map[string]string{"db.*": "verbose"}})
+		if err == nil || !strings.Contains(err.Error(), `"verbose"`) {
+			t.Errorf("New() error = %v, want unknown level error", err)
+		}
+		if err := Verify(Config{Service: "test-default-levels", DefaultLevels: map[string]string{"db.*": "verbose"}}); err == nil {
+			t.Error("Verify() error = nil, want unknown level error")
+		}
+	})
+
+	t.Run("explicit level wins", func(t *testing.T) {
+		event := newEvent(context.Background(), defaultMonitor.config.Load(), "db.error", nil, LevelWarn)
+		if event.Level != LevelWarn {
+			t.Errorf("level = %v, want warn", event.Level)
+		}
+	})
+}
+
+func TestLevelRuleMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*", "anything", true},
+		{"a.*", "a.b", true},
+		{"a.*", "b.a", false},
+		{"*.b", "a.b", true},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "a-x-b-y-c", true},
+		{"a*b*c", "a-x-c-y-b", false},
+		{"ab*ba", "aba", false}, // prefix and suffix must not overlap
+	}
+
+	for _, tt := range tests {
+		rules := compileLevelRules(map[string]string{tt.pattern: LevelWarn})
+		if got := rules[0].match(tt.name); got != tt.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMinLevel(t *testing.T) {
+	if err := Init(Config{Service: "test-min-level", DisableStdout: true, Debug: true, MinLevel: LevelWarn}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Init(Config{Service: "test-min-level", DisableStdout: true})
+
+	ctx := context.Background()
+	events := Captured(func() {
+		Debug(ctx, "test.debug", nil)
+		Info(ctx, "test.info", nil)
+		Emit(ctx, "test.emit-debug", nil, WithLevel(LevelDebug))
+		Warn(ctx, "test.warn", nil)
+		Error(ctx, "test.error", nil)
+		EmitAudit(ctx, "test.audit", nil)
+	})
+
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Name)
+	}
+	want := []string{"test.warn", "test.error", "test.audit"}
+	if !slices.Equal(got, want) {
+		t.Errorf("emitted %v, want %v", got, want)
+	}
+
+	if err := EmitSync(ctx, "test.sync-debug", nil, WithLevel(LevelDebug)); err != nil {
+		t.Errorf("EmitSync() below MinLevel error = %v, want nil", err)
+	}
+
+	if err := Init(Config{Service: "test-min-level", MinLevel: "verbose"}); err == nil {
+		t.Error("Init() with unknown MinLevel should fail")
+	}
+}
+
+func TestWithLevelUnknown(t *testing.T) {
+	var internal bytes.Buffer
+	m, err := New(Config{Service: "test-unknown-level", DisableStdout: true, InternalSink: &internal})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+
+	events := Captured(func() {
+		m.Emit(context.Background(), "test.first", nil, WithLevel("verbose"))
+		m.Emit(context.Background(), "test.second", nil, WithLevel("trace"))
+	})
+	if len(events) != 2 {
+		t.Fatalf("captured %d events, want 2", len(events))
+	}
+	for _, e := range events {
+		if e.Level != LevelInfo {
+			t.Errorf("%s level = %q, want %q", e.Name, e.Level, LevelInfo)
+		}
+	}
+	if n := strings.Count(internal.String(), "monitor.unknown_level"); n != 1 {
+		t.Errorf("unknown_level warnings = %d, want 1: %s", n, internal.String())
+	}
+	if !strings.Contains(internal.String(), `"level":"verbose"`) {
+		t.Errorf("warning = %s, want the unknown level", internal.String())
+	}
+}
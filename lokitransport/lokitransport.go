@@ -0,0 +1,201 @@
+// Package lokitransport ships go-monitor batches to Grafana Loki's push API.
+//
+// Events are grouped into Loki streams by a small, low-cardinality label set
+// (service, env, level, plus any static labels). Each event's full JSON is
+// the log line, so request_id, trace_id, and data remain queryable with
+// LogQL's json parser without exploding stream cardinality.
+//
+// Usage:
+//
+//	t, err := lokitransport.New(lokitransport.Config{
+//	    URL:      "http://loki:3100",
+//	    TenantID: "team-a",
+//	})
+//	monitor.Init(monitor.Config{Service: "api", Transport: t})
+package lokitransport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+// pushPath is Loki's HTTP push endpoint.
+const pushPath = "/loki/api/v1/push"
+
+// ErrURLRequired is returned when Config.URL is empty.
+var ErrURLRequired = errors.New("lokitransport: Config.URL is required")
+
+// Config configures the Loki transport.
+type Config struct {
+	// URL is the Loki base URL (e.g., "http://loki:3100"). The push path is
+	// appended unless the URL already ends with it. Required.
+	URL string
+
+	// TenantID is sent as X-Scope-OrgID for multi-tenant Loki. Optional.
+	TenantID string
+
+	// Username and Password enable basic auth (e.g., Grafana Cloud). Optional.
+	Username string
+	Password string
+
+	// Labels are static labels added to every stream (e.g., {"cluster": "eu-1"}).
+	// Keep these low-cardinality. Optional.
+	Labels map[string]string
+
+	// HTTPClient is used for push requests. Default: a client with a 30s timeout.
+	HTTPClient *http.Client
+}
+
+// Transport implements monitor.Transport for Loki.
+type Transport struct {
+	cfg     Config
+	pushURL string
+	client  *http.Client
+}
+
+// New creates a Loki transport.
+func New(cfg Config) (*Transport, error) {
+	if cfg.URL == "" {
+		return nil, ErrURLRequired
+	}
+
+	pushURL := strings.TrimSuffix(cfg.URL, "/")
+	if !strings.HasSuffix(pushURL, pushPath) {
+		pushURL += pushPath
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Transport{cfg: cfg, pushURL: pushURL, client: client}, nil
+}
+
+// pushRequest is the JSON body of a Loki push.
+type pushRequest struct {
+	Streams []stream `json:"streams"`
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Send implements monitor.Transport.
+func (t *Transport) Send(ctx context.Context, batch []monitor.Event) error {
+	body, err := json.Marshal(t.buildPush(batch))
+	if err != nil {
+		return fmt.Errorf("lokitransport: marshal push: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.pushURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("lokitransport: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.cfg.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", t.cfg.TenantID)
+	}
+	if t.cfg.Username != "" || t.cfg.Password != "" {
+		req.SetBasicAuth(t.cfg.Username, t.cfg.Password)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("lokitransport: push: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("lokitransport: push returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// buildPush groups events into streams keyed by their label set.
+func (t *Transport) buildPush(batch []monitor.Event) pushRequest {
+	byKey := make(map[string]*stream)
+	var order []string
+
+	for _, event := range batch {
+		line, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+
+		labels := t.labelsFor(event)
+		key := labelKey(labels)
+		s, ok := byKey[key]
+		if !ok {
+			s = &stream{Stream: labels}
+			byKey[key] = s
+			order = append(order, key)
+		}
+		s.Values = append(s.Values, [2]string{timestampNanos(event.Timestamp), string(line)})
+	}
+
+	req := pushRequest{Streams: make([]stream, 0, len(order))}
+	for _, key := range order {
+		req.Streams = append(req.Streams, *byKey[key])
+	}
+	return req
+}
+
+// labelsFor returns the stream labels for an event. Per-request IDs are
+// deliberately excluded to keep stream cardinality low.
+func (t *Transport) labelsFor(event monitor.Event) map[string]string {
+	labels := make(map[string]string, len(t.cfg.Labels)+3)
+	for k, v := range t.cfg.Labels {
+		labels[k] = v
+	}
+	labels["service"] = event.Service
+	labels["level"] = event.Level
+	if event.Env != "" {
+		labels["env"] = event.Env
+	}
+	return labels
+}
+
+// labelKey returns a canonical string for a label set.
+func labelKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(labels[k])
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// timestampNanos converts an event timestamp to Loki's unix-nanosecond string,
+// falling back to the current time if it can't be parsed.
+func timestampNanos(ts string) string {
+	parsed, err := (monitor.Event{Timestamp: ts}).Time()
+	if err != nil {
+		parsed = time.Now()
+	}
+	return strconv.FormatInt(parsed.UnixNano(), 10)
+}
+
+var _ monitor.Transport = (*Transport)(nil)
@@ -0,0 +1,114 @@
+package lokitransport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{}); err != ErrURLRequired {
+		t.Errorf("New() error = %v, want ErrURLRequired", err)
+	}
+
+	tr, err := New(Config{URL: "http://loki:3100/"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tr.pushURL != "http://loki:3100/loki/api/v1/push" {
+		t.Errorf("pushURL = %v, want push path appended", tr.pushURL)
+	}
+}
+
+func TestSend(t *testing.T) {
+	var (
+		gotPath, gotTenant, gotType string
+		gotUser, gotPass            string
+		gotPush                     pushRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotTenant = r.Header.Get("X-Scope-OrgID")
+		gotType = r.Header.Get("Content-Type")
+		gotUser, gotPass, _ = r.BasicAuth()
+		if err := json.NewDecoder(r.Body).Decode(&gotPush); err != nil {
+			t.Errorf("decode push body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tr, err := New(Config{
+		URL:      server.URL,
+		TenantID: "team-a",
+		Username: "user",
+		Password: "pass",
+		Labels:   map[string]string{"cluster": "eu-1"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	batch := []monitor.Event{
+		{Timestamp: ts.Format(time.RFC3339Nano), Service: "api", Env: "prod", Level: "info", Name: "a", RequestID: "req-1"},
+		{Timestamp: ts.Format(time.RFC3339Nano), Service: "api", Env: "prod", Level: "error", Name: "b", RequestID: "req-2"},
+		{Timestamp: ts.Format(time.RFC3339Nano), Service: "api", Env: "prod", Level: "info", Name: "c", RequestID: "req-3"},
+	}
+	if err := tr.Send(context.Background(), batch); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if gotPath != pushPath {
+		t.Errorf("path = %v, want %v", gotPath, pushPath)
+	}
+	if gotTenant != "team-a" {
+		t.Errorf("X-Scope-OrgID = %v, want team-a", gotTenant)
+	}
+	if gotType != "application/json" {
+		t.Errorf("Content-Type = %v, want application/json", gotType)
+	}
+	if gotUser != "user" || gotPass != "pass" {
+		t.Errorf("basic auth = %v:%v, want user:pass", gotUser, gotPass)
+	}
+
+	// Two streams: one per level
+	if len(gotPush.Streams) != 2 {
+		t.Fatalf("streams = %d, want 2", len(gotPush.Streams))
+	}
+	info := gotPush.Streams[0]
+	if info.Stream["level"] != "info" || info.Stream["service"] != "api" || info.Stream["env"] != "prod" || info.Stream["cluster"] != "eu-1" {
+		t.Errorf("stream labels = %v", info.Stream)
+	}
+	if _, ok := info.Stream["request_id"]; ok {
+		t.Error("request_id must not be a stream label")
+	}
+	if len(info.Values) != 2 {
+		t.Fatalf("info stream values = %d, want 2", len(info.Values))
+	}
+	if info.Values[0][0] != "1767323045000000006" {
+		t.Errorf("timestamp = %v, want unix nanos", info.Values[0][0])
+	}
+	if !strings.Contains(info.Values[0][1], `"request_id":"req-1"`) {
+		t.Errorf("line should be the event JSON, got %v", info.Values[0][1])
+	}
+}
+
+func TestSendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "entry too far behind", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	tr, _ := New(Config{URL: server.URL})
+	err := tr.Send(context.Background(), []monitor.Event{{Service: "api", Level: "info"}})
+	if err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Errorf("Send() error = %v, want status 400 error", err)
+	}
+}
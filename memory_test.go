@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hungTransport blocks every Send until released, like an endpoint that
+// accepts connections but never answers.
+type hungTransport struct {
+	release chan struct{}
+	sends   atomic.Int32
+}
+
+func (h *hungTransport) Send(ctx context.Context, batch []Event) error {
+	h.sends.Add(1)
+	select {
+	case <-h.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// held returns how many events the shipper is holding in its queue and buffer.
+func (s *shipper) held() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.eventsCh) + s.events.len()
+}
+
+func TestShipperMemoryBounded(t *testing.T) {
+	const batchSize, queueSize = 10, 50
+
+	t.Run("endpoint down", func(t *testing.T) {
+		ht := &hungTransport{release: make(chan struct{})}
+		if err := Init(Config{
+			Service:       "test-memory",
+			DisableStdout: true,
+			Transport:     ht,
+			BatchSize:     batchSize,
+			QueueSize:     queueSize,
+			FlushEvery:    time.Hour,
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		s := defaultMonitor.shipper.Load()
+
+		for i := 0; i < 100*queueSize; i++ {
+			Emit(context.Background(), "test.outage", nil)
+		}
+
+		// One batch in flight, the rest dropped at the queue
+		if got, max := s.held(), 2*queueSize+batchSize; got > max {
+			t.Errorf("shipper holds %d events, want at most %d", got, max)
+		}
+
+		close(ht.release)
+		Shutdown()
+	})
+
+	t.Run("stop drain with active producers", func(t *testing.T) {
+		// Unstarted shipper: drainQueued runs while producers keep sending
+		s := newShipper(defaultMonitor, &Config{Service: "test-memory", IngestURL: "http://unused", BatchSize: batchSize, QueueSize: queueSize, FlushEvery: time.Hour})
+
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						s.send(Event{Name: "test.producer"})
+					}
+				}
+			}()
+		}
+
+		for i := 0; i < 20; i++ {
+			s.drainQueued()
+		}
+		close(stop)
+		wg.Wait()
+
+		if got, max := s.events.len(), s.maxBuffered(); got > max {
+			t.Errorf("buffer holds %d events, want at most %d", got, max)
+		}
+	})
+}
+
+func TestEventBufferTrim(t *testing.T) {
+	var b eventBuffer
+	b.push(
+		Event{Name: "debug.1", priority: PriorityLow},
+		Event{Name: "error.1", priority: PriorityCritical},
+		Event{Name: "debug.2", priority: PriorityLow},
+		Event{Name: "info.1", priority: PriorityNormal},
+	)
+
+	dropped := b.trim(2)
+	if len(dropped) != 2 {
+		t.Fatalf("trim(2) dropped %d, want 2", len(dropped))
+	}
+	if names := eventNames(dropped); names[0] != "debug.1" || names[1] != "debug.2" {
+		t.Errorf("dropped = %v, want [debug.1 debug.2]", names)
+	}
+	got := eventNames(b.take(10, 0))
+	if len(got) != 2 || got[0] != "error.1" || got[1] != "info.1" {
+		t.Errorf("remaining = %v, want [error.1 info.1]", got)
+	}
+}
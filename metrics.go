@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// metricsContentType is the Prometheus text exposition format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsHandler serves the shipper counters reported by Stats in the
+// Prometheus text exposition format, as go_monitor_* series labeled with the
+// configured service. It writes the text itself rather than registering
+// collectors, so it can be mounted next to any other metrics handler.
+//
+// Usage:
+//
+//	http.Handle("/metrics/monitor", monitor.MetricsHandler())
+func MetricsHandler() http.Handler {
+	return defaultMonitor.MetricsHandler()
+}
+
+// MetricsHandler is like the package-level MetricsHandler, but serves m's counters.
+func (m *Monitor) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service := ""
+		if cfg := m.config.Load(); cfg != nil {
+			service = cfg.Service
+		}
+		w.Header().Set("Content-Type", metricsContentType)
+		_, _ = w.Write(renderMetrics(m.Stats(), service))
+	})
+}
+
+// renderMetrics formats st as Prometheus text exposition.
+func renderMetrics(st ShipperStats, service string) []byte {
+	labels := `{service="` + escapeLabelValue(service) + `"}`
+	var buf bytes.Buffer
+	metric := func(name, kind, help string, value any) {
+		fmt.Fprintf(&buf, "# HELP go_monitor_%s %s\n", name, help)
+		fmt.Fprintf(&buf, "# TYPE go_monitor_%s %s\n", name, kind)
+		fmt.Fprintf(&buf, "go_monitor_%s%s %v\n", name, labels, value)
+	}
+
+	metric("events_shipped_total", "counter", "Events accepted by the ingest endpoint.", st.TotalShipped)
+	metric("events_dropped_total", "counter", "Events discarded for any OnDrop reason.", st.TotalDropped)
+	metric("batches_shipped_total", "counter", "Batches accepted by the ingest endpoint.", st.TotalBatches)
+	metric("retries_total", "counter", "Delivery attempts that retried a failed batch.", st.TotalRetries)
+	metric("queue_depth", "gauge", "Events waiting in the shipper queue.", st.QueuedEvents)
+	metric("queue_capacity", "gauge", "Size of the shipper queue.", st.QueueCapacity)
+	metric("queue_high_water", "gauge", "Deepest the shipper queue has been since Init.", st.QueueHighWater)
+	return buf.Bytes()
+}
+
+// labelValueEscaper escapes a Prometheus label value.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes backslashes, quotes, and newlines in a label value.
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrapeMetrics fetches h and returns each sample's value keyed by its
+// series (name and labels), along with the response.
+func scrapeMetrics(t *testing.T, h http.Handler) (map[string]string, *httptest.ResponseRecorder) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	samples := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(rec.Body.String()))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			t.Fatalf("malformed sample line %q", line)
+		}
+		samples[line[:i]] = line[i+1:]
+	}
+	return samples, rec
+}
+
+func TestMetricsHandler(t *testing.T) {
+	rt := &recordingTransport{failN: 1}
+	if err := Init(Config{
+		Service:        "test-metrics",
+		DisableStdout:  true,
+		Transport:      rt,
+		BatchSize:      10,
+		QueueSize:      50,
+		RetryBaseDelay: time.Millisecond,
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	for i := 0; i < 15; i++ {
+		Emit(context.Background(), "test.metrics", nil)
+	}
+	Flush()
+
+	samples, rec := scrapeMetrics(t, MetricsHandler())
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want Prometheus text format", ct)
+	}
+
+	want := map[string]string{
+		`go_monitor_events_shipped_total{service="test-metrics"}`:  "15",
+		`go_monitor_events_dropped_total{service="test-metrics"}`:  "0",
+		`go_monitor_batches_shipped_total{service="test-metrics"}`: "2",
+		`go_monitor_retries_total{service="test-metrics"}`:         "1",
+		`go_monitor_queue_depth{service="test-metrics"}`:           "0",
+		`go_monitor_queue_capacity{service="test-metrics"}`:        "50",
+	}
+	for series, value := range want {
+		if got, ok := samples[series]; !ok {
+			t.Errorf("missing series %s in:\n%s", series, rec.Body.String())
+		} else if got != value {
+			t.Errorf("%s = %s, want %s", series, got, value)
+		}
+	}
+	if _, ok := samples[`go_monitor_queue_high_water{service="test-metrics"}`]; !ok {
+		t.Errorf("missing go_monitor_queue_high_water in:\n%s", rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "# TYPE go_monitor_events_shipped_total counter\n") {
+		t.Errorf("missing TYPE line for events_shipped_total in:\n%s", rec.Body.String())
+	}
+}
+
+func TestMetricsHandlerEscapesService(t *testing.T) {
+	m, err := New(Config{Service: `api "v2"`, DisableStdout: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+
+	samples, rec := scrapeMetrics(t, m.MetricsHandler())
+	if _, ok := samples[`go_monitor_events_shipped_total{service="api \"v2\""}`]; !ok {
+		t.Errorf("service label not escaped in:\n%s", rec.Body.String())
+	}
+}
@@ -17,43 +17,196 @@ const (
 
 	// HeaderTraceID is the HTTP header for trace ID.
 	HeaderTraceID = "X-Trace-Id"
+
+	// HeaderTraceparent is the W3C Trace Context header.
+	HeaderTraceparent = "traceparent"
+
+	// HeaderSampled carries a trace's sampling decision, "1" or "0". See
+	// WithSampled.
+	HeaderSampled = "X-Sampled"
+
+	// HeaderParentJobID carries the calling service's job ID, recorded by
+	// the receiving service as its parent job ID. See WithParentJobID.
+	HeaderParentJobID = "X-Parent-Job-Id"
 )
 
-// propagateIDs extracts or generates request_id, trace_id, and job_id,
-// stores them in the context, and sets response headers for debugging.
-func propagateIDs(ctx context.Context, r *http.Request, w http.ResponseWriter) context.Context {
-	requestID := r.Header.Get(HeaderRequestID)
-	if requestID == "" {
-		requestID = generateShortID()
+// propagateIDs applies PropagateIDs to an HTTP request, reading IDs from its
+// headers and echoing them as response headers.
+func (m *Monitor) propagateIDs(ctx context.Context, r *http.Request, w http.ResponseWriter) context.Context {
+	return m.PropagateIDs(ctx, r.Header.Get, w.Header().Set)
+}
+
+// PropagateIDs extracts or generates request_id, trace_id, span_id, and
+// job_id and stores them in the context. It is the transport-independent core
+// of Middleware, for adapting other protocols (e.g., gRPC metadata).
+//
+// get looks up an incoming header by its canonical HTTP name (HeaderRequestID,
+// HeaderTraceID, HeaderTraceparent, HeaderParentJobID, or Config.XRayHeader)
+// and returns "" if absent. HeaderParentJobID, when present, is stored with
+// WithParentJobID. A valid W3C traceparent supplies the trace ID and parent span ID,
+// taking precedence over HeaderTraceID; a malformed one is ignored. set, if
+// non-nil, is called with HeaderRequestID and HeaderTraceID so the IDs can be
+// echoed to the caller; it is skipped when Config.EchoResponseHeaders is false.
+// IDs already present in the context (e.g., set by an outer Middleware) take
+// precedence over headers, so applying it twice is a no-op. With
+// Config.CorrelateRequestAndTrace, a request carrying only one of the request
+// and trace IDs uses it for both. With Config.TrustSampledHeader, a
+// HeaderSampled decision is stored with WithSampled; otherwise, or without
+// one, Config.DefaultSampleRate decides for the trace.
+func PropagateIDs(ctx context.Context, get func(key string) string, set func(key, value string)) context.Context {
+	return defaultMonitor.PropagateIDs(ctx, get, set)
+}
+
+// PropagateIDs is like the package-level PropagateIDs, but generates IDs and
+// reads XRayHeader, JobID, and EchoResponseHeaders according to m's config.
+func (m *Monitor) PropagateIDs(ctx context.Context, get func(key string) string, set func(key, value string)) context.Context {
+	cfg := m.config.Load()
+
+	requestID := RequestID(ctx)
+	hasRequestID := requestID != ""
+	if !hasRequestID {
+		requestID = get(HeaderRequestID)
 	}
-	ctx = WithRequestID(ctx, requestID)
 
-	traceID := r.Header.Get(HeaderTraceID)
-	if traceID == "" {
-		traceID = generateID()
+	traceID := TraceID(ctx)
+	hasTraceID := traceID != ""
+	if !hasTraceID {
+		var parent string
+		traceID, parent = headerTraceID(cfg, get)
+		if parent != "" && ParentSpanID(ctx) == "" {
+			ctx = context.WithValue(ctx, ctxKeyParentSpanID, parent)
+		}
 	}
-	ctx = WithTraceID(ctx, traceID)
 
-	jobID := JobID(ctx)
-	if jobID == "" {
-		if cfg := globalConfig.Load(); cfg != nil {
-			jobID = cfg.JobID
+	// With only one incoming ID, reuse it rather than generating an unrelated one
+	if cfg != nil && cfg.CorrelateRequestAndTrace {
+		if requestID == "" {
+			requestID = traceID
+		} else if traceID == "" {
+			traceID = requestID
 		}
 	}
+
+	if !hasRequestID {
+		if requestID == "" {
+			requestID = newRequestID(cfg)
+		}
+		ctx = WithRequestID(ctx, requestID)
+	}
+	if !hasTraceID {
+		if traceID == "" {
+			traceID = newTraceID(cfg)
+		}
+		ctx = WithTraceID(ctx, traceID)
+	}
+
+	// Honor a trusted upstream sampling decision, or make one for the whole trace
+	if _, ok := Sampled(ctx); !ok {
+		if s, ok := parseSampledHeader(get(HeaderSampled)); ok && cfg != nil && cfg.TrustSampledHeader {
+			ctx = WithSampled(ctx, s)
+		} else if cfg != nil && cfg.DefaultSampleRate > 0 {
+			ctx = WithSampled(ctx, traceFraction(traceID) < cfg.DefaultSampleRate)
+		}
+	}
+
+	// Each request handled by this service is its own span within the trace
+	if SpanID(ctx) == "" {
+		ctx = WithSpanID(ctx, generateSpanID())
+	}
+
+	// Number this request's events; an outer Middleware's counter is kept
+	if ctx.Value(ctxKeyRequestSeq) == nil {
+		ctx = WithRequestSeq(ctx)
+	}
+
+	jobID := JobID(ctx)
+	if jobID == "" && cfg != nil {
+		jobID = cfg.JobID
+	}
 	if jobID != "" {
 		ctx = WithJobID(ctx, jobID)
 	}
+	if ParentJobID(ctx) == "" {
+		if parentJobID := get(HeaderParentJobID); parentJobID != "" {
+			ctx = WithParentJobID(ctx, parentJobID)
+		}
+	}
 
-	w.Header().Set(HeaderRequestID, requestID)
-	w.Header().Set(HeaderTraceID, traceID)
+	if set != nil && echoResponseHeadersEnabled(cfg) {
+		set(HeaderRequestID, requestID)
+		set(HeaderTraceID, traceID)
+	}
 
 	return ctx
 }
 
-// Middleware is an HTTP middleware that ensures request_id and trace_id
-// exist on every request. It reads IDs from incoming headers if present,
-// otherwise generates new ones. The IDs are stored in the request context
-// and also set as response headers for debugging.
+// headerTraceID reads the incoming trace ID, and the parent span ID if the
+// header carries one, in PropagateIDs' order of precedence: Config.XRayHeader,
+// then traceparent, then HeaderTraceID. It returns "" if none is present.
+func headerTraceID(cfg *Config, get func(key string) string) (traceID, parentSpanID string) {
+	if cfg != nil && cfg.XRayHeader != "" {
+		if root, parent, ok := parseXRayHeader(get(cfg.XRayHeader)); ok {
+			return root, parent
+		}
+	}
+	if trace, parent, ok := parseTraceparent(get(HeaderTraceparent)); ok {
+		return trace, parent
+	}
+	return get(HeaderTraceID), ""
+}
+
+// InjectIDs ensures ctx carries request, trace, and span IDs (and the
+// configured job ID), generating any that are missing, exactly as Middleware
+// does for a request without ID headers. IDs already in ctx are kept. It
+// doesn't depend on net/http, so adapters for frameworks such as Echo or Gin
+// can build on it, typically after reading headers with IDsFromHeaders:
+//
+//	requestID, traceID := monitor.IDsFromHeaders(c.Request().Header)
+//	ctx := monitor.WithTraceID(monitor.WithRequestID(ctx, requestID), traceID)
+//	ctx = monitor.InjectIDs(ctx)
+func InjectIDs(ctx context.Context) context.Context {
+	return defaultMonitor.InjectIDs(ctx)
+}
+
+// InjectIDs is like the package-level InjectIDs, but generates IDs according
+// to m's config.
+func (m *Monitor) InjectIDs(ctx context.Context) context.Context {
+	return m.PropagateIDs(ctx, func(string) string { return "" }, nil)
+}
+
+// IDsFromHeaders returns the request and trace IDs carried by incoming HTTP
+// headers, read as Middleware reads them: the trace ID comes from
+// Config.XRayHeader (if set), then a valid traceparent, then X-Trace-Id. Each
+// is "" if absent; nothing is generated.
+func IDsFromHeaders(h http.Header) (requestID, traceID string) {
+	return defaultMonitor.IDsFromHeaders(h)
+}
+
+// IDsFromHeaders is like the package-level IDsFromHeaders, but reads
+// XRayHeader from m's config.
+func (m *Monitor) IDsFromHeaders(h http.Header) (requestID, traceID string) {
+	traceID, _ = headerTraceID(m.config.Load(), h.Get)
+	return h.Get(HeaderRequestID), traceID
+}
+
+// Middleware is an HTTP middleware that ensures request_id, trace_id, and
+// span_id exist on every request. It reads request and trace IDs from incoming
+// headers if present (the trace ID from a W3C traceparent header, falling back
+// to X-Trace-Id), otherwise generates new ones, and generates a fresh span ID
+// for each request. The IDs are stored in the request context and the
+// request and trace IDs are also set as response headers for debugging, unless
+// Config.EchoResponseHeaders is false.
+//
+// A panic in next is recovered: Middleware emits an error-level "http.panic"
+// event with the panic value and stack trace, writes a 500 if the handler
+// hadn't written a response yet, and then re-panics if
+// Config.RepanicAfterRecover is true, flushing the response first so the
+// client still receives it.
+//
+// When Config.EmitHTTPRequests is true, Middleware also emits an
+// "http.request" event as each request completes, with the method, path,
+// status, duration, and response bytes written, including for a request
+// that panicked.
 //
 // Compatible with gorilla/mux and any standard net/http router.
 //
@@ -62,12 +215,101 @@ func propagateIDs(ctx context.Context, r *http.Request, w http.ResponseWriter) c
 //	r := mux.NewRouter()
 //	r.Use(monitor.Middleware)
 func Middleware(next http.Handler) http.Handler {
+	return defaultMonitor.Middleware(next)
+}
+
+// Middleware is like the package-level Middleware, but propagates IDs
+// according to m's config.
+func (m *Monitor) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := propagateIDs(r.Context(), r, w)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		ctx := m.propagateIDs(r.Context(), r, w)
+		rw := &captureResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		start := time.Now()
+
+		// Deferred so a request whose panic is re-raised is still reported
+		defer func() {
+			if cfg := m.activeConfig(); cfg == nil || !cfg.EmitHTTPRequests {
+				return
+			}
+			level := LevelInfo
+			if rw.statusCode >= 500 {
+				level = LevelError
+			}
+			m.emitInternal(ctx, "http.request", map[string]any{
+				"request_method":  r.Method,
+				"request_path":    r.URL.Path,
+				"response_status": rw.statusCode,
+				"duration_ms":     time.Since(start).Milliseconds(),
+				"response_bytes":  rw.bytesWritten,
+			}, level)
+		}()
+		m.serveRecovering(next, rw, r.WithContext(ctx))
 	})
 }
 
+// WrapHandler applies Middleware to a single handler, for routers that take
+// per-route wrappers rather than func(http.Handler) http.Handler middleware.
+// The behavior, including response headers, is identical to Middleware.
+func WrapHandler(h http.Handler) http.Handler {
+	return defaultMonitor.WrapHandler(h)
+}
+
+// WrapHandlerFunc is like WrapHandler for an http.HandlerFunc.
+//
+// Usage:
+//
+//	http.HandleFunc("/orders", monitor.WrapHandlerFunc(handleOrders))
+func WrapHandlerFunc(h http.HandlerFunc) http.HandlerFunc {
+	return defaultMonitor.WrapHandlerFunc(h)
+}
+
+// WrapHandler is like the package-level WrapHandler, but propagates IDs
+// according to m's config.
+func (m *Monitor) WrapHandler(h http.Handler) http.Handler {
+	return m.Middleware(h)
+}
+
+// WrapHandlerFunc is like the package-level WrapHandlerFunc, but propagates
+// IDs according to m's config.
+func (m *Monitor) WrapHandlerFunc(h http.HandlerFunc) http.HandlerFunc {
+	return m.Middleware(h).ServeHTTP
+}
+
+// serveRecovering calls next, recovering a panic as described on Middleware.
+func (m *Monitor) serveRecovering(next http.Handler, w *captureResponseWriter, r *http.Request) {
+	defer m.recoverPanic(w, r)
+	next.ServeHTTP(w, r)
+}
+
+// recoverPanic must be deferred directly, since it calls recover. The
+// http.ErrAbortHandler sentinel is re-panicked unreported, as net/http uses
+// it to abort a response on purpose.
+func (m *Monitor) recoverPanic(w *captureResponseWriter, r *http.Request) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+
+	m.emitInternal(r.Context(), "http.panic", map[string]any{
+		"panic":          fmt.Sprint(v),
+		"stack":          callerStack(3),
+		"request_method": r.Method,
+		"request_path":   r.URL.Path,
+	}, LevelError)
+
+	if !w.wroteHeader {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+	if cfg := m.config.Load(); cfg != nil && cfg.RepanicAfterRecover {
+		// net/http drops the connection on a panic, discarding buffered output
+		w.Flush()
+		panic(v)
+	}
+}
+
 // MiddlewareConfig configures the enhanced HTTP middleware.
 type MiddlewareConfig struct {
 	// CaptureRequestBody enables capturing the request body in events.
@@ -82,12 +324,26 @@ type MiddlewareConfig struct {
 
 	// SkipPaths is a list of paths to skip monitoring (e.g., "/healthcheck").
 	SkipPaths []string
+
+	// EmitStartEnd emits a "request.start" event (method and path) before the
+	// handler runs and names the completion event "request.end" instead of
+	// "http.request". A start without a matching end (same request_id) marks a
+	// request that hung or was killed mid-flight. Doubles request-event volume.
+	// Default: false.
+	EmitStartEnd bool
 }
 
 // MiddlewareWithConfig returns an HTTP middleware that captures detailed
 // request/response information and emits "http.request" events.
-// It also performs the same ID propagation as the basic Middleware.
+// It also performs the same ID propagation and panic recovery as the basic
+// Middleware; a recovered panic is reported with status 500.
 func MiddlewareWithConfig(cfg MiddlewareConfig) func(http.Handler) http.Handler {
+	return defaultMonitor.MiddlewareWithConfig(cfg)
+}
+
+// MiddlewareWithConfig is like the package-level MiddlewareWithConfig, but
+// emits its events through m.
+func (m *Monitor) MiddlewareWithConfig(cfg MiddlewareConfig) func(http.Handler) http.Handler {
 	if cfg.MaxBodySize <= 0 {
 		cfg.MaxBodySize = 4096
 	}
@@ -99,7 +355,7 @@ func MiddlewareWithConfig(cfg MiddlewareConfig) func(http.Handler) http.Handler
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := propagateIDs(r.Context(), r, w)
+			ctx := m.propagateIDs(r.Context(), r, w)
 
 			// Check skip paths
 			if skipSet[r.URL.Path] {
@@ -109,6 +365,13 @@ func MiddlewareWithConfig(cfg MiddlewareConfig) func(http.Handler) http.Handler
 
 			start := time.Now()
 
+			if cfg.EmitStartEnd {
+				m.emitInternal(ctx, "request.start", map[string]any{
+					"request_method": r.Method,
+					"request_path":   r.URL.Path,
+				}, LevelInfo)
+			}
+
 			// Optionally capture request body
 			var reqBody string
 			if cfg.CaptureRequestBody && r.Body != nil {
@@ -129,50 +392,58 @@ func MiddlewareWithConfig(cfg MiddlewareConfig) func(http.Handler) http.Handler
 				maxBodySize:    cfg.MaxBodySize,
 			}
 
-			next.ServeHTTP(rw, r.WithContext(ctx))
-
-			duration := time.Since(start)
-
-			// Build event data
-			data := map[string]any{
-				"request_method":        r.Method,
-				"request_path":          r.URL.Path,
-				"request_query":         r.URL.RawQuery,
-				"response_status":       rw.statusCode,
-				"duration_ms":           duration.Milliseconds(),
-				"response_content_type": rw.Header().Get("Content-Type"),
-				"request_headers": map[string]string{
-					"Content-Type": r.Header.Get("Content-Type"),
-					"User-Agent":   r.Header.Get("User-Agent"),
-				},
-			}
+			// Deferred so a request whose panic is re-raised is still reported
+			defer func() {
+				duration := time.Since(start)
 
-			if cfg.CaptureRequestBody && reqBody != "" {
-				data["request_body"] = reqBody
-			}
-			if cfg.CaptureResponseBody && rw.body.Len() > 0 {
-				data["response_body"] = rw.body.String()
-			}
+				// Build event data
+				data := map[string]any{
+					"request_method":        r.Method,
+					"request_path":          r.URL.Path,
+					"request_query":         r.URL.RawQuery,
+					"response_status":       rw.statusCode,
+					"duration_ms":           duration.Milliseconds(),
+					"response_content_type": rw.Header().Get("Content-Type"),
+					"response_bytes":        rw.bytesWritten,
+					"request_headers": map[string]string{
+						"Content-Type": r.Header.Get("Content-Type"),
+						"User-Agent":   r.Header.Get("User-Agent"),
+					},
+				}
 
-			level := LevelInfo
-			if rw.statusCode >= 500 {
-				level = LevelError
-			}
+				if cfg.CaptureRequestBody && reqBody != "" {
+					data["request_body"] = reqBody
+				}
+				if cfg.CaptureResponseBody && rw.body.Len() > 0 {
+					data["response_body"] = rw.body.String()
+				}
+
+				level := LevelInfo
+				if rw.statusCode >= 500 {
+					level = LevelError
+				}
 
-			emitInternal(ctx, "http.request", data, level)
+				name := "http.request"
+				if cfg.EmitStartEnd {
+					name = "request.end"
+				}
+				m.emitInternal(ctx, name, data, level)
+			}()
+			m.serveRecovering(next, rw, r.WithContext(ctx))
 		})
 	}
 }
 
-// captureResponseWriter wraps http.ResponseWriter to capture the status code
-// and optionally the response body.
+// captureResponseWriter wraps http.ResponseWriter to capture the status code,
+// the number of body bytes written, and optionally the response body.
 type captureResponseWriter struct {
 	http.ResponseWriter
-	statusCode  int
-	wroteHeader bool
-	captureBody bool
-	maxBodySize int
-	body        bytes.Buffer
+	statusCode   int
+	wroteHeader  bool
+	bytesWritten int64
+	captureBody  bool
+	maxBodySize  int
+	body         bytes.Buffer
 }
 
 func (w *captureResponseWriter) WriteHeader(code int) {
@@ -195,18 +466,27 @@ func (w *captureResponseWriter) Write(b []byte) (int, error) {
 			w.body.Write(b[:remaining])
 		}
 	}
-	return w.ResponseWriter.Write(b)
+	n, err := w.ResponseWriter.Write(b)
+	w.bytesWritten += int64(n)
+	return n, err
 }
 
 func (w *captureResponseWriter) Flush() {
 	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
 		f.Flush()
 	}
 }
 
 func (w *captureResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		w.wroteHeader = true
 		return h.Hijack()
 	}
 	return nil, nil, fmt.Errorf("underlying ResponseWriter does not support hijacking")
 }
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *captureResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -1,10 +1,16 @@
 package monitor
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestMiddlewareWithConfig(t *testing.T) {
@@ -181,6 +187,36 @@ func TestMiddlewareWithConfig(t *testing.T) {
 			t.Errorf("trace ID = %v, want existing-trace", gotTraceID)
 		}
 	})
+
+	t.Run("start and end events", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+		wrapped := MiddlewareWithConfig(MiddlewareConfig{EmitStartEnd: true})(handler)
+
+		events := Captured(func() {
+			wrapped.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/jobs", nil))
+		})
+
+		if len(events) != 2 {
+			t.Fatalf("emitted %d events, want 2", len(events))
+		}
+		start, end := events[0], events[1]
+		if start.Name != "request.start" || end.Name != "request.end" {
+			t.Fatalf("names = %q, %q, want request.start, request.end", start.Name, end.Name)
+		}
+		if start.RequestID == "" || start.RequestID != end.RequestID {
+			t.Errorf("request IDs = %q, %q, want equal and non-empty", start.RequestID, end.RequestID)
+		}
+		startData, _ := start.Data.(map[string]any)
+		if startData["request_method"] != "POST" || startData["request_path"] != "/jobs" {
+			t.Errorf("start data = %v, want method and path", startData)
+		}
+		endData, _ := end.Data.(map[string]any)
+		if endData["response_status"] != http.StatusAccepted || endData["duration_ms"] == nil {
+			t.Errorf("end data = %v, want status and duration", endData)
+		}
+	})
 }
 
 func TestCaptureResponseWriter(t *testing.T) {
@@ -231,3 +267,698 @@ func TestCaptureResponseWriter(t *testing.T) {
 		}
 	})
 }
+
+func TestMiddlewareXRay(t *testing.T) {
+	const (
+		root   = "1-5759e988-bd862e3fe1be46a994272793"
+		header = "Root=" + root + ";Parent=53995c3f42cd8ad8;Sampled=1"
+	)
+
+	serve := func(headers map[string]string) (traceID, parentSpanID string) {
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceID = TraceID(r.Context())
+			parentSpanID = ParentSpanID(r.Context())
+		}))
+		req := httptest.NewRequest("GET", "/test", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return traceID, parentSpanID
+	}
+
+	t.Run("ignored unless configured", func(t *testing.T) {
+		if err := Init(Config{Service: "test-xray", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		traceID, _ := serve(map[string]string{HeaderXRayTraceID: header, HeaderTraceID: "trace-std"})
+		if traceID != "trace-std" {
+			t.Errorf("trace ID = %v, want trace-std", traceID)
+		}
+	})
+
+	if err := Init(Config{Service: "test-xray", DisableStdout: true, XRayHeader: HeaderXRayTraceID}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantTrace  string
+		wantParent string
+	}{
+		{
+			name:       "ALB header with parent",
+			headers:    map[string]string{HeaderXRayTraceID: header, HeaderTraceID: "trace-std"},
+			wantTrace:  root,
+			wantParent: "53995c3f42cd8ad8",
+		},
+		{
+			name:      "root only",
+			headers:   map[string]string{HeaderXRayTraceID: "Root=" + root},
+			wantTrace: root,
+		},
+		{
+			name:      "self field before root",
+			headers:   map[string]string{HeaderXRayTraceID: "Self=1-67891234-12456789abcdef012345678;Root=" + root},
+			wantTrace: root,
+		},
+		{
+			name:      "malformed root falls back",
+			headers:   map[string]string{HeaderXRayTraceID: "Root=1-xyz;Parent=53995c3f42cd8ad8", HeaderTraceID: "trace-std"},
+			wantTrace: "trace-std",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceID, parent := serve(tt.headers)
+			if traceID != tt.wantTrace {
+				t.Errorf("trace ID = %v, want %v", traceID, tt.wantTrace)
+			}
+			if parent != tt.wantParent {
+				t.Errorf("parent span ID = %v, want %v", parent, tt.wantParent)
+			}
+		})
+	}
+
+	t.Run("absent generates", func(t *testing.T) {
+		traceID, parent := serve(nil)
+		if traceID == "" || parent != "" {
+			t.Errorf("trace ID = %q, parent = %q, want generated trace and no parent", traceID, parent)
+		}
+	})
+}
+
+func TestMiddlewareTraceparent(t *testing.T) {
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+		valid    = "00-" + traceID + "-" + parentID + "-01"
+	)
+
+	if err := Init(Config{Service: "test-traceparent", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	serve := func(headers map[string]string) (trace, span, parent string) {
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			trace = TraceID(r.Context())
+			span = SpanID(r.Context())
+			parent = ParentSpanID(r.Context())
+		}))
+		req := httptest.NewRequest("GET", "/test", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return trace, span, parent
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantTrace  string
+		wantParent string
+	}{
+		{
+			name:       "well-formed",
+			headers:    map[string]string{HeaderTraceparent: valid, HeaderTraceID: "trace-std"},
+			wantTrace:  traceID,
+			wantParent: parentID,
+		},
+		{
+			name:       "future version with extra fields",
+			headers:    map[string]string{HeaderTraceparent: "01-" + traceID + "-" + parentID + "-01-extra"},
+			wantTrace:  traceID,
+			wantParent: parentID,
+		},
+		{
+			name:      "uppercase ignored",
+			headers:   map[string]string{HeaderTraceparent: strings.ToUpper(valid), HeaderTraceID: "trace-std"},
+			wantTrace: "trace-std",
+		},
+		{
+			name:      "all-zero trace ID ignored",
+			headers:   map[string]string{HeaderTraceparent: "00-00000000000000000000000000000000-" + parentID + "-01", HeaderTraceID: "trace-std"},
+			wantTrace: "trace-std",
+		},
+		{
+			name:      "version ff ignored",
+			headers:   map[string]string{HeaderTraceparent: "ff" + valid[2:], HeaderTraceID: "trace-std"},
+			wantTrace: "trace-std",
+		},
+		{
+			name:      "version 00 with extra fields ignored",
+			headers:   map[string]string{HeaderTraceparent: valid + "-extra", HeaderTraceID: "trace-std"},
+			wantTrace: "trace-std",
+		},
+		{
+			name:      "truncated ignored",
+			headers:   map[string]string{HeaderTraceparent: valid[:50], HeaderTraceID: "trace-std"},
+			wantTrace: "trace-std",
+		},
+		{
+			name:      "absent uses X-Trace-Id",
+			headers:   map[string]string{HeaderTraceID: "trace-std"},
+			wantTrace: "trace-std",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace, span, parent := serve(tt.headers)
+			if trace != tt.wantTrace {
+				t.Errorf("trace ID = %v, want %v", trace, tt.wantTrace)
+			}
+			if parent != tt.wantParent {
+				t.Errorf("parent span ID = %v, want %v", parent, tt.wantParent)
+			}
+			if len(span) != 16 || span == parentID {
+				t.Errorf("span ID = %q, want a fresh 16-character child span ID", span)
+			}
+		})
+	}
+
+	t.Run("absent generates", func(t *testing.T) {
+		trace, _, parent := serve(nil)
+		if trace == "" || parent != "" {
+			t.Errorf("trace ID = %q, parent = %q, want generated trace and no parent", trace, parent)
+		}
+	})
+}
+
+// panickingHandler panics before writing a response.
+func panickingHandler(w http.ResponseWriter, r *http.Request) {
+	panic("boom")
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	if err := Init(Config{Service: "test-panic", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	serve := func(handler http.Handler) (*httptest.ResponseRecorder, []Event) {
+		rec := httptest.NewRecorder()
+		events := Captured(func() {
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/panic", nil))
+		})
+		return rec, events
+	}
+
+	t.Run("recovers and writes 500", func(t *testing.T) {
+		rec, events := serve(Middleware(http.HandlerFunc(panickingHandler)))
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500", rec.Code)
+		}
+		if len(events) != 1 || events[0].Name != "http.panic" || events[0].Level != LevelError {
+			t.Fatalf("events = %+v, want one error-level http.panic", events)
+		}
+		data := events[0].Data.(map[string]any)
+		if data["panic"] != "boom" || data["request_path"] != "/panic" {
+			t.Errorf("data = %v, want panic boom on /panic", data)
+		}
+		if stack, _ := data["stack"].(string); !strings.HasPrefix(stack, "github.com/aidenappl/go-monitor.panickingHandler\n") {
+			t.Errorf("stack = %q, want it to start at panickingHandler", stack)
+		}
+		if events[0].RequestID == "" {
+			t.Error("http.panic should carry the request ID")
+		}
+	})
+
+	t.Run("keeps a response already started", func(t *testing.T) {
+		rec, events := serve(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("late")
+		})))
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("status = %d, want 202", rec.Code)
+		}
+		if len(events) != 1 || events[0].Name != "http.panic" {
+			t.Errorf("events = %+v, want one http.panic", events)
+		}
+	})
+
+	t.Run("MiddlewareWithConfig reports 500", func(t *testing.T) {
+		rec, events := serve(MiddlewareWithConfig(MiddlewareConfig{})(http.HandlerFunc(panickingHandler)))
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500", rec.Code)
+		}
+		if len(events) != 2 || events[0].Name != "http.panic" || events[1].Name != "http.request" {
+			t.Fatalf("events = %+v, want http.panic then http.request", events)
+		}
+		if status := events[1].Data.(map[string]any)["response_status"]; status != http.StatusInternalServerError {
+			t.Errorf("response_status = %v, want 500", status)
+		}
+	})
+
+	t.Run("ErrAbortHandler passes through", func(t *testing.T) {
+		var events []Event
+		func() {
+			defer func() {
+				if v := recover(); v != http.ErrAbortHandler {
+					t.Errorf("recovered %v, want http.ErrAbortHandler", v)
+				}
+			}()
+			_, events = serve(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic(http.ErrAbortHandler)
+			})))
+		}()
+		if len(events) != 0 {
+			t.Errorf("events = %+v, want none", events)
+		}
+	})
+
+	t.Run("RepanicAfterRecover", func(t *testing.T) {
+		if err := Init(Config{Service: "test-panic", DisableStdout: true, RepanicAfterRecover: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		rec := httptest.NewRecorder()
+		var recovered any
+		events := Captured(func() {
+			defer func() { recovered = recover() }()
+			Middleware(http.HandlerFunc(panickingHandler)).ServeHTTP(rec, httptest.NewRequest("GET", "/panic", nil))
+		})
+		if recovered != "boom" {
+			t.Errorf("recovered %v, want the original panic value", recovered)
+		}
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500 before re-panicking", rec.Code)
+		}
+		if len(events) != 1 || events[0].Name != "http.panic" {
+			t.Errorf("events = %+v, want one http.panic", events)
+		}
+	})
+
+	// A recorder keeps what the handler wrote even when it panics; a real
+	// connection is dropped with anything still buffered
+	for _, repanic := range []bool{false, true} {
+		t.Run(fmt.Sprintf("real server, RepanicAfterRecover=%v", repanic), func(t *testing.T) {
+			var out lockedBuffer
+			m, err := New(Config{Service: "test-panic", Output: &out, EmitHTTPRequests: true, RepanicAfterRecover: repanic})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer m.Shutdown()
+			server := httptest.NewUnstartedServer(m.Middleware(http.HandlerFunc(panickingHandler)))
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			server.Start()
+			defer server.Close()
+
+			resp, err := http.Get(server.URL + "/panic")
+			if err != nil {
+				t.Fatalf("GET error = %v, want a 500 response", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusInternalServerError {
+				t.Errorf("status = %d, want 500", resp.StatusCode)
+			}
+
+			// The events are written as the handler unwinds, after the response
+			deadline := time.Now().Add(5 * time.Second)
+			for len(out.lines()) < 2 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			lines := out.lines()
+			if len(lines) != 2 || !strings.Contains(lines[0], `"http.panic"`) || !strings.Contains(lines[1], `"http.request"`) {
+				t.Errorf("output = %v, want http.panic then http.request", lines)
+			}
+		})
+	}
+}
+
+func TestMiddlewareEmitHTTPRequests(t *testing.T) {
+	serve := func(handler http.HandlerFunc) []Event {
+		return Captured(func() {
+			Middleware(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items", nil))
+		})
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		if err := Init(Config{Service: "test-http-requests", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if events := serve(ok); len(events) != 0 {
+			t.Errorf("events = %+v, want none", events)
+		}
+	})
+
+	if err := Init(Config{Service: "test-http-requests", DisableStdout: true, EmitHTTPRequests: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBytes  int64
+	}{
+		{name: "200", handler: ok, wantStatus: http.StatusOK, wantBytes: 5},
+		{name: "404", handler: http.NotFound, wantStatus: http.StatusNotFound, wantBytes: int64(len("404 page not found\n"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := serve(tt.handler)
+			if len(events) != 1 || events[0].Name != "http.request" || events[0].Level != LevelInfo {
+				t.Fatalf("events = %+v, want one info-level http.request", events)
+			}
+			data := events[0].Data.(map[string]any)
+			if data["request_method"] != "GET" || data["request_path"] != "/items" {
+				t.Errorf("method, path = %v, %v, want GET, /items", data["request_method"], data["request_path"])
+			}
+			if data["response_status"] != tt.wantStatus {
+				t.Errorf("response_status = %v, want %d", data["response_status"], tt.wantStatus)
+			}
+			if data["response_bytes"] != tt.wantBytes {
+				t.Errorf("response_bytes = %v, want %d", data["response_bytes"], tt.wantBytes)
+			}
+			if ms, _ := data["duration_ms"].(int64); ms < 0 {
+				t.Errorf("duration_ms = %v, want non-negative", data["duration_ms"])
+			}
+			if events[0].RequestID == "" {
+				t.Error("http.request should carry the request ID")
+			}
+		})
+	}
+
+	t.Run("Flusher and Hijacker pass through", func(t *testing.T) {
+		var flushed, hijacked atomic.Bool
+		srv := httptest.NewServer(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/stream" {
+				w.(http.Flusher).Flush()
+				flushed.Store(true)
+				return
+			}
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				hijacked.Store(true)
+				conn.Close()
+			}
+		})))
+		defer srv.Close()
+
+		if resp, err := http.Get(srv.URL + "/stream"); err == nil {
+			_, _ = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		_, _ = http.Get(srv.URL + "/upgrade")
+		if !flushed.Load() || !hijacked.Load() {
+			t.Errorf("flushed = %v, hijacked = %v, want both", flushed.Load(), hijacked.Load())
+		}
+	})
+}
+
+func TestMiddlewareEchoResponseHeaders(t *testing.T) {
+	serve := func() (*httptest.ResponseRecorder, string, string) {
+		var requestID, traceID string
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID = RequestID(r.Context())
+			traceID = TraceID(r.Context())
+		}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/test", nil))
+		return rec, requestID, traceID
+	}
+
+	t.Run("echoed by default", func(t *testing.T) {
+		if err := Init(Config{Service: "test-echo", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		rec, requestID, _ := serve()
+		if got := rec.Header().Get(HeaderRequestID); got != requestID {
+			t.Errorf("X-Request-Id = %q, want %q", got, requestID)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		echo := false
+		if err := Init(Config{Service: "test-echo", DisableStdout: true, EchoResponseHeaders: &echo}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		rec, requestID, traceID := serve()
+		if rec.Header().Get(HeaderRequestID) != "" || rec.Header().Get(HeaderTraceID) != "" {
+			t.Errorf("response headers = %v, want no ID headers", rec.Header())
+		}
+		if requestID == "" || traceID == "" {
+			t.Errorf("context IDs = %q/%q, want still set", requestID, traceID)
+		}
+	})
+}
+
+func TestWrapHandler(t *testing.T) {
+	if err := Init(Config{Service: "test-wrap", JobID: "wrap-test-job", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	var gotRequestID, gotTraceID, gotJobID string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		gotRequestID = RequestID(r.Context())
+		gotTraceID = TraceID(r.Context())
+		gotJobID = JobID(r.Context())
+		if r.URL.Path == "/panic" {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	adapters := []struct {
+		name    string
+		wrapped http.Handler
+	}{
+		{"WrapHandler", WrapHandler(http.HandlerFunc(handler))},
+		{"WrapHandlerFunc", WrapHandlerFunc(handler)},
+	}
+	for _, a := range adapters {
+		t.Run(a.name, func(t *testing.T) {
+			t.Run("generates IDs when not present", func(t *testing.T) {
+				gotRequestID, gotTraceID, gotJobID = "", "", ""
+				rec := httptest.NewRecorder()
+				a.wrapped.ServeHTTP(rec, httptest.NewRequest("GET", "/test", nil))
+
+				if gotRequestID == "" || gotTraceID == "" {
+					t.Errorf("IDs = %q/%q, want generated", gotRequestID, gotTraceID)
+				}
+				if gotJobID != "wrap-test-job" {
+					t.Errorf("JobID = %v, want wrap-test-job", gotJobID)
+				}
+				if rec.Header().Get(HeaderRequestID) != gotRequestID {
+					t.Errorf("X-Request-Id = %q, want %q", rec.Header().Get(HeaderRequestID), gotRequestID)
+				}
+				if rec.Header().Get(HeaderTraceID) != gotTraceID {
+					t.Errorf("X-Trace-Id = %q, want %q", rec.Header().Get(HeaderTraceID), gotTraceID)
+				}
+			})
+
+			t.Run("uses existing IDs from headers", func(t *testing.T) {
+				req := httptest.NewRequest("GET", "/test", nil)
+				req.Header.Set(HeaderRequestID, "incoming-request-id")
+				req.Header.Set(HeaderTraceID, "incoming-trace-id")
+				rec := httptest.NewRecorder()
+				a.wrapped.ServeHTTP(rec, req)
+
+				if gotRequestID != "incoming-request-id" || gotTraceID != "incoming-trace-id" {
+					t.Errorf("IDs = %q/%q, want incoming", gotRequestID, gotTraceID)
+				}
+				if rec.Header().Get(HeaderRequestID) != "incoming-request-id" {
+					t.Error("Response X-Request-Id should match incoming")
+				}
+				if rec.Header().Get(HeaderTraceID) != "incoming-trace-id" {
+					t.Error("Response X-Trace-Id should match incoming")
+				}
+			})
+
+			t.Run("recovers panics", func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				events := Captured(func() {
+					a.wrapped.ServeHTTP(rec, httptest.NewRequest("GET", "/panic", nil))
+				})
+				if rec.Code != http.StatusInternalServerError {
+					t.Errorf("status = %d, want 500", rec.Code)
+				}
+				if len(events) != 1 || events[0].Name != "http.panic" {
+					t.Errorf("events = %v, want one http.panic", events)
+				}
+			})
+		})
+	}
+}
+
+func TestPropagateIDs(t *testing.T) {
+	if err := Init(Config{Service: "test-propagate", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	incoming := map[string]string{HeaderRequestID: "req-1", HeaderTraceID: "trace-1", HeaderParentJobID: "job-parent"}
+	echoed := map[string]string{}
+	ctx := PropagateIDs(context.Background(),
+		func(key string) string { return incoming[key] },
+		func(key, value string) { echoed[key] = value },
+	)
+
+	if RequestID(ctx) != "req-1" || TraceID(ctx) != "trace-1" || SpanID(ctx) == "" {
+		t.Errorf("IDs = %q/%q/%q, want req-1/trace-1 and a span ID", RequestID(ctx), TraceID(ctx), SpanID(ctx))
+	}
+	if echoed[HeaderRequestID] != "req-1" || echoed[HeaderTraceID] != "trace-1" {
+		t.Errorf("echoed = %v, want the request and trace IDs", echoed)
+	}
+	if got := ParentJobID(ctx); got != "job-parent" {
+		t.Errorf("ParentJobID = %q, want job-parent", got)
+	}
+
+	// A nil set only skips echoing
+	ctx = PropagateIDs(context.Background(), func(string) string { return "" }, nil)
+	if RequestID(ctx) == "" || TraceID(ctx) == "" {
+		t.Error("PropagateIDs() with nil set did not generate IDs")
+	}
+}
+
+func TestInjectIDs(t *testing.T) {
+	if err := Init(Config{Service: "test-inject", JobID: "inject-job", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	t.Run("generates when missing", func(t *testing.T) {
+		ctx := InjectIDs(context.Background())
+		if RequestID(ctx) == "" || TraceID(ctx) == "" || SpanID(ctx) == "" {
+			t.Errorf("IDs = %q/%q/%q, want all generated", RequestID(ctx), TraceID(ctx), SpanID(ctx))
+		}
+		if JobID(ctx) != "inject-job" {
+			t.Errorf("JobID = %q, want inject-job", JobID(ctx))
+		}
+	})
+
+	t.Run("passes through when present", func(t *testing.T) {
+		ctx := WithTraceID(WithRequestID(context.Background(), "req-1"), "trace-1")
+		ctx = InjectIDs(ctx)
+		if RequestID(ctx) != "req-1" || TraceID(ctx) != "trace-1" {
+			t.Errorf("IDs = %q/%q, want req-1/trace-1", RequestID(ctx), TraceID(ctx))
+		}
+		again := InjectIDs(ctx)
+		if SpanID(again) != SpanID(ctx) {
+			t.Errorf("SpanID changed from %q to %q, want InjectIDs to be idempotent", SpanID(ctx), SpanID(again))
+		}
+	})
+
+	t.Run("matches middleware", func(t *testing.T) {
+		var fromMiddleware context.Context
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fromMiddleware = r.Context()
+		}))
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(HeaderRequestID, "incoming-request-id")
+		req.Header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		requestID, traceID := IDsFromHeaders(req.Header)
+		ctx := InjectIDs(WithTraceID(WithRequestID(context.Background(), requestID), traceID))
+		if RequestID(ctx) != RequestID(fromMiddleware) || TraceID(ctx) != TraceID(fromMiddleware) {
+			t.Errorf("InjectIDs IDs = %q/%q, Middleware IDs = %q/%q",
+				RequestID(ctx), TraceID(ctx), RequestID(fromMiddleware), TraceID(fromMiddleware))
+		}
+	})
+}
+
+func TestIDsFromHeaders(t *testing.T) {
+	if err := Init(Config{Service: "test-ids-from-headers", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		wantRequestID string
+		wantTraceID   string
+	}{
+		{"absent", nil, "", ""},
+		{"plain headers", map[string]string{HeaderRequestID: "req-1", HeaderTraceID: "trace-1"}, "req-1", "trace-1"},
+		{
+			"traceparent wins",
+			map[string]string{
+				HeaderTraceID:     "trace-1",
+				HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			"", "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{"malformed traceparent ignored", map[string]string{HeaderTraceID: "trace-1", HeaderTraceparent: "garbage"}, "", "trace-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+			requestID, traceID := IDsFromHeaders(h)
+			if requestID != tt.wantRequestID || traceID != tt.wantTraceID {
+				t.Errorf("IDsFromHeaders() = %q, %q, want %q, %q", requestID, traceID, tt.wantRequestID, tt.wantTraceID)
+			}
+		})
+	}
+}
+
+func TestMiddlewareCorrelateRequestAndTrace(t *testing.T) {
+	serve := func(headers map[string]string) (string, string) {
+		var requestID, traceID string
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID = RequestID(r.Context())
+			traceID = TraceID(r.Context())
+		}))
+		req := httptest.NewRequest("GET", "/test", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return requestID, traceID
+	}
+
+	if err := Init(Config{Service: "test-correlate", DisableStdout: true, CorrelateRequestAndTrace: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	t.Run("request ID only", func(t *testing.T) {
+		requestID, traceID := serve(map[string]string{HeaderRequestID: "req-only"})
+		if requestID != "req-only" || traceID != "req-only" {
+			t.Errorf("IDs = %q/%q, want req-only for both", requestID, traceID)
+		}
+	})
+
+	t.Run("trace ID only", func(t *testing.T) {
+		requestID, traceID := serve(map[string]string{HeaderTraceID: "trace-only"})
+		if requestID != "trace-only" || traceID != "trace-only" {
+			t.Errorf("IDs = %q/%q, want trace-only for both", requestID, traceID)
+		}
+	})
+
+	t.Run("traceparent only", func(t *testing.T) {
+		requestID, traceID := serve(map[string]string{HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+		if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || requestID != traceID {
+			t.Errorf("IDs = %q/%q, want the traceparent trace ID for both", requestID, traceID)
+		}
+	})
+
+	t.Run("both present", func(t *testing.T) {
+		requestID, traceID := serve(map[string]string{HeaderRequestID: "req-1", HeaderTraceID: "trace-1"})
+		if requestID != "req-1" || traceID != "trace-1" {
+			t.Errorf("IDs = %q/%q, want req-1/trace-1", requestID, traceID)
+		}
+	})
+
+	t.Run("both absent", func(t *testing.T) {
+		requestID, traceID := serve(nil)
+		if requestID == "" || traceID == "" || requestID == traceID {
+			t.Errorf("IDs = %q/%q, want two distinct generated IDs", requestID, traceID)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		if err := Init(Config{Service: "test-correlate", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		requestID, traceID := serve(map[string]string{HeaderRequestID: "req-only"})
+		if requestID != "req-only" || traceID == "req-only" || traceID == "" {
+			t.Errorf("IDs = %q/%q, want req-only and a generated trace ID", requestID, traceID)
+		}
+	})
+}
@@ -2,12 +2,20 @@ package monitor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -20,101 +28,898 @@ type Config struct {
 	// Env is the environment (e.g., "prod", "staging", "dev"). Optional.
 	Env string
 
+	// StaticFields are added as top-level JSON keys to every event (e.g.,
+	// {"tenant_id": "acme"} for an ingest schema that indexes it), not under
+	// data. WithField overrides them per event. Keys that collide with the
+	// event's own fields get a "field_" prefix. Optional.
+	StaticFields map[string]any
+
 	// JobID is an optional override for the process-level job ID.
 	// If empty, one will be auto-generated.
 	JobID string
 
+	// IDFormat sets the format of generated job, request, and trace IDs:
+	// IDFormatUUID, IDFormatUUIDNoHyphen (32 chars), IDFormatHex16,
+	// IDFormatHex32, or IDFormatOTel (32-char trace IDs, 16-char others). Span
+	// IDs are always 16 hex characters. Default: IDFormatUUID.
+	IDFormat string
+
+	// IDGenerator, when set, generates job, request, and trace IDs instead of
+	// IDFormat (e.g., ULIDs, or a counter for deterministic IDs in tests). It
+	// must be safe for concurrent use. Optional.
+	IDGenerator func() string
+
+	// RequestIDGenerator and TraceIDGenerator, when set, generate request and
+	// trace IDs respectively, taking precedence over IDGenerator. Optional.
+	RequestIDGenerator func() string
+	TraceIDGenerator   func() string
+
 	// IngestURL is the URL to POST NDJSON batches to.
-	// If empty, the async shipper is disabled and events only go to stdout.
+	// If empty (and Transport is nil), the async shipper is disabled and events only go to stdout.
 	IngestURL string
 
+	// IngestURLs are more ingest URLs that receive every batch, authenticated
+	// with APIKey. Each endpoint has its own queue, retries, and spill
+	// subdirectory, so a slow or failing one doesn't hold up the others. They
+	// ship alongside IngestURL or Transport, or on their own. Optional.
+	IngestURLs []string
+
+	// Endpoints are more ingest endpoints that receive every batch, each with
+	// its own API key, shipped independently like IngestURLs. Optional.
+	Endpoints []Endpoint
+
+	// Transport, when set, replaces the built-in HTTP POST delivery. Batches are
+	// still assembled by the shipper and handed to Transport.Send. Optional.
+	Transport Transport
+
+	// SpanExporter, when set, receives every span ended with Span.Finish
+	// (e.g., the otel subpackage's OTLP exporter), separately from the event
+	// pipeline. Optional.
+	SpanExporter SpanExporter
+
 	// APIKey is an optional API key for authenticating with the ingest endpoint.
 	APIKey string
 
+	// AuthHeader is the request header that carries APIKey, e.g.,
+	// "Authorization" or "X-Api-Token". The default is "X-Api-Key", with no
+	// AuthScheme, because that is what the shipper has always sent; set
+	// AuthHeader to "Authorization" and AuthScheme to "Bearer" for bearer
+	// tokens. Default: "X-Api-Key".
+	AuthHeader string
+
+	// AuthScheme prefixes APIKey in AuthHeader, separated by a space, e.g.,
+	// "Bearer" to send "Authorization: Bearer <key>". When empty the key is
+	// sent as is. Default: "".
+	AuthScheme string
+
+	// ExtraHeaders are set on every request to the ingest endpoint, e.g., a
+	// tenant or routing header required by a gateway. Content-Type and
+	// AuthHeader take precedence over entries with the same name. Optional.
+	ExtraHeaders map[string]string
+
+	// PartialFailureParser reports which events in a batch the ingest endpoint
+	// rejected, so only those are retried. It is called for 2xx responses.
+	// Default: DefaultPartialFailureParser, which reads {"failed": [indices]}
+	// from 207 Multi-Status responses.
+	PartialFailureParser PartialFailureParser
+
+	// IngestContentType overrides the Content-Type sent with shipped batches
+	// (e.g., "application/jsonlines"). Default: "application/x-ndjson", or
+	// "application/json" with ExportFormatOTLPLogs, or the Encoder's
+	// ContentType if it has one.
+	IngestContentType string
+
+	// ExportFormat selects how batches are encoded for IngestURL:
+	// ExportFormatNDJSON, or ExportFormatOTLPLogs to post OTLP/HTTP JSON logs
+	// straight to an OpenTelemetry Collector. With OTLP, set IngestURL to the
+	// collector's full logs endpoint (e.g., "http://otel-collector:4318/v1/logs").
+	// It does not apply to a custom Transport. Default: ExportFormatNDJSON.
+	ExportFormat string
+
+	// Encoder serializes each event written to Output and shipped in an
+	// NDJSON batch, e.g., LogfmtEncoder for a logfmt aggregator. It can't be
+	// combined with ExportFormatOTLPLogs, and a custom Transport still
+	// receives Events. Default: JSONEncoder.
+	Encoder Encoder
+
+	// IngestProtocol selects the HTTP protocol used to ship batches: ProtocolAuto,
+	// ProtocolHTTP1, ProtocolHTTP2, or ProtocolH2C. HTTP/2 multiplexes flushes
+	// over one connection, which helps behind h2-capable proxies. Default: ProtocolAuto.
+	IngestProtocol string
+
+	// HTTPClient is the client used to post batches to IngestURL, IngestURLs,
+	// and Endpoints, e.g., one with a proxy, custom TLS, or client
+	// certificates. When set, IngestProtocol is ignored and the client's own
+	// Timeout applies. Optional; the default client times out after 30s.
+	HTTPClient *http.Client
+
 	// BatchSize is the maximum number of events per batch. Default: 200.
 	BatchSize int
 
+	// MaxBatchBytes caps the uncompressed NDJSON size of each batch, for
+	// endpoints that reject large payloads. A backlog is split across POSTs to
+	// stay under it, and the shipper flushes early once this many bytes are
+	// buffered. An event larger than the cap is sent on its own.
+	// Default: 0 (no limit).
+	MaxBatchBytes int
+
+	// GroupByTrace splits each batch by trace_id and ships every trace's
+	// events in a separate request, for ingest pipelines that process a
+	// batch faster when it holds a single trace. Events without a trace ID
+	// are shipped together. It trades more requests for locality.
+	// Default: false.
+	GroupByTrace bool
+
+	// QueueSize is how many emitted events can wait for the shipper before new
+	// ones are dropped, tuned independently of BatchSize to absorb bursts.
+	// Must be at least BatchSize. Default: 2 * BatchSize.
+	QueueSize int
+
 	// FlushEvery is how often to flush batches. Default: 1s.
 	FlushEvery time.Duration
 
-	// GzipEnabled enables gzip compression for shipped batches. Default: false.
+	// MaxEventAge bounds how long an event waits in the shipper's buffer: a
+	// flush is triggered once the oldest buffered event is this old, without
+	// waiting for the FlushEvery tick. Set it below FlushEvery to cut tail
+	// latency (e.g., for alerting events) without flushing more often when
+	// idle. Default: 0 (events wait for the tick or a full batch).
+	MaxEventAge time.Duration
+
+	// MaxRetries is how many times a batch is retried after a network error,
+	// 429, or 5xx before it is dropped. Other 4xx responses are never retried.
+	// Negative disables retries. Default: 3.
+	MaxRetries int
+
+	// RetryBaseDelay is the backoff before the first retry; it doubles per
+	// attempt (capped at 30s) with jitter. Default: 1s.
+	RetryBaseDelay time.Duration
+
+	// SpillDir, when set, is a directory where events that overflow the queue
+	// are written as NDJSON instead of being dropped. Spilled events are
+	// replayed, oldest first, once a batch is delivered again; files left by a
+	// previous process are replayed too. Optional.
+	SpillDir string
+
+	// MaxSpillBytes caps the total size of SpillDir; past it the oldest spill
+	// files are deleted and their events reported as dropped. Default: 100 MiB.
+	MaxSpillBytes int64
+
+	// CircuitThreshold enables a circuit breaker on each ingest endpoint:
+	// after this many consecutive failed delivery attempts (network errors,
+	// 429s, 5xxs, or Transport errors), batches skip the endpoint for
+	// CircuitCooldown and are spilled to SpillDir if set, otherwise dropped
+	// (DropReasonCircuitOpen). After the cooldown one batch probes the
+	// endpoint with a single attempt: success resumes delivery, failure
+	// reopens the circuit. Default: 0 (disabled).
+	CircuitThreshold int
+
+	// CircuitCooldown is how long the circuit stays open before probing the
+	// endpoint again. Default: 30s.
+	CircuitCooldown time.Duration
+
+	// MaxEventsPerSecond caps how many events are output per second, across
+	// stdout and shipping, so a runaway loop can't flood either. Excess events
+	// are dropped and reported to OnDrop with DropReasonRateLimited; EmitSync
+	// returns ErrRateLimited for them. Audit events are never limited.
+	// Default: 0 (no limit).
+	MaxEventsPerSecond int
+
+	// RateLimitBurst is how many events may be output at once, above the
+	// steady MaxEventsPerSecond rate, after a quiet period.
+	// Default: MaxEventsPerSecond (one second's worth).
+	RateLimitBurst int
+
+	// DedupWindow, when positive, collapses bursts of identical events (same
+	// name, level, and data; timestamps and IDs are ignored). The first is
+	// emitted as usual, repeats within DedupWindow of it are suppressed, and
+	// when the window closes a copy carrying data._repeat_count, the number
+	// suppressed, is emitted. Up to 10000 distinct events are tracked at once;
+	// others pass through. Audit events and EmitSync are never deduplicated.
+	// Default: 0 (off).
+	DedupWindow time.Duration
+
+	// OnDrop, when set, is called for every event the shipper discards, with
+	// one of the DropReason constants. Use it to count or log lost events.
+	// These reasons are reported on the emitting goroutine:
+	//   - DropReasonBufferFull
+	//   - DropReasonRateLimited
+	//   - DropReasonSchemaViolation
+	//   - DropReasonStdoutFull
+	//   - DropReasonTraceNotSampled
+	//   - DropReasonMarshalError, with RejectUnmarshalable
+	// The rest are reported on the shipper's goroutine. OnDrop is never called
+	// under the shipper's lock and must be safe for concurrent use. It must not
+	// emit events itself: a full buffer would drop them and call it again.
+	// Optional.
+	OnDrop func(event Event, reason string)
+
+	// RejectUnmarshalable marshals each event's data as it is emitted and, if
+	// that fails (e.g., a struct with a channel or func field), drops the
+	// event right away with DropReasonMarshalError, counting it and passing it
+	// to OnDrop, instead of losing it later with only a stderr line. It costs
+	// an extra marshal per event. Default: false.
+	RejectUnmarshalable bool
+
+	// MaxDataBytes caps an event's data as serialized to JSON. Data over the
+	// limit is replaced with {"_truncated": true, "_original_bytes": N} and
+	// the event is still written and shipped, so one oversized payload can't
+	// exhaust memory or the ingest endpoint. Checking costs an extra marshal
+	// per event with data. Audit events are never truncated. 0 means no limit.
+	// Default: 0.
+	MaxDataBytes int
+
+	// OnTruncate, when set, is called on the emitting goroutine for every
+	// event whose data MaxDataBytes replaced, with the truncated event and
+	// the data's original size in bytes. Like OnDrop, it must be safe for
+	// concurrent use and must not emit events itself. Optional.
+	OnTruncate func(event Event, originalBytes int)
+
+	// GzipEnabled enables gzip compression for shipped batches.
+	//
+	// Deprecated: Set Compression to CompressionGzip. GzipEnabled is used only
+	// when Compression is empty. Default: false.
 	GzipEnabled bool
 
-	// DisableStdout disables printing events to stdout. Default: false.
+	// Compression is the algorithm for shipped batches, also sent as the
+	// Content-Encoding header: CompressionNone, CompressionGzip, or
+	// CompressionZstd (import github.com/aidenappl/go-monitor/zstdcompress to
+	// register it), or a name passed to RegisterCompressor.
+	// Default: CompressionNone, or CompressionGzip if GzipEnabled is set.
+	Compression string
+
+	// CompressionLevel is the algorithm-specific level, e.g. 1 (fastest) to 9
+	// (smallest) for gzip. 0 selects the algorithm's default. Default: 0.
+	CompressionLevel int
+
+	// Output is where events are written as NDJSON, e.g., a file or a
+	// bytes.Buffer in tests. Each event is written with a single Write, and
+	// writes are serialized, so the writer need not be safe for concurrent use.
+	// Default: os.Stdout.
+	Output io.Writer
+
+	// DisableStdout disables writing events to Output (stdout by default),
+	// the same as setting Output to io.Discard. Default: false.
 	DisableStdout bool
 
+	// SyncStdout writes each event to stdout before Emit returns, so output
+	// is immediate and ordered with other writes to stdout. Set to false to
+	// write stdout from a background goroutine, flushed every FlushEvery and
+	// on Flush/Shutdown: Emit then never blocks on a slow terminal or pipe,
+	// trading latency (and lines lost on a crash or queue overflow) for
+	// throughput. The shipper is always asynchronous. Default: true.
+	SyncStdout *bool
+
+	// StdoutQueueSize is how many lines may wait for the stdout writer when
+	// SyncStdout is false. Lines beyond it are dropped, counted in Stats, and
+	// reported to OnDrop with DropReasonStdoutFull. Default: 1024.
+	StdoutQueueSize int
+
+	// SyslogNetwork and SyslogAddr, when either is set, also write each event
+	// as an NDJSON line to syslog via log/syslog (e.g., "udp" and
+	// "localhost:514", or "unixgram" and "/dev/log"), in addition to stdout and
+	// the shipper. Messages are tagged with Service and use the user facility,
+	// with severity from the event's level: error is LOG_ERR, warn LOG_WARNING,
+	// info LOG_INFO, debug LOG_DEBUG, fatal LOG_CRIT, and audit LOG_NOTICE.
+	// SyslogNetwork defaults to "udp". Not supported on Windows or Plan 9.
+	// Optional.
+	SyslogNetwork string
+	SyslogAddr    string
+
 	// Debug enables debug-level events. Default: false.
 	Debug bool
 
 	// CaptureSource enables automatic source location capture. Default: true.
 	// Set to false to disable adding source_file, source_line, source_func to events.
 	CaptureSource *bool
+
+	// CaptureCaller sets each event's caller field to the emitting call site
+	// as "dir/file.go:line". It costs a runtime.Caller lookup per event, so it
+	// is off unless enabled. Default: false.
+	CaptureCaller bool
+
+	// CaptureStack adds the caller's stack trace under data.stack to events
+	// emitted with EmitError. Default: false.
+	CaptureStack bool
+
+	// DefaultLevels maps event-name patterns to the level used when the caller
+	// doesn't specify one (e.g., {"*.error": "error", "cache.*": "debug"}).
+	// Patterns support '*' wildcards. An exact name beats any pattern, and among
+	// patterns the most specific (most literal characters) wins. Explicit
+	// WithLevel and the level helpers (Info, Warn, ...) always take precedence.
+	// Init rejects levels other than the Level constants. Optional.
+	DefaultLevels map[string]string
+
+	// StrictSchema drops events that lack a data key required by their
+	// RegisterSchema schema, reporting them to OnDrop with
+	// DropReasonSchemaViolation. When false, such events are emitted with
+	// data._schema_warnings listing the missing keys. Default: false.
+	StrictSchema bool
+
+	// MinLevel drops events below this level before stdout and shipping,
+	// ordering levels debug < info < warn < error < fatal. Events with an
+	// unknown level rank as info, and audit events are never dropped.
+	// Default: "" (all levels are emitted).
+	MinLevel string
+
+	// XRayHeader opts Middleware into AWS X-Ray trace headers (typically
+	// HeaderXRayTraceID). When the header has a valid Root, it becomes the
+	// trace_id and its Parent becomes parent_span_id; otherwise X-Trace-Id is
+	// used as usual. Optional.
+	XRayHeader string
+
+	// CorrelateRequestAndTrace makes Middleware and PropagateIDs use the one
+	// incoming ID for both request_id and trace_id when a request carries a
+	// request ID but no trace ID, or vice versa, instead of generating an
+	// unrelated second ID. Requests with neither get two generated IDs as
+	// usual. Default: false.
+	CorrelateRequestAndTrace bool
+
+	// EchoResponseHeaders controls whether Middleware sets X-Request-Id and
+	// X-Trace-Id on responses. Set to false to keep correlation IDs internal;
+	// they are still stored in the request context and emitted. Default: true.
+	EchoResponseHeaders *bool
+
+	// RepanicAfterRecover makes Middleware re-panic with the original value
+	// after recovering a handler panic, emitting "http.panic", and writing a
+	// 500, so outer recovery (or net/http's own logging) still sees it. The
+	// response is flushed first, since net/http then drops the connection.
+	// When false, the panic ends with the event and the 500. Default: false.
+	RepanicAfterRecover bool
+
+	// EmitHTTPRequests makes Middleware emit an "http.request" event as each
+	// request completes, with method, path, status, duration_ms, and
+	// response_bytes. MiddlewareWithConfig always emits it. Default: false.
+	EmitHTTPRequests bool
+
+	// Processors run in order on every event after it is fully built
+	// (timestamp, service, env, IDs, level, and source location resolved) and
+	// before it is written or shipped. Use them to enrich or rewrite events,
+	// e.g., deriving a field from TraceID. Changing Level also changes the
+	// shipping priority, unless WithPriority set it. Optional.
+	Processors []Processor
+
+	// RedactKeys lists data keys whose values are replaced with "[REDACTED]"
+	// before events are written, shipped, or captured (e.g., "password",
+	// "ssn"). Matching is case-insensitive and applies at any depth, including
+	// maps inside slices. Only map data is redacted, on a copy; the caller's
+	// map is not modified. Optional.
+	RedactKeys []string
+
+	// FlattenData folds nested map[string]any values in event data into dotted
+	// keys (e.g., {"a":{"b":1}} -> {"a.b":1}) for flat-schema backends. Applied
+	// to both stdout and shipped output. When two keys flatten to the same
+	// name, the one that sorts last wins, so a literal "a.b" beats a folded
+	// one. Default: false.
+	FlattenData bool
+
+	// FlattenArrays, with FlattenData, also folds []any values into indexed keys
+	// (e.g., {"tags":["x","y"]} -> {"tags.0":"x","tags.1":"y"}). When false,
+	// arrays are kept as-is. Default: false.
+	FlattenArrays bool
+
+	// EmitConfigOnInit emits a "monitor.config" event at the end of Init with
+	// the effective configuration (after defaults, secrets redacted), so
+	// deployed settings can be audited from the event stream. Unlike other
+	// monitor.* events it is shipped normally. Default: false.
+	EmitConfigOnInit bool
+
+	// SampleRate is the fraction of events to keep, from 0 to 1. Events that
+	// share a trace ID are kept or dropped together. WithSampleRate overrides it
+	// for a context, and a trace-level decision from WithSampled replaces it.
+	// Internal events are never sampled. 0 can't be told apart from unset, so
+	// it also keeps all events; use WithSampleRate(ctx, 0) or MinLevel to drop
+	// them. Default: 1 (keep all).
+	SampleRate float64
+
+	// DefaultSampleRate is the fraction of traces Middleware marks sampled
+	// (see WithSampled) when it doesn't take the decision from the request,
+	// so the whole call tree keeps or drops the trace together: the decision
+	// is forwarded downstream by InjectHeaders, WrapTransport, and
+	// NewTransport. 0 leaves such requests undecided, so SampleRate applies.
+	// Default: 0.
+	DefaultSampleRate float64
+
+	// TrustSampledHeader makes Middleware take the sampling decision from an
+	// incoming HeaderSampled. Enable it only behind services you control:
+	// any client can otherwise send "X-Sampled: 0" to silence its requests or
+	// "1" to bypass SampleRate. Even then, a "not sampled" decision never
+	// drops error, fatal, or audit events. Default: false.
+	TrustSampledHeader bool
+
+	// SampleRates thins out high-volume events by name: an event whose name
+	// is a key is kept at random with that probability (0 to 1), on top of
+	// SampleRate. Kept events carry data._sample_rate, the overall fraction of
+	// that name's events that are kept, so true volumes can be reconstructed.
+	// Names not in the map are unaffected. Optional.
+	SampleRates map[string]float64
+
+	// RuntimeStatsInterval, when positive, emits a "runtime.stats" event
+	// (goroutines, heap, GC counts from runtime.MemStats) at this interval
+	// until Shutdown. runtime.ReadMemStats briefly stops the world, so keep the
+	// interval coarse. Default: 0 (disabled).
+	RuntimeStatsInterval time.Duration
+
+	// TimestampFormat sets how each event's timestamp is written:
+	// TimestampRFC3339Nano (a string), or TimestampUnixMillis or
+	// TimestampUnixNanos (a JSON number). Default: TimestampRFC3339Nano.
+	TimestampFormat string
+
+	// IncludeUptime adds process_uptime_ms, the milliseconds since Init on the
+	// monotonic clock, to every event. It orders a process's events even when
+	// the wall clock jumps. Default: false.
+	IncludeUptime bool
+
+	// IncludeDeadline adds data._deadline_remaining_ms, the time left before
+	// the emitting context's deadline, to events whose context has one, and
+	// data._ctx_err once the context is canceled or past its deadline. It
+	// flags work running close to a timeout. Default: false.
+	IncludeDeadline bool
+
+	// RingBufferSize, when positive, keeps the last RingBufferSize events in
+	// memory for RecentEvents and RecentEventsHandler, whether or not they
+	// are written to stdout or shipped. Default: 0 (disabled).
+	RingBufferSize int
+
+	// HandleSignals makes Init install a SIGINT/SIGTERM handler that calls
+	// Shutdown, so buffered events are flushed when the process is told to
+	// stop, and then re-raises the signal so the process exits as it
+	// otherwise would. If the application also handles these signals with
+	// signal.Notify, it receives both the original and the re-raised signal
+	// and decides when to exit; Shutdown is safe to call again. The handler
+	// is removed by Shutdown and replaced by each Init. Default: false.
+	HandleSignals bool
+
+	// InternalSink receives the SDK's own pipeline-health events (monitor.*)
+	// as NDJSON, keeping them out of the business event stream. When nil they
+	// are written to stdout and shipped like other events, except that one
+	// that can't be shipped is dropped without a report. Optional.
+	InternalSink io.Writer
+
+	// levelRules is DefaultLevels precompiled by Init.
+	levelRules []levelRule
+
+	// redactKeys is RedactKeys lowercased into a set by Init.
+	redactKeys map[string]bool
+
+	// limiter enforces MaxEventsPerSecond, or is nil without a limit.
+	limiter *rateLimiter
+
+	// startedAt is when Init was called, for IncludeUptime.
+	startedAt time.Time
+
+	// output is Output wrapped by Init to serialize writes, or nil for stdout.
+	output io.Writer
 }
 
-// globalConfig stores the initialized configuration atomically.
-var globalConfig atomic.Pointer[Config]
+// Monitor is an independently configured event pipeline with its own
+// config, shipper, output, and shutdown state. The package-level functions
+// (Init, Emit, Flush, ...) use a default Monitor; New creates others, so one
+// process can run several differently configured monitors (e.g., one per
+// tenant). A Monitor is safe for concurrent use.
+type Monitor struct {
+	// config stores the initialized configuration atomically.
+	config atomic.Pointer[Config]
+
+	// shipper stores the active shipper (if any).
+	shipper atomic.Pointer[shipper]
+
+	// stdoutBuffer is the buffered output writer when Config.SyncStdout is
+	// false, or nil when output is unbuffered.
+	stdoutBuffer atomic.Pointer[stdoutBuffer]
+
+	// syslog is the Config.SyslogAddr sink, or nil when disabled.
+	syslog atomic.Pointer[syslogSink]
+
+	// runtimeStats is the running runtime.stats emitter, if enabled.
+	runtimeStats atomic.Pointer[runtimeStatsEmitter]
+
+	// recent holds the last Config.RingBufferSize events, or nil if disabled.
+	recent atomic.Pointer[recentEvents]
+
+	// signals is the installed Config.HandleSignals handler, if enabled.
+	signals atomic.Pointer[signalHandler]
+
+	// dedup suppresses repeated events when Config.DedupWindow is set.
+	dedup atomic.Pointer[deduper]
 
-// globalShipper stores the active shipper (if any).
-var globalShipper atomic.Pointer[shipper]
+	// lastAuditHash is the audit_hash of the most recent audit event, the
+	// link the next audit event chains to.
+	lastAuditHash atomic.Pointer[string]
 
-// ErrNotInitialized is returned when Emit is called before Init.
+	// shutdown is set by Shutdown and cleared by Init. While set, events are dropped.
+	shutdown atomic.Bool
+
+	// hooks holds the functions registered with RegisterShutdownHook.
+	hooks shutdownHooks
+
+	// schemas holds the event schemas registered with RegisterSchema.
+	schemas schemas
+
+	// counts holds the per-name emit counts returned by EventCounts.
+	counts eventCounts
+
+	// unknownLevelOnce limits the unknown-level warning to one per monitor.
+	unknownLevelOnce sync.Once
+}
+
+// defaultMonitor backs the package-level functions. It stays uninitialized
+// until Init is called, so importing the package configures nothing.
+var defaultMonitor = &Monitor{}
+
+// ErrNotInitialized is returned by EmitSync and TryEmit before Init or after
+// Shutdown.
 var ErrNotInitialized = errors.New("monitor: not initialized, call Init first")
 
+// errEventCaptured reports that Captured took an event, so it is not shipped.
+var errEventCaptured = errors.New("monitor: event captured")
+
+// errEventFiltered reports that an event is below Config.MinLevel.
+var errEventFiltered = errors.New("monitor: event below MinLevel")
+
 // ErrServiceRequired is returned when Config.Service is empty.
 var ErrServiceRequired = errors.New("monitor: Config.Service is required")
 
-// Init initializes the monitor with the given configuration.
+// ErrSampled is returned by TryEmit for an event dropped by sampling:
+// SampleRate, SampleRates, WithSampleRate, or a WithSampled decision.
+var ErrSampled = errors.New("monitor: event sampled out")
+
+// ErrUnmarshalable is wrapped by errors returned by TryEmit and EmitSync for
+// an event that could not be encoded.
+var ErrUnmarshalable = errors.New("monitor: failed to marshal event")
+
+// ErrQueueSizeTooSmall is returned when Config.QueueSize is smaller than BatchSize.
+var ErrQueueSizeTooSmall = errors.New("monitor: Config.QueueSize must be at least BatchSize")
+
+// Init initializes the default monitor with the given configuration.
 // Must be called before Emit. Can be called multiple times to reconfigure.
 func Init(cfg Config) error {
+	return defaultMonitor.init(cfg)
+}
+
+// New creates a Monitor independent of the default one and of any other
+// Monitor, configured as by Init. Call its Shutdown when done with it.
+func New(cfg Config) (*Monitor, error) {
+	m := &Monitor{}
+	if err := m.init(cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// validateConfig checks cfg and applies its defaults in place, the checks
+// shared by Init and Verify. It has no side effects beyond cfg.
+func validateConfig(cfg *Config) error {
 	if cfg.Service == "" {
 		return ErrServiceRequired
 	}
 
-	// Apply defaults
-	if cfg.JobID == "" {
-		cfg.JobID = generateShortID()
+	if !validIDFormat(cfg.IDFormat) {
+		return fmt.Errorf("monitor: unknown IDFormat %q", cfg.IDFormat)
+	}
+	if !validTimestampFormat(cfg.TimestampFormat) {
+		return fmt.Errorf("monitor: unknown TimestampFormat %q", cfg.TimestampFormat)
+	}
+	if !validExportFormat(cfg.ExportFormat) {
+		return fmt.Errorf("monitor: unknown ExportFormat %q", cfg.ExportFormat)
+	}
+	if cfg.Encoder != nil && cfg.ExportFormat == ExportFormatOTLPLogs {
+		return errors.New("monitor: Encoder can't be combined with ExportFormatOTLPLogs")
+	}
+	if err := validateCompression(cfg); err != nil {
+		return err
+	}
+	if _, ok := levelRanks[cfg.MinLevel]; cfg.MinLevel != "" && !ok {
+		return fmt.Errorf("monitor: unknown MinLevel %q", cfg.MinLevel)
+	}
+	for pattern, level := range cfg.DefaultLevels {
+		if !knownLevel(level) {
+			return fmt.Errorf("monitor: DefaultLevels[%q] has unknown level %q", pattern, level)
+		}
 	}
 	if cfg.BatchSize <= 0 {
 		cfg.BatchSize = 200
 	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = cfg.BatchSize * 2
+	} else if cfg.QueueSize < cfg.BatchSize {
+		return ErrQueueSizeTooSmall
+	}
 	if cfg.FlushEvery <= 0 {
 		cfg.FlushEvery = time.Second
 	}
+	if cfg.StdoutQueueSize <= 0 {
+		cfg.StdoutQueueSize = 1024
+	}
+	if cfg.SpillDir != "" && cfg.MaxSpillBytes <= 0 {
+		cfg.MaxSpillBytes = defaultMaxSpillBytes
+	}
+	if cfg.RetryBaseDelay <= 0 {
+		cfg.RetryBaseDelay = defaultRetryBaseDelay
+	}
+	if cfg.CircuitThreshold > 0 && cfg.CircuitCooldown <= 0 {
+		cfg.CircuitCooldown = defaultCircuitCooldown
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		return fmt.Errorf("monitor: SampleRate %v must be between 0 and 1", cfg.SampleRate)
+	}
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = 1
+	}
+	if cfg.DefaultSampleRate < 0 || cfg.DefaultSampleRate > 1 {
+		return fmt.Errorf("monitor: DefaultSampleRate %v must be between 0 and 1", cfg.DefaultSampleRate)
+	}
+	for name, rate := range cfg.SampleRates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("monitor: SampleRates[%q] %v must be between 0 and 1", name, rate)
+		}
+	}
+	if _, err := ingestTransport(cfg.IngestProtocol); err != nil {
+		return err
+	}
+	if cfg.IngestURL != "" {
+		if err := validateIngestURL("IngestURL", cfg.IngestURL); err != nil {
+			return err
+		}
+	}
+	for i, u := range cfg.IngestURLs {
+		if u == "" {
+			return fmt.Errorf("monitor: IngestURLs[%d] is empty", i)
+		}
+		if err := validateIngestURL(fmt.Sprintf("IngestURLs[%d]", i), u); err != nil {
+			return err
+		}
+	}
+	for i, ep := range cfg.Endpoints {
+		if ep.URL == "" {
+			return fmt.Errorf("monitor: Endpoints[%d] has no URL", i)
+		}
+		if err := validateIngestURL(fmt.Sprintf("Endpoints[%d].URL", i), ep.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateIngestURL checks that raw, the value of the named Config field, is
+// an absolute http or https URL, so a typo fails Init instead of every flush.
+func validateIngestURL(field, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("monitor: invalid %s %q: %w", field, raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("monitor: invalid %s %q: scheme must be http or https", field, raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("monitor: invalid %s %q: missing host", field, raw)
+	}
+	return nil
+}
+
+// init validates cfg, applies defaults, and (re)starts m's pipeline.
+func (m *Monitor) init(cfg Config) error {
+	if err := validateConfig(&cfg); err != nil {
+		return err
+	}
+	if cfg.SpillDir != "" {
+		if err := os.MkdirAll(cfg.SpillDir, 0o755); err != nil {
+			return fmt.Errorf("monitor: create SpillDir: %w", err)
+		}
+	}
+	if cfg.JobID == "" {
+		cfg.JobID = newID(&cfg)
+	}
+	cfg.SampleRates = maps.Clone(cfg.SampleRates)
+	cfg.levelRules = compileLevelRules(cfg.DefaultLevels)
+	cfg.redactKeys = compileRedactKeys(cfg.RedactKeys)
+	cfg.limiter = newRateLimiter(&cfg)
+	if cfg.Output != nil {
+		cfg.output = &syncWriter{w: cfg.Output}
+	}
+	cfg.startedAt = time.Now()
+	cfg.IngestURLs = slices.Clone(cfg.IngestURLs)
+	cfg.Endpoints = slices.Clone(cfg.Endpoints)
+	var sysl *syslogSink
+	if cfg.SyslogNetwork != "" || cfg.SyslogAddr != "" {
+		var err error
+		if sysl, err = dialSyslog(&cfg); err != nil {
+			return err
+		}
+	}
 
-	// Stop existing shipper if any
-	if oldShipper := globalShipper.Load(); oldShipper != nil {
+	// Stop existing signal handler, runtime stats emitter, shipper, stdout
+	// buffer, and syslog sink if any
+	if oldSignals := m.signals.Swap(nil); oldSignals != nil {
+		oldSignals.stop()
+	}
+	// Emit the old config's pending summaries before its shipper stops
+	if oldDedup := m.dedup.Swap(nil); oldDedup != nil {
+		oldDedup.stop()
+	}
+	if oldStats := m.runtimeStats.Swap(nil); oldStats != nil {
+		oldStats.stop()
+	}
+	if oldShipper := m.shipper.Load(); oldShipper != nil {
 		oldShipper.stop()
 	}
+	if oldBuffer := m.stdoutBuffer.Swap(nil); oldBuffer != nil {
+		oldBuffer.stop()
+	}
+	if oldSyslog := m.syslog.Swap(sysl); oldSyslog != nil {
+		oldSyslog.close()
+	}
+
+	// Keep recorded events across re-Init unless the size changes
+	if cfg.RingBufferSize <= 0 {
+		m.recent.Store(nil)
+	} else if r := m.recent.Load(); r == nil || len(r.events) != cfg.RingBufferSize {
+		m.recent.Store(newRecentEvents(cfg.RingBufferSize))
+	}
 
 	// Store the config
-	globalConfig.Store(&cfg)
+	m.config.Store(&cfg)
+	m.shutdown.Store(false)
 
-	// Start shipper if IngestURL is configured
-	if cfg.IngestURL != "" {
-		s := newShipper(&cfg)
-		globalShipper.Store(s)
+	if cfg.DedupWindow > 0 {
+		m.dedup.Store(newDeduper(m, cfg.DedupWindow))
+	}
+	if !cfg.DisableStdout && !syncStdoutEnabled(&cfg) {
+		m.stdoutBuffer.Store(newStdoutBuffer(baseOutput(&cfg), cfg.FlushEvery, cfg.StdoutQueueSize))
+	}
+
+	// Start shipper if an ingest endpoint or a custom Transport is configured
+	if cfg.IngestURL != "" || cfg.Transport != nil || len(cfg.IngestURLs) > 0 || len(cfg.Endpoints) > 0 {
+		s := newFanoutShipper(m, &cfg)
+		m.shipper.Store(s)
 		s.start()
 	} else {
-		globalShipper.Store(nil)
+		m.shipper.Store(nil)
+	}
+
+	// Enqueued before returning so it ships with the first batch
+	if cfg.EmitConfigOnInit {
+		m.emitInternal(context.Background(), "monitor.config", effectiveConfig(&cfg), LevelInfo)
+	}
+
+	if cfg.RuntimeStatsInterval > 0 {
+		m.runtimeStats.Store(newRuntimeStatsEmitter(m, cfg.RuntimeStatsInterval))
+	}
+
+	if cfg.HandleSignals {
+		m.signals.Store(newSignalHandler(m, raiseSignal))
 	}
 
 	return nil
 }
 
+// IsShutdown reports whether Shutdown has been called since the last Init.
+func IsShutdown() bool {
+	return defaultMonitor.IsShutdown()
+}
+
+// IsShutdown reports whether m has been shut down.
+func (m *Monitor) IsShutdown() bool {
+	return m.shutdown.Load()
+}
+
+// activeConfig returns the config events are emitted with, or nil if m has
+// not been initialized or has been shut down.
+func (m *Monitor) activeConfig() *Config {
+	if m.shutdown.Load() {
+		return nil
+	}
+	return m.config.Load()
+}
+
+// Processor inspects or modifies an event before it is output. It receives
+// the complete event, so enrichment can depend on resolved fields such as
+// TraceID, RequestID, Service, and Env. Processors run on the emitting
+// goroutine and must be safe for concurrent use.
+type Processor func(event *Event)
+
 // EmitOption is a functional option for Emit.
 type EmitOption func(*emitOptions)
 
 type emitOptions struct {
-	level string
+	level       string
+	expiry      time.Duration
+	skipSource  bool
+	links       []Link
+	fields      map[string]any
+	data        map[string]any
+	priority    int
+	hasPriority bool
 }
 
-// WithLevel sets the log level for the event.
+// WithLevel sets the log level for the event. Levels other than the Level
+// constants are replaced with LevelInfo.
 func WithLevel(level string) EmitOption {
 	return func(o *emitOptions) {
 		o.level = level
 	}
 }
 
+// WithExpiry marks the event as valid for d from now by setting expires_at.
+// Intended for state-snapshot events (e.g., "cache.warmed") that ingest
+// systems with TTL support can expire. Non-positive durations are ignored.
+func WithExpiry(d time.Duration) EmitOption {
+	return func(o *emitOptions) {
+		o.expiry = d
+	}
+}
+
+// WithoutSource skips source location and caller capture for this event.
+// Integrations that emit on behalf of the caller (e.g., a database driver
+// wrapper) use it because their own file and line aren't meaningful.
+func WithoutSource() EmitOption {
+	return func(o *emitOptions) {
+		o.skipSource = true
+	}
+}
+
+// WithLink records a link from the event to an external system's trace or
+// request (e.g., WithLink("stripe", "req_123")), emitted in the event's links
+// array. Repeatable; links keep the order they were given.
+func WithLink(system, id string) EmitOption {
+	return func(o *emitOptions) {
+		o.links = append(o.links, Link{System: system, ID: id})
+	}
+}
+
+// WithField adds key as a top-level field of the event's JSON, alongside
+// service, trace_id, and the rest, rather than under data (e.g.,
+// WithField("tenant_id", id)). It overrides a Config.StaticFields entry with
+// the same key. Keys that collide with the event's own fields get a "field_"
+// prefix. Repeatable.
+func WithField(key, value string) EmitOption {
+	return func(o *emitOptions) {
+		if o.fields == nil {
+			o.fields = make(map[string]any)
+		}
+		o.fields[key] = value
+	}
+}
+
+// WithData merges fields into the event's data, so base data and per-call
+// additions combine without building the map by hand. Keys from later
+// WithData options override earlier ones, and all of them override the data
+// argument. Non-map data is kept under data._data. The caller's map is
+// copied, not modified. Repeatable.
+//
+//	monitor.Emit(ctx, "order.placed", base, monitor.WithData(map[string]any{"total": total}))
+func WithData(fields map[string]any) EmitOption {
+	return func(o *emitOptions) {
+		if len(fields) == 0 {
+			return
+		}
+		if o.data == nil {
+			o.data = make(map[string]any, len(fields))
+		}
+		maps.Copy(o.data, fields)
+	}
+}
+
+// applyTo sets option-derived fields on an already constructed event.
+func (o *emitOptions) applyTo(event *Event) {
+	if len(o.data) > 0 {
+		addDataFields(event, o.data)
+	}
+	if len(o.fields) > 0 {
+		if event.Fields == nil {
+			event.Fields = make(map[string]any, len(o.fields))
+		}
+		maps.Copy(event.Fields, o.fields)
+	}
+	if o.expiry > 0 {
+		event.ExpiresAt = time.Now().Add(o.expiry).UTC().Format(time.RFC3339Nano)
+	}
+	event.Links = o.links
+	if o.hasPriority {
+		event.priority = o.priority
+		event.explicitPriority = true
+	}
+}
+
 // captureSourceEnabled returns true if source capture is enabled in the config.
 // Defaults to true when CaptureSource is nil (not explicitly set).
 func captureSourceEnabled(cfg *Config) bool {
@@ -124,6 +929,15 @@ func captureSourceEnabled(cfg *Config) bool {
 	return *cfg.CaptureSource
 }
 
+// echoResponseHeadersEnabled returns true if Middleware should set ID response
+// headers. Defaults to true when EchoResponseHeaders is nil (not explicitly set).
+func echoResponseHeadersEnabled(cfg *Config) bool {
+	if cfg == nil || cfg.EchoResponseHeaders == nil {
+		return true
+	}
+	return *cfg.EchoResponseHeaders
+}
+
 // attachSourceLocation adds source_file, source_line, source_func to the event's
 // data map using runtime.Caller at the given depth.
 func attachSourceLocation(event *Event, callerDepth int) {
@@ -153,78 +967,414 @@ func attachSourceLocation(event *Event, callerDepth int) {
 	event.Data = dataMap
 }
 
+// callerLocation returns the call site at the given runtime.Caller depth as
+// "dir/file.go:line", or "" if it is unavailable.
+func callerLocation(callerDepth int) string {
+	_, file, line, ok := runtime.Caller(callerDepth)
+	if !ok {
+		return ""
+	}
+	dir, name := filepath.Split(file)
+	return filepath.Base(dir) + "/" + name + ":" + strconv.Itoa(line)
+}
+
 // Emit emits a monitoring event with the given name and data.
 // The event will always contain: job_id, request_id, trace_id, service, timestamp.
 // If any ID is missing from the context, it will be generated.
+// data may be a map[string]any or any JSON-marshalable value, such as a
+// struct with json tags.
 func Emit(ctx context.Context, name string, data any, opts ...EmitOption) {
-	cfg := globalConfig.Load()
+	defaultMonitor.emitWithOptions(ctx, name, data, opts, 2)
+}
+
+// Emit emits an event through m, like the package-level Emit.
+func (m *Monitor) Emit(ctx context.Context, name string, data any, opts ...EmitOption) {
+	m.emitWithOptions(ctx, name, data, opts, 2)
+}
+
+// TryEmit emits an event like Emit, but reports whether the pipeline
+// accepted it: nil once it has been written to stdout and queued for
+// shipping (or deliberately filtered by MinLevel or DedupWindow), otherwise
+// why it was rejected. Rejections are ErrNotInitialized, ErrSampled,
+// ErrRateLimited, ErrBufferFull, or errors wrapping ErrSchemaViolation or
+// ErrUnmarshalable; compare with errors.Is. Acceptance is not delivery: use
+// EmitSync to wait for the ingest endpoint.
+func TryEmit(ctx context.Context, name string, data any, opts ...EmitOption) error {
+	return defaultMonitor.emitWithOptions(ctx, name, data, opts, 2)
+}
+
+// TryEmit emits an event through m, like the package-level TryEmit.
+func (m *Monitor) TryEmit(ctx context.Context, name string, data any, opts ...EmitOption) error {
+	return m.emitWithOptions(ctx, name, data, opts, 2)
+}
+
+// emitWithOptions implements Emit and TryEmit for callers at the given depth,
+// so wrappers like EmitError attribute the event to their own caller.
+func (m *Monitor) emitWithOptions(ctx context.Context, name string, data any, opts []EmitOption, callerDepth int) error {
+	cfg := m.activeConfig()
 	if cfg == nil {
-		return
+		return ErrNotInitialized
+	}
+	m.counts.add(name)
+	if !m.hasSinks(cfg) {
+		nextRequestSeq(ctx)
+		return nil
 	}
 
-	// Apply options
-	o := &emitOptions{level: "info"}
+	// Apply options; sampling depends on the level
+	o := &emitOptions{}
 	for _, opt := range opts {
 		opt(o)
 	}
+	o.level = m.checkLevel(o.level)
+	if o.level == "" {
+		o.level = defaultLevelFor(cfg, name)
+	}
+
+	nameRate := nameSampleRate(cfg, name)
+	if m.traceUnsampled(ctx, cfg, name, data, o.level) || !sampled(ctx, cfg, o.level) || !sampledAt(nameRate) {
+		nextRequestSeq(ctx)
+		return ErrSampled
+	}
 
 	// Create the event
-	event := newEvent(ctx, name, data, o.level)
+	event := newEvent(ctx, cfg, name, data, o.level)
+	o.applyTo(&event)
+	if nameRate < 1 {
+		annotateSampleRate(&event, nameRate*sampleRate(ctx, cfg))
+	}
 
 	// Attach source location if enabled
-	if captureSourceEnabled(cfg) {
-		attachSourceLocation(&event, 2)
+	if captureSourceEnabled(cfg) && !o.skipSource {
+		attachSourceLocation(&event, callerDepth+1)
+	}
+	if cfg.CaptureCaller && !o.skipSource {
+		event.Caller = callerLocation(callerDepth + 1)
 	}
 
-	dispatchEvent(event)
+	return m.dispatchEvent(event)
 }
 
 // emitWithCallerDepth is used by convenience functions (Info, Warn, etc.) to emit
 // events with the correct caller depth for source location capture.
-func emitWithCallerDepth(ctx context.Context, name string, data any, level string, callerDepth int) {
-	cfg := globalConfig.Load()
+func (m *Monitor) emitWithCallerDepth(ctx context.Context, name string, data any, level string, callerDepth int) {
+	cfg := m.activeConfig()
 	if cfg == nil {
 		return
 	}
+	m.counts.add(name)
+	nameRate := nameSampleRate(cfg, name)
+	if !m.hasSinks(cfg) || m.traceUnsampled(ctx, cfg, name, data, level) || !sampled(ctx, cfg, level) || !sampledAt(nameRate) {
+		nextRequestSeq(ctx)
+		return
+	}
 
-	event := newEvent(ctx, name, data, level)
+	event := newEvent(ctx, cfg, name, data, level)
+	if nameRate < 1 {
+		annotateSampleRate(&event, nameRate*sampleRate(ctx, cfg))
+	}
 
 	if captureSourceEnabled(cfg) {
 		attachSourceLocation(&event, callerDepth+1)
 	}
+	if cfg.CaptureCaller {
+		event.Caller = callerLocation(callerDepth + 1)
+	}
 
-	dispatchEvent(event)
+	m.dispatchEvent(event)
 }
 
-// dispatchEvent handles stdout output and shipper send for an event.
-func dispatchEvent(event Event) {
-	cfg := globalConfig.Load()
+// hasSinks reports whether an emitted event would be written anywhere or seen
+// by a processor. When it is false, Emit skips building the event entirely so
+// discard mode (e.g., a library whose host never configured output) costs next
+// to nothing.
+func (m *Monitor) hasSinks(cfg *Config) bool {
+	return !cfg.DisableStdout || m.shipper.Load() != nil || len(cfg.Processors) > 0 ||
+		m.recent.Load() != nil || globalCapture.Load() != nil || m.syslog.Load() != nil
+}
+
+// dispatchEvent runs processors, then handles stdout output and shipper send
+// for an event. It returns why the event was rejected, for TryEmit.
+func (m *Monitor) dispatchEvent(event Event) error {
+	cfg := m.activeConfig()
 	if cfg == nil {
-		return
+		return ErrNotInitialized
+	}
+	if d := m.dedup.Load(); d != nil && d.suppress(&event) {
+		return nil
+	}
+	return m.deliverEvent(cfg, event)
+}
+
+// deliverEvent outputs and ships event, bypassing DedupWindow.
+func (m *Monitor) deliverEvent(cfg *Config, event Event) error {
+	if err := m.outputEvent(cfg, &event); err != nil {
+		if err == errEventCaptured || err == errEventFiltered {
+			return nil
+		}
+		return err
+	}
+	if s := m.shipper.Load(); s != nil {
+		return s.send(event)
+	}
+	return nil
+}
+
+// outputEvent finishes event and writes it to stdout and syslog. Finishing
+// runs the processors, redaction, the schema check, flattening, data
+// truncation, and the audit chain, in that order. A non-nil error means the
+// event must not be shipped. It is errEventFiltered for an event below
+// MinLevel, errEventCaptured for one Captured took, a schema violation,
+// ErrRateLimited, or why the event failed to marshal.
+func (m *Monitor) outputEvent(cfg *Config, event *Event) error {
+	if belowMinLevel(cfg, event.Level) {
+		return errEventFiltered
+	}
+	for _, process := range cfg.Processors {
+		process(event)
+	}
+	// A processor may have changed the level the priority was derived from
+	if !event.explicitPriority {
+		event.priority = levelPriority(event.Level)
+	}
+	if cfg.redactKeys != nil {
+		event.Data = redactData(event.Data, cfg.redactKeys)
+	}
+	// Checked before flattening, which renames nested keys
+	if err := m.checkSchema(cfg, event); err != nil {
+		return err
+	}
+	if cfg.FlattenData {
+		event.Data = flattenData(event.Data, cfg.FlattenArrays)
+	}
+	if event.Level != LevelAudit && !cfg.limiter.allow() {
+		m.dropUnshipped(cfg, *event, DropReasonRateLimited)
+		return ErrRateLimited
+	}
+	if cfg.RejectUnmarshalable {
+		if err := m.checkMarshalable(cfg, event); err != nil {
+			return err
+		}
+	}
+	// Audit data is kept whole so the chain vouches for what was recorded
+	if cfg.MaxDataBytes > 0 && event.Level != LevelAudit {
+		truncateData(cfg, event)
+	}
+	// Hashed last, so the chain covers the event exactly as it is output
+	if event.Level == LevelAudit {
+		m.chainAudit(event)
+	}
+	if r := m.recent.Load(); r != nil {
+		r.add(*event)
+	}
+	if c := globalCapture.Load(); c != nil {
+		c.add(*event)
+		return errEventCaptured
+	}
+	sysl := m.syslog.Load()
+	if cfg.DisableStdout && sysl == nil {
+		return nil
+	}
+	line, err := encodeEvent(cfg, *event)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: failed to marshal event: %v\n", err)
+		return fmt.Errorf("%w: %w", ErrUnmarshalable, err)
 	}
 	if !cfg.DisableStdout {
-		if _, err := event.ToJSON(); err != nil {
-			fmt.Fprintf(os.Stderr, "monitor: failed to marshal event: %v\n", err)
+		if b := m.stdoutBuffer.Load(); b != nil {
+			if !b.enqueue(ndjsonLine(line)) {
+				m.dropUnshipped(cfg, *event, DropReasonStdoutFull)
+			}
+		} else {
+			writeLine(baseOutput(cfg), line)
+		}
+	}
+	if sysl != nil {
+		sysl.write(event.Level, line)
+	}
+	return nil
+}
+
+// checkMarshalable drops event with DropReasonMarshalError if its data can't
+// be marshaled, for Config.RejectUnmarshalable.
+func (m *Monitor) checkMarshalable(cfg *Config, event *Event) error {
+	if _, err := json.Marshal(event.Data); err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: rejecting event %q: failed to marshal data: %v\n", event.Name, err)
+		m.dropUnshipped(cfg, *event, DropReasonMarshalError)
+		return fmt.Errorf("%w data: %w", ErrUnmarshalable, err)
+	}
+	return nil
+}
+
+// truncateData replaces event's data with a truncation marker if it
+// marshals to more than Config.MaxDataBytes, and reports it to OnTruncate.
+// Data that can't be marshaled is left for the encoder to report.
+func truncateData(cfg *Config, event *Event) {
+	if event.Data == nil {
+		return
+	}
+	b, err := json.Marshal(event.Data)
+	if err != nil || len(b) <= cfg.MaxDataBytes {
+		return
+	}
+	event.Data = map[string]any{"_truncated": true, "_original_bytes": len(b)}
+	if cfg.OnTruncate != nil {
+		cfg.OnTruncate(*event, len(b))
+	}
+}
+
+// dropUnshipped reports an event dropped before reaching the shipper: it is
+// counted in Stats and passed to OnDrop as if the shipper had discarded it.
+func (m *Monitor) dropUnshipped(cfg *Config, event Event, reason string) {
+	if s := m.shipper.Load(); s != nil {
+		s.dropped(reason, event)
+	} else if cfg.OnDrop != nil {
+		cfg.OnDrop(event, reason)
+	}
+}
+
+// emitSelf emits one of the SDK's own pipeline-health events. These go to
+// Config.InternalSink when set, otherwise to stdout and the shipper. They
+// are marked internal so a struggling pipeline drops them rather than
+// reporting their loss and feeding itself.
+func (m *Monitor) emitSelf(name string, data map[string]any, level string) {
+	cfg := m.config.Load()
+	if cfg == nil || belowMinLevel(cfg, level) {
+		return
+	}
+
+	event := newEvent(context.Background(), cfg, name, data, level)
+	event.internal = true
+
+	out := cfg.InternalSink
+	if out == nil {
+		if s := m.shipper.Load(); s != nil {
+			_ = s.send(event)
+		}
+		if cfg.DisableStdout {
 			return
 		}
+		out = m.stdoutTarget()
 	}
-	if s := globalShipper.Load(); s != nil {
-		s.send(event)
+
+	line, err := encodeEvent(cfg, event)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: failed to marshal event: %v\n", err)
+		return
 	}
+	writeLine(out, line)
+}
+
+// writeLine writes an NDJSON line with a single Write call so concurrent
+// writers don't interleave partial lines.
+func writeLine(w io.Writer, line []byte) {
+	if _, err := w.Write(ndjsonLine(line)); err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: failed to write event: %v\n", err)
+	}
+}
+
+// ndjsonLine returns a copy of line terminated by a newline.
+func ndjsonLine(line []byte) []byte {
+	buf := make([]byte, 0, len(line)+1)
+	buf = append(buf, line...)
+	return append(buf, '\n')
 }
 
 // Flush flushes any buffered events to the ingest endpoint.
 // This is useful to call before application shutdown.
 func Flush() {
-	if s := globalShipper.Load(); s != nil {
+	defaultMonitor.Flush()
+}
+
+// Flush flushes m's buffered events, like the package-level Flush.
+func (m *Monitor) Flush() {
+	if b := m.stdoutBuffer.Load(); b != nil {
+		b.flush(context.Background())
+	}
+	if s := m.shipper.Load(); s != nil {
 		s.flush()
 	}
 }
 
-// Shutdown gracefully shuts down the monitor, flushing any remaining events.
+// FlushContext is like Flush but gives up when ctx is done, returning
+// ctx.Err(). Retries against a failing endpoint back off with jitter and stop
+// at the deadline, so a batch job's exit isn't delayed unbounded. Events that
+// weren't delivered in time stay buffered for the next flush.
+func FlushContext(ctx context.Context) error {
+	return defaultMonitor.FlushContext(ctx)
+}
+
+// FlushContext flushes m's buffered events, like the package-level FlushContext.
+func (m *Monitor) FlushContext(ctx context.Context) error {
+	if b := m.stdoutBuffer.Load(); b != nil {
+		if err := b.flush(ctx); err != nil {
+			return err
+		}
+	}
+	if s := m.shipper.Load(); s != nil {
+		return s.flushContext(ctx)
+	}
+	return nil
+}
+
+// Shutdown gracefully shuts down the monitor, flushing any remaining events
+// and then running shutdown hooks. Hook errors are written to stderr; use
+// ShutdownContext to receive them.
+//
+// After Shutdown, Emit and its variants are no-ops (nothing is written to
+// stdout or shipped) until Init is called again. IsShutdown reports this state.
 func Shutdown() {
-	if s := globalShipper.Load(); s != nil {
-		s.stop()
-		globalShipper.Store(nil)
+	defaultMonitor.Shutdown()
+}
+
+// Shutdown shuts down m like the package-level Shutdown. Other monitors,
+// including the default one, keep running.
+func (m *Monitor) Shutdown() {
+	if err := m.ShutdownContext(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: shutdown: %v\n", err)
+	}
+}
+
+// ShutdownContext shuts down the monitor like Shutdown, passing ctx to the
+// hooks registered with RegisterShutdownHook and returning their errors joined.
+// The final flush gives up when ctx is done, so an unreachable endpoint can't
+// hang shutdown: events it couldn't deliver by then are dropped (reported with
+// DropReasonShutdown) and ctx.Err() is returned.
+func ShutdownContext(ctx context.Context) error {
+	return defaultMonitor.ShutdownContext(ctx)
+}
+
+// ShutdownContext shuts down m like the package-level ShutdownContext,
+// running the hooks registered with m.RegisterShutdownHook.
+func (m *Monitor) ShutdownContext(ctx context.Context) error {
+	// Pending summaries are emitted while the monitor still accepts events
+	if d := m.dedup.Swap(nil); d != nil {
+		d.stop()
+	}
+	m.shutdown.Store(true)
+	if h := m.signals.Swap(nil); h != nil {
+		h.stop()
+	}
+	if r := m.runtimeStats.Swap(nil); r != nil {
+		r.stop()
+	}
+	var stopErr error
+	if s := m.shipper.Load(); s != nil {
+		stopErr = s.stopContext(ctx)
+		m.shipper.Store(nil)
+	}
+	if b := m.stdoutBuffer.Swap(nil); b != nil {
+		b.stop()
+	}
+	if sysl := m.syslog.Swap(nil); sysl != nil {
+		sysl.close()
+	}
+	hookErr := m.hooks.run(ctx)
+	if stopErr == nil {
+		return hookErr
+	}
+	if hookErr == nil {
+		return stopErr
 	}
+	return errors.Join(stopErr, hookErr)
 }
@@ -1,12 +1,21 @@
 package monitor
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestInit(t *testing.T) {
@@ -69,38 +78,6 @@ func TestContextHelpers(t *testing.T) {
 	}
 }
 
-func TestGenerateID(t *testing.T) {
-	id := generateID()
-	// UUID format: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx (36 chars)
-	if len(id) != 36 {
-		t.Errorf("generateID() length = %d, want 36 (UUID format)", len(id))
-	}
-	// Check UUID format with hyphens
-	if id[8] != '-' || id[13] != '-' || id[18] != '-' || id[23] != '-' {
-		t.Errorf("generateID() = %v, want UUID format", id)
-	}
-
-	// Should be unique
-	id2 := generateID()
-	if id == id2 {
-		t.Error("generateID() should generate unique IDs")
-	}
-}
-
-func TestGenerateShortID(t *testing.T) {
-	id := generateShortID()
-	// Now uses same UUID format as generateID
-	if len(id) != 36 {
-		t.Errorf("generateShortID() length = %d, want 36 (UUID format)", len(id))
-	}
-
-	// Should be unique
-	id2 := generateShortID()
-	if id == id2 {
-		t.Error("generateShortID() should generate unique IDs")
-	}
-}
-
 func TestEvent(t *testing.T) {
 	// Initialize monitor first
 	if err := Init(Config{Service: "test-service", Env: "test", JobID: "test-job"}); err != nil {
@@ -111,7 +88,7 @@ func TestEvent(t *testing.T) {
 	ctx = WithRequestID(ctx, "req-123")
 	ctx = WithTraceID(ctx, "trace-456")
 
-	event := newEvent(ctx, "test.event", map[string]any{"key": "value"}, "info")
+	event := newEvent(ctx, defaultMonitor.config.Load(), "test.event", map[string]any{"key": "value"}, "info")
 
 	// Check required fields
 	if event.Service != "test-service" {
@@ -174,7 +151,7 @@ func TestEventMissingIDs(t *testing.T) {
 
 	// Event with no IDs in context should have job_id from config only
 	ctx := context.Background()
-	event := newEvent(ctx, "test.event", nil, "")
+	event := newEvent(ctx, defaultMonitor.config.Load(), "test.event", nil, "")
 
 	if event.JobID != "global-job" {
 		t.Errorf("event.JobID = %v, want global-job (from config)", event.JobID)
@@ -197,7 +174,7 @@ func TestEventOmitsEmptyIDs(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	event := newEvent(ctx, "test.event", nil, "info")
+	event := newEvent(ctx, defaultMonitor.config.Load(), "test.event", nil, "info")
 
 	jsonBytes, err := event.ToJSON()
 	if err != nil {
@@ -294,6 +271,113 @@ func TestMiddleware(t *testing.T) {
 	})
 }
 
+func TestMiddlewareAppliedTwice(t *testing.T) {
+	if err := Init(Config{Service: "test-service", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	var outerRequestID, outerTraceID string
+	var gotRequestID, gotTraceID string
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestID = RequestID(r.Context())
+		gotTraceID = TraceID(r.Context())
+	})
+
+	// Record what the outer layer set before the inner layer runs
+	inner := Middleware(handler)
+	probe := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outerRequestID = RequestID(r.Context())
+		outerTraceID = TraceID(r.Context())
+		inner.ServeHTTP(w, r)
+	})
+	wrapped := Middleware(probe)
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, req)
+
+	if outerRequestID == "" || outerTraceID == "" {
+		t.Fatal("outer middleware should set IDs")
+	}
+	if gotRequestID != outerRequestID {
+		t.Errorf("RequestID = %v, want outer %v", gotRequestID, outerRequestID)
+	}
+	if gotTraceID != outerTraceID {
+		t.Errorf("TraceID = %v, want outer %v", gotTraceID, outerTraceID)
+	}
+	if rec.Header().Get(HeaderRequestID) != outerRequestID {
+		t.Errorf("X-Request-Id = %v, want outer %v", rec.Header().Get(HeaderRequestID), outerRequestID)
+	}
+	if rec.Header().Get(HeaderTraceID) != outerTraceID {
+		t.Errorf("X-Trace-Id = %v, want outer %v", rec.Header().Get(HeaderTraceID), outerTraceID)
+	}
+}
+
+func TestSpanID(t *testing.T) {
+	if err := Init(Config{Service: "test-span", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	t.Run("context helpers", func(t *testing.T) {
+		ctx := context.Background()
+		if got := SpanID(ctx); got != "" {
+			t.Errorf("SpanID(empty ctx) = %v, want empty", got)
+		}
+		ctx = WithSpanID(ctx, "00f067aa0ba902b7")
+		if got := SpanID(ctx); got != "00f067aa0ba902b7" {
+			t.Errorf("SpanID() = %v, want 00f067aa0ba902b7", got)
+		}
+	})
+
+	t.Run("generated format", func(t *testing.T) {
+		id := generateSpanID()
+		if len(id) != 16 {
+			t.Fatalf("generateSpanID() length = %d, want 16", len(id))
+		}
+		if _, err := hex.DecodeString(id); err != nil {
+			t.Errorf("generateSpanID() = %v, want hex: %v", id, err)
+		}
+		if id == generateSpanID() {
+			t.Error("generateSpanID() should generate unique IDs")
+		}
+	})
+
+	t.Run("middleware generates span per request", func(t *testing.T) {
+		var spans []string
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			spans = append(spans, SpanID(r.Context()))
+		}))
+
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set(HeaderTraceID, "shared-trace")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		if len(spans[0]) != 16 || len(spans[1]) != 16 {
+			t.Fatalf("spans = %v, want 16-hex IDs", spans)
+		}
+		if spans[0] == spans[1] {
+			t.Error("each request should get a distinct span ID")
+		}
+	})
+
+	t.Run("span_id on event", func(t *testing.T) {
+		event := newEvent(WithSpanID(context.Background(), "00f067aa0ba902b7"), defaultMonitor.config.Load(), "test.span", nil, "info")
+		jsonBytes, _ := event.ToJSON()
+		if !strings.Contains(string(jsonBytes), `"span_id":"00f067aa0ba902b7"`) {
+			t.Errorf("JSON should contain span_id, got %s", jsonBytes)
+		}
+
+		event = newEvent(context.Background(), defaultMonitor.config.Load(), "test.span", nil, "info")
+		jsonBytes, _ = event.ToJSON()
+		if strings.Contains(string(jsonBytes), "span_id") {
+			t.Errorf("JSON should omit empty span_id, got %s", jsonBytes)
+		}
+	})
+}
+
 func TestEmitWithLevel(t *testing.T) {
 	if err := Init(Config{Service: "test-service", DisableStdout: true}); err != nil {
 		t.Fatalf("Init() error = %v", err)
@@ -309,13 +393,53 @@ func TestEmitWithLevel(t *testing.T) {
 
 func TestEmitBeforeInit(t *testing.T) {
 	// Reset global config
-	globalConfig.Store(nil)
+	defaultMonitor.config.Store(nil)
 
 	// Should not panic when called before Init
 	ctx := context.Background()
 	Emit(ctx, "test.event", nil) // Should be a no-op
 }
 
+func TestEmitAfterShutdown(t *testing.T) {
+	out := captureStdout(t)
+	rt := &recordingTransport{}
+	if err := Init(Config{Service: "test-after-shutdown", Transport: rt}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if IsShutdown() {
+		t.Fatal("IsShutdown() = true after Init, want false")
+	}
+
+	Emit(context.Background(), "test.before", nil)
+	Shutdown()
+	if !IsShutdown() {
+		t.Fatal("IsShutdown() = false after Shutdown, want true")
+	}
+
+	Emit(context.Background(), "test.after", nil)
+	Info(context.Background(), "test.after", nil)
+	Flush()
+
+	if lines := out.lines(); len(lines) != 1 || !strings.Contains(lines[0], "test.before") {
+		t.Errorf("stdout lines = %v, want only test.before", lines)
+	}
+	if got := rt.eventCount(); got != 1 {
+		t.Errorf("transport received %d events, want 1", got)
+	}
+
+	// Init re-enables emitting
+	if err := Init(Config{Service: "test-after-shutdown"}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if IsShutdown() {
+		t.Error("IsShutdown() = true after re-Init, want false")
+	}
+	Emit(context.Background(), "test.reinit", nil)
+	if lines := out.lines(); len(lines) != 2 {
+		t.Errorf("stdout lines = %v, want test.reinit written after re-Init", lines)
+	}
+}
+
 func TestEventJSONFormat(t *testing.T) {
 	if err := Init(Config{Service: "json-test", Env: "test", JobID: "json-job"}); err != nil {
 		t.Fatalf("Init() error = %v", err)
@@ -324,7 +448,7 @@ func TestEventJSONFormat(t *testing.T) {
 	ctx := WithRequestID(context.Background(), "json-req")
 	ctx = WithTraceID(ctx, "json-trace")
 
-	event := newEvent(ctx, "json.test", map[string]any{
+	event := newEvent(ctx, defaultMonitor.config.Load(), "json.test", map[string]any{
 		"string": "value",
 		"number": 123,
 		"bool":   true,
@@ -371,3 +495,1051 @@ func TestEventJSONFormat(t *testing.T) {
 		t.Errorf("data.string = %v, want value", data["string"])
 	}
 }
+
+func TestParentJobID(t *testing.T) {
+	if err := Init(Config{Service: "test-parent-job", JobID: "job-parent"}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	cfg := defaultMonitor.config.Load()
+
+	ctx := WithParentJobID(WithJobID(context.Background(), "job-child"), "job-parent")
+	jsonBytes, err := newEvent(ctx, cfg, "job.started", nil, LevelInfo).ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(jsonBytes, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded["parent_job_id"] != "job-parent" || decoded["job_id"] != "job-child" {
+		t.Errorf("parent_job_id/job_id = %v/%v, want job-parent/job-child", decoded["parent_job_id"], decoded["job_id"])
+	}
+
+	jsonBytes, err = newEvent(context.Background(), cfg, "job.started", nil, LevelInfo).ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	if strings.Contains(string(jsonBytes), "parent_job_id") {
+		t.Errorf("event JSON = %s, want parent_job_id omitted when unset", jsonBytes)
+	}
+}
+
+func TestUserAndSessionID(t *testing.T) {
+	if err := Init(Config{Service: "test-session"}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	cfg := defaultMonitor.config.Load()
+
+	ctx := WithSessionID(WithUserID(context.Background(), "user-1"), "sess-1")
+	if got := SessionID(ctx); got != "sess-1" {
+		t.Errorf("SessionID() = %q, want sess-1", got)
+	}
+	jsonBytes, err := newEvent(ctx, cfg, "page.viewed", nil, LevelInfo).ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(jsonBytes, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded["user_id"] != "user-1" || decoded["session_id"] != "sess-1" {
+		t.Errorf("user_id/session_id = %v/%v, want user-1/sess-1", decoded["user_id"], decoded["session_id"])
+	}
+
+	jsonBytes, err = newEvent(context.Background(), cfg, "page.viewed", nil, LevelInfo).ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	if strings.Contains(string(jsonBytes), "user_id") || strings.Contains(string(jsonBytes), "session_id") {
+		t.Errorf("event JSON = %s, want user_id and session_id omitted when unset", jsonBytes)
+	}
+
+	t.Run("written by Emit", func(t *testing.T) {
+		var out lockedBuffer
+		m, err := New(Config{Service: "test-session", Output: &out, CaptureSource: new(bool)})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer m.Shutdown()
+
+		m.Emit(ctx, "user.login", nil)
+		m.Flush()
+
+		lines := out.lines()
+		if len(lines) != 1 {
+			t.Fatalf("got %d lines, want 1", len(lines))
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if decoded["user_id"] != "user-1" || decoded["session_id"] != "sess-1" {
+			t.Errorf("line = %s, want user_id user-1 and session_id sess-1", lines[0])
+		}
+	})
+}
+
+func TestWithFields(t *testing.T) {
+	if err := Init(Config{Service: "test-with-fields", DisableStdout: true, CaptureSource: new(bool)}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	parent := WithFields(context.Background(), map[string]any{"tenant_id": "t-1", "region": "us"})
+	ctx := WithFields(parent, map[string]any{"region": "eu", "plan": "pro"})
+
+	t.Run("accumulates", func(t *testing.T) {
+		want := map[string]any{"tenant_id": "t-1", "region": "eu", "plan": "pro"}
+		if got := ContextFields(ctx); !maps.Equal(got, want) {
+			t.Errorf("ContextFields() = %v, want %v", got, want)
+		}
+		if got := ContextFields(parent)["region"]; got != "us" {
+			t.Errorf("parent region = %v, want us (parent must not be mutated)", got)
+		}
+		if got := ContextFields(context.Background()); got != nil {
+			t.Errorf("ContextFields(empty ctx) = %v, want nil", got)
+		}
+	})
+
+	t.Run("merged into data", func(t *testing.T) {
+		data := map[string]any{"amount": 120, "plan": "free"}
+		events := Captured(func() {
+			Emit(ctx, "test.fields", data)
+			Emit(ctx, "test.fields.nil", nil)
+			Emit(ctx, "test.fields.scalar", 7)
+		})
+		if len(events) != 3 {
+			t.Fatalf("captured %d events, want 3", len(events))
+		}
+
+		got := events[0].Data.(map[string]any)
+		want := map[string]any{"tenant_id": "t-1", "region": "eu", "plan": "free", "amount": 120}
+		if !maps.Equal(got, want) {
+			t.Errorf("data = %v, want %v (explicit keys win)", got, want)
+		}
+		if _, ok := data["tenant_id"]; ok {
+			t.Error("caller's data map was modified")
+		}
+
+		if got := events[1].Data.(map[string]any)["tenant_id"]; got != "t-1" {
+			t.Errorf("nil data: tenant_id = %v, want t-1", got)
+		}
+		scalar := events[2].Data.(map[string]any)
+		if scalar["_data"] != 7 || scalar["tenant_id"] != "t-1" {
+			t.Errorf("scalar data = %v, want _data and tenant_id", scalar)
+		}
+	})
+}
+
+func TestCorrelations(t *testing.T) {
+	if err := Init(Config{Service: "test-correlation", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	t.Run("empty context", func(t *testing.T) {
+		ctx := context.Background()
+		if got := Correlation(ctx, "order"); got != "" {
+			t.Errorf("Correlation(empty ctx) = %v, want empty", got)
+		}
+		if got := Correlations(ctx); got != nil {
+			t.Errorf("Correlations(empty ctx) = %v, want nil", got)
+		}
+	})
+
+	t.Run("multiple named correlations", func(t *testing.T) {
+		ctx := WithCorrelation(context.Background(), "order", "ord-1")
+		ctx = WithCorrelation(ctx, "session", "sess-2")
+
+		if got := Correlation(ctx, "order"); got != "ord-1" {
+			t.Errorf("Correlation(order) = %v, want ord-1", got)
+		}
+		if got := Correlation(ctx, "session"); got != "sess-2" {
+			t.Errorf("Correlation(session) = %v, want sess-2", got)
+		}
+	})
+
+	t.Run("parent context is not mutated", func(t *testing.T) {
+		parent := WithCorrelation(context.Background(), "order", "ord-1")
+		child := WithCorrelation(parent, "order", "ord-2")
+
+		if got := Correlation(parent, "order"); got != "ord-1" {
+			t.Errorf("parent Correlation(order) = %v, want ord-1", got)
+		}
+		if got := Correlation(child, "order"); got != "ord-2" {
+			t.Errorf("child Correlation(order) = %v, want ord-2", got)
+		}
+	})
+
+	t.Run("surfaced on event", func(t *testing.T) {
+		ctx := WithCorrelation(context.Background(), "order", "ord-1")
+		event := newEvent(ctx, defaultMonitor.config.Load(), "test.correlation", nil, "info")
+
+		jsonBytes, err := event.ToJSON()
+		if err != nil {
+			t.Fatalf("ToJSON() error = %v", err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(jsonBytes, &decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+
+		correlations, ok := decoded["correlations"].(map[string]any)
+		if !ok {
+			t.Fatal("correlations should be an object")
+		}
+		if correlations["order"] != "ord-1" {
+			t.Errorf("correlations.order = %v, want ord-1", correlations["order"])
+		}
+	})
+
+	t.Run("omitted when unset", func(t *testing.T) {
+		event := newEvent(context.Background(), defaultMonitor.config.Load(), "test.correlation", nil, "info")
+		jsonBytes, _ := event.ToJSON()
+		if strings.Contains(string(jsonBytes), "correlations") {
+			t.Errorf("JSON should omit empty correlations, got %s", jsonBytes)
+		}
+	})
+}
+
+func TestWithExpiry(t *testing.T) {
+	t.Run("sets absolute expiry", func(t *testing.T) {
+		o := &emitOptions{}
+		WithExpiry(5 * time.Minute)(o)
+
+		var event Event
+		before := time.Now()
+		o.applyTo(&event)
+		after := time.Now()
+
+		expiresAt, err := time.Parse(time.RFC3339Nano, event.ExpiresAt)
+		if err != nil {
+			t.Fatalf("ExpiresAt %q is not RFC3339Nano: %v", event.ExpiresAt, err)
+		}
+		if expiresAt.Before(before.Add(5*time.Minute)) || expiresAt.After(after.Add(5*time.Minute)) {
+			t.Errorf("ExpiresAt = %v, want ~5m after %v", expiresAt, before)
+		}
+		if expiresAt.Location() != time.UTC {
+			t.Errorf("ExpiresAt location = %v, want UTC", expiresAt.Location())
+		}
+	})
+
+	t.Run("omitted when unset", func(t *testing.T) {
+		o := &emitOptions{}
+		WithExpiry(0)(o)
+
+		var event Event
+		o.applyTo(&event)
+
+		jsonBytes, _ := event.ToJSON()
+		if strings.Contains(string(jsonBytes), "expires_at") {
+			t.Errorf("JSON should omit empty expires_at, got %s", jsonBytes)
+		}
+	})
+}
+
+func TestWithLink(t *testing.T) {
+	t.Run("multiple links", func(t *testing.T) {
+		o := &emitOptions{}
+		WithLink("stripe", "req_123")(o)
+		WithLink("upstream", "4bf92f3577b34da6")(o)
+
+		var event Event
+		o.applyTo(&event)
+
+		if len(event.Links) != 2 {
+			t.Fatalf("Links = %v, want 2 links", event.Links)
+		}
+		if got := event.LinkID("stripe"); got != "req_123" {
+			t.Errorf("LinkID(stripe) = %v, want req_123", got)
+		}
+		if got := event.LinkID("upstream"); got != "4bf92f3577b34da6" {
+			t.Errorf("LinkID(upstream) = %v, want 4bf92f3577b34da6", got)
+		}
+		if got := event.LinkID("github"); got != "" {
+			t.Errorf("LinkID(github) = %v, want empty", got)
+		}
+
+		jsonBytes, _ := event.ToJSON()
+		want := `"links":[{"system":"stripe","id":"req_123"},{"system":"upstream","id":"4bf92f3577b34da6"}]`
+		if !strings.Contains(string(jsonBytes), want) {
+			t.Errorf("JSON = %s, want to contain %s", jsonBytes, want)
+		}
+	})
+
+	t.Run("omitted when unset", func(t *testing.T) {
+		var event Event
+		(&emitOptions{}).applyTo(&event)
+
+		jsonBytes, _ := event.ToJSON()
+		if strings.Contains(string(jsonBytes), "links") {
+			t.Errorf("JSON should omit empty links, got %s", jsonBytes)
+		}
+	})
+}
+
+func TestWithData(t *testing.T) {
+	apply := func(data any, opts ...EmitOption) any {
+		o := &emitOptions{}
+		for _, opt := range opts {
+			opt(o)
+		}
+		event := Event{Data: data}
+		o.applyTo(&event)
+		return event.Data
+	}
+
+	t.Run("merge precedence", func(t *testing.T) {
+		base := map[string]any{"a": 1, "b": 1, "c": 1}
+		got := apply(base,
+			WithData(map[string]any{"b": 2, "c": 2}),
+			WithData(map[string]any{"c": 3, "d": 3}),
+		)
+		want := map[string]any{"a": 1, "b": 2, "c": 3, "d": 3}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("data = %v, want %v", got, want)
+		}
+		if !reflect.DeepEqual(base, map[string]any{"a": 1, "b": 1, "c": 1}) {
+			t.Errorf("data argument was modified: %v", base)
+		}
+	})
+
+	t.Run("nil data", func(t *testing.T) {
+		got := apply(nil, WithData(map[string]any{"a": 1}))
+		if want := map[string]any{"a": 1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("data = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("non-map data", func(t *testing.T) {
+		got := apply("payload", WithData(map[string]any{"a": 1}))
+		if want := map[string]any{"_data": "payload", "a": 1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("data = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("without WithData", func(t *testing.T) {
+		if got := apply("payload"); got != "payload" {
+			t.Errorf("data = %v, want payload unchanged", got)
+		}
+	})
+
+	t.Run("emitted", func(t *testing.T) {
+		if err := Init(Config{Service: "test-with-data", DisableStdout: true, CaptureSource: new(bool)}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+		c := NewCapture()
+		defer c.Close()
+
+		Emit(context.Background(), "test.data", map[string]any{"user_id": 1}, WithData(map[string]any{"plan": "pro"}))
+		events := c.Events()
+		if len(events) != 1 {
+			t.Fatalf("captured %d events, want 1", len(events))
+		}
+		if want := map[string]any{"user_id": 1, "plan": "pro"}; !reflect.DeepEqual(events[0].Data, want) {
+			t.Errorf("data = %v, want %v", events[0].Data, want)
+		}
+	})
+}
+
+func TestIncludeUptime(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		if err := Init(Config{Service: "test-uptime", DisableStdout: true, IncludeUptime: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defaultMonitor.config.Load().startedAt = time.Now().Add(-1500 * time.Millisecond)
+
+		first := newEvent(context.Background(), defaultMonitor.config.Load(), "test.first", nil, "")
+		time.Sleep(5 * time.Millisecond)
+		second := newEvent(context.Background(), defaultMonitor.config.Load(), "test.second", nil, "")
+
+		if first.ProcessUptimeMS < 1500 {
+			t.Errorf("ProcessUptimeMS = %d, want >= 1500", first.ProcessUptimeMS)
+		}
+		if second.ProcessUptimeMS <= first.ProcessUptimeMS {
+			t.Errorf("uptime did not increase: %d then %d", first.ProcessUptimeMS, second.ProcessUptimeMS)
+		}
+		jsonBytes, _ := first.ToJSON()
+		if !strings.Contains(string(jsonBytes), `"process_uptime_ms":`) {
+			t.Errorf("JSON = %s, want process_uptime_ms", jsonBytes)
+		}
+	})
+
+	t.Run("omitted by default", func(t *testing.T) {
+		if err := Init(Config{Service: "test-uptime", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defaultMonitor.config.Load().startedAt = time.Now().Add(-time.Second)
+
+		jsonBytes, _ := newEvent(context.Background(), defaultMonitor.config.Load(), "test.default", nil, "").ToJSON()
+		if strings.Contains(string(jsonBytes), "process_uptime_ms") {
+			t.Errorf("JSON should omit process_uptime_ms, got %s", jsonBytes)
+		}
+	})
+}
+
+func TestIncludeDeadline(t *testing.T) {
+	cfg := &Config{Service: "test-deadline", IncludeDeadline: true}
+
+	t.Run("remaining", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		data := map[string]any{"key": "value"}
+		event := newEvent(ctx, cfg, "test.deadline", data, "")
+		got := event.Data.(map[string]any)
+		remaining, ok := got["_deadline_remaining_ms"].(int64)
+		if !ok {
+			t.Fatalf("data = %v, want _deadline_remaining_ms", got)
+		}
+		if remaining <= 1500 || remaining > 2000 {
+			t.Errorf("_deadline_remaining_ms = %d, want about 2000", remaining)
+		}
+		if got["key"] != "value" {
+			t.Errorf("data[key] = %v, want value", got["key"])
+		}
+		if _, ok := got["_ctx_err"]; ok {
+			t.Errorf("data = %v, want no _ctx_err", got)
+		}
+		if _, ok := data["_deadline_remaining_ms"]; ok {
+			t.Error("caller's map should not be modified")
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		cancel()
+
+		got := newEvent(ctx, cfg, "test.deadline", "payload", "").Data.(map[string]any)
+		if got["_ctx_err"] != context.Canceled.Error() {
+			t.Errorf("_ctx_err = %v, want %q", got["_ctx_err"], context.Canceled.Error())
+		}
+		if got["_data"] != "payload" {
+			t.Errorf("_data = %v, want payload", got["_data"])
+		}
+	})
+
+	t.Run("no deadline", func(t *testing.T) {
+		event := newEvent(context.Background(), cfg, "test.deadline", nil, "")
+		if event.Data != nil {
+			t.Errorf("Data = %v, want nil", event.Data)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		event := newEvent(ctx, &Config{Service: "test-deadline"}, "test.deadline", nil, "")
+		if event.Data != nil {
+			t.Errorf("Data = %v, want nil", event.Data)
+		}
+	})
+}
+
+func TestInternalSink(t *testing.T) {
+	var sink bytes.Buffer
+	if err := Init(Config{Service: "test-internal", DisableStdout: true, InternalSink: &sink}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	// Unstarted shipper with a 2-slot queue; the third send overflows
+	s := newShipper(defaultMonitor, &Config{Service: "test-internal", IngestURL: "http://unused", BatchSize: 1, FlushEvery: time.Second})
+	for i := 0; i < 3; i++ {
+		s.send(Event{Name: "user.event"})
+	}
+
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("internal sink got %d lines, want 1: %q", len(lines), sink.String())
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded["name"] != "monitor.event_dropped" {
+		t.Errorf("name = %v, want monitor.event_dropped", decoded["name"])
+	}
+	data, _ := decoded["data"].(map[string]any)
+	if data["reason"] != "buffer_full" {
+		t.Errorf("data.reason = %v, want buffer_full", data["reason"])
+	}
+
+	// Internal events never enter the shipper queue
+	if got := len(s.eventsCh); got != 2 {
+		t.Errorf("queued events = %d, want 2 (internal event must not be queued)", got)
+	}
+}
+
+func TestInternalEventsShipped(t *testing.T) {
+	rt := &recordingTransport{}
+	m, err := New(Config{Service: "test-internal-shipped", DisableStdout: true, Transport: rt})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+
+	m.emitSelf("monitor.event_dropped", map[string]any{"reason": DropReasonBufferFull}, LevelWarn)
+	m.Flush()
+
+	rt.mu.Lock()
+	var names []string
+	for _, b := range rt.batches {
+		for _, e := range b {
+			names = append(names, e.Name)
+		}
+	}
+	rt.mu.Unlock()
+	if len(names) != 1 || names[0] != "monitor.event_dropped" {
+		t.Fatalf("shipped = %v, want [monitor.event_dropped]", names)
+	}
+
+	// Losing a batch of internal events is not itself reported
+	var sink bytes.Buffer
+	m2, err := New(Config{Service: "test-internal-shipped", DisableStdout: true, InternalSink: &sink})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	s := newShipper(m2, &Config{Service: "test-internal-shipped", IngestURL: "http://unused"})
+	s.emitBatchDropped(DropReasonRetriesExhausted, []Event{{Name: "monitor.event_dropped", internal: true}})
+	if sink.Len() != 0 {
+		t.Errorf("internal sink = %q, want empty", sink.String())
+	}
+	s.emitBatchDropped(DropReasonRetriesExhausted, []Event{{Name: "monitor.event_dropped", internal: true}, {Name: "user.event"}})
+	if !strings.Contains(sink.String(), `"events":1`) {
+		t.Errorf("internal sink = %q, want a batch_dropped event counting 1 event", sink.String())
+	}
+}
+
+func TestRequestSeq(t *testing.T) {
+	if err := Init(Config{Service: "test-seq", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	t.Run("no counter", func(t *testing.T) {
+		event := newEvent(context.Background(), defaultMonitor.config.Load(), "test.seq", nil, "info")
+		if event.RequestSeq != 0 {
+			t.Errorf("RequestSeq = %d, want 0", event.RequestSeq)
+		}
+		jsonBytes, _ := event.ToJSON()
+		if strings.Contains(string(jsonBytes), "request_seq") {
+			t.Errorf("JSON should omit empty request_seq, got %s", jsonBytes)
+		}
+	})
+
+	t.Run("middleware numbers events", func(t *testing.T) {
+		var seqs []uint64
+		handler := Middleware(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for i := 0; i < 3; i++ {
+				seqs = append(seqs, newEvent(r.Context(), defaultMonitor.config.Load(), "test.seq", nil, "info").RequestSeq)
+			}
+			if got := RequestSeq(r.Context()); got != 3 {
+				t.Errorf("RequestSeq() = %d, want 3", got)
+			}
+		})))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+
+		if len(seqs) != 3 || seqs[0] != 1 || seqs[1] != 2 || seqs[2] != 3 {
+			t.Errorf("seqs = %v, want [1 2 3]", seqs)
+		}
+	})
+
+	t.Run("concurrent emits", func(t *testing.T) {
+		ctx := WithRequestSeq(context.Background())
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				Emit(ctx, "test.seq", nil)
+			}()
+		}
+		wg.Wait()
+		if got := RequestSeq(ctx); got != 50 {
+			t.Errorf("RequestSeq() = %d, want 50", got)
+		}
+	})
+}
+
+func TestEmitWithoutSinks(t *testing.T) {
+	if err := Init(Config{Service: "test-discard", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	ctx := WithRequestSeq(context.Background())
+	allocs := testing.AllocsPerRun(100, func() {
+		Emit(ctx, "test.discard", nil)
+		Info(ctx, "test.discard", nil)
+	})
+	if allocs != 0 {
+		t.Errorf("Emit() without sinks allocated %v times per run, want 0", allocs)
+	}
+
+	// Counters still advance so request_seq stays accurate if output is enabled later
+	if got := RequestSeq(ctx); got != 202 {
+		t.Errorf("RequestSeq() = %d, want 202", got)
+	}
+}
+
+func TestMonitorInstances(t *testing.T) {
+	services := func(rt *recordingTransport) []string {
+		rt.mu.Lock()
+		defer rt.mu.Unlock()
+		var got []string
+		for _, batch := range rt.batches {
+			for _, event := range batch {
+				got = append(got, event.Service+"/"+event.Name)
+			}
+		}
+		return got
+	}
+
+	rtDefault := &recordingTransport{}
+	if err := Init(Config{Service: "test-default", DisableStdout: true, Transport: rtDefault}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	rtA := &recordingTransport{}
+	a, err := New(Config{Service: "tenant-a", DisableStdout: true, Transport: rtA})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer a.Shutdown()
+
+	rtB := &recordingTransport{}
+	outB := &lockedBuffer{}
+	b, err := New(Config{Service: "tenant-b", Output: outB, Transport: rtB, IDFormat: IDFormatHex16})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer b.Shutdown()
+
+	if _, err := New(Config{}); err != ErrServiceRequired {
+		t.Errorf("New() error = %v, want ErrServiceRequired", err)
+	}
+
+	ctx := context.Background()
+	a.Emit(ctx, "a.one", nil)
+	b.Emit(ctx, "b.one", nil)
+	b.Emit(ctx, "b.two", nil)
+	Emit(ctx, "default.one", nil)
+	a.Flush()
+	b.Flush()
+	Flush()
+
+	if got := services(rtA); len(got) != 1 || got[0] != "tenant-a/a.one" {
+		t.Errorf("tenant-a shipped %v, want [tenant-a/a.one]", got)
+	}
+	if got := services(rtB); len(got) != 2 || got[0] != "tenant-b/b.one" || got[1] != "tenant-b/b.two" {
+		t.Errorf("tenant-b shipped %v, want [tenant-b/b.one tenant-b/b.two]", got)
+	}
+	if got := services(rtDefault); len(got) != 1 || got[0] != "test-default/default.one" {
+		t.Errorf("default shipped %v, want [test-default/default.one]", got)
+	}
+	if lines := outB.lines(); len(lines) != 2 || !strings.Contains(lines[0], `"service":"tenant-b"`) {
+		t.Errorf("tenant-b output = %v, want its 2 events", lines)
+	}
+	if st := a.Stats(); st.TotalShipped != 1 {
+		t.Errorf("a.Stats().TotalShipped = %d, want 1", st.TotalShipped)
+	}
+	if st := b.Stats(); st.TotalShipped != 2 {
+		t.Errorf("b.Stats().TotalShipped = %d, want 2", st.TotalShipped)
+	}
+
+	t.Run("Middleware uses the monitor's config", func(t *testing.T) {
+		var requestID, jobID string
+		handler := b.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID = RequestID(r.Context())
+			jobID = JobID(r.Context())
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		if len(requestID) != 16 {
+			t.Errorf("request ID = %q, want 16 hex characters from tenant-b's IDFormat", requestID)
+		}
+		if jobID == "" || jobID != b.config.Load().JobID {
+			t.Errorf("job ID = %q, want tenant-b's %q", jobID, b.config.Load().JobID)
+		}
+	})
+
+	t.Run("helpers emit through the monitor", func(t *testing.T) {
+		rtC := &recordingTransport{}
+		c, err := New(Config{Service: "tenant-c", DisableStdout: true, Debug: true, CaptureCaller: true, Transport: rtC})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer c.Shutdown()
+		before := rtDefault.eventCount()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		c.Debug(ctx, "c.debug", nil)
+		c.Info(ctx, "c.info", nil)
+		c.Warn(ctx, "c.warn", nil)
+		c.Error(ctx, "c.error", nil)
+		c.Fatal(ctx, "c.fatal", nil)
+		c.CaptureError(ctx, errors.New("boom"))
+		c.EmitError(ctx, "c.emit_error", errors.New("boom"))
+		c.EmitErrors(ctx, "c.emit_errors", []error{errors.New("boom")}, nil)
+		c.EmitAudit(ctx, "c.audit", nil)
+		c.StartTimer("c.timer").End(ctx)
+		_, span := c.StartSpan(ctx, "c.span")
+		span.Finish()
+		resp, err := c.WrapHTTPClient(nil).Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+		inputs := make(chan EventInput)
+		stop := c.EmitFrom(ctx, inputs)
+		inputs <- EventInput{Name: "c.from"} // unbuffered, so received before stop
+		stop()
+		c.Flush()
+
+		want := []string{"c.audit", "c.debug", "c.emit_error", "c.emit_errors", "c.error", "c.fatal", "c.from",
+			"c.info", "c.span", "c.timer", "c.warn", "error.captured", "http.client_request"}
+		rtC.mu.Lock()
+		var names []string
+		for _, batch := range rtC.batches {
+			for _, event := range batch {
+				names = append(names, event.Service+"/"+event.Name)
+				// The wrappers attribute events to their caller, not to the SDK
+				switch event.Name {
+				case "c.debug", "c.audit", "error.captured", "c.emit_error", "c.emit_errors":
+					if !strings.Contains(event.Caller, "/monitor_test.go:") {
+						t.Errorf("%s caller = %q, want monitor_test.go", event.Name, event.Caller)
+					}
+				}
+			}
+		}
+		rtC.mu.Unlock()
+		sort.Strings(names)
+		for i := range want {
+			want[i] = "tenant-c/" + want[i]
+		}
+		if !slices.Equal(names, want) {
+			t.Errorf("tenant-c shipped %v, want %v", names, want)
+		}
+		if n := rtDefault.eventCount(); n != before {
+			t.Errorf("default monitor shipped %d new events, want 0", n-before)
+		}
+	})
+
+	t.Run("Shutdown is per monitor", func(t *testing.T) {
+		var ranA, ranDefault bool
+		a.RegisterShutdownHook(func(ctx context.Context) error {
+			ranA = true
+			return nil
+		})
+		RegisterShutdownHook(func(ctx context.Context) error {
+			ranDefault = true
+			return nil
+		})
+
+		a.Shutdown()
+		if !ranA || ranDefault {
+			t.Errorf("after a.Shutdown(): a's hook ran = %v, default hook ran = %v; want true, false", ranA, ranDefault)
+		}
+		if !a.IsShutdown() || b.IsShutdown() || IsShutdown() {
+			t.Errorf("IsShutdown: a = %v, b = %v, default = %v; want true, false, false", a.IsShutdown(), b.IsShutdown(), IsShutdown())
+		}
+
+		a.Emit(ctx, "a.after", nil)
+		b.Emit(ctx, "b.after", nil)
+		b.Flush()
+		if n := rtA.eventCount(); n != 1 {
+			t.Errorf("tenant-a shipped %d events after Shutdown, want 1", n)
+		}
+		if n := rtB.eventCount(); n != 3 {
+			t.Errorf("tenant-b shipped %d events, want 3", n)
+		}
+	})
+}
+
+func BenchmarkEmitWithoutSinks(b *testing.B) {
+	if err := Init(Config{Service: "bench-discard", DisableStdout: true}); err != nil {
+		b.Fatalf("Init() error = %v", err)
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Emit(ctx, "bench.discard", nil)
+	}
+}
+
+func TestRejectUnmarshalable(t *testing.T) {
+	type order struct {
+		ID     string  `json:"id"`
+		Amount float64 `json:"amount"`
+	}
+	type withFunc struct {
+		ID       string `json:"id"`
+		Callback func() `json:"callback"`
+	}
+
+	var mu sync.Mutex
+	var drops []string
+	rt := &recordingTransport{}
+	captureSource := false
+	if err := Init(Config{
+		Service:             "test-reject",
+		DisableStdout:       true,
+		CaptureSource:       &captureSource,
+		Transport:           rt,
+		RejectUnmarshalable: true,
+		OnDrop: func(event Event, reason string) {
+			mu.Lock()
+			defer mu.Unlock()
+			drops = append(drops, event.Name+":"+reason)
+		},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	ctx := context.Background()
+	Emit(ctx, "order.created", order{ID: "o-1", Amount: 9.5})
+	Emit(ctx, "order.bad", withFunc{ID: "o-2", Callback: func() {}})
+	Flush()
+
+	mu.Lock()
+	gotDrops := drops
+	mu.Unlock()
+	if len(gotDrops) != 1 || gotDrops[0] != "order.bad:"+DropReasonMarshalError {
+		t.Errorf("drops = %v, want [order.bad:%s]", gotDrops, DropReasonMarshalError)
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if len(rt.batches) != 1 || len(rt.batches[0]) != 1 {
+		t.Fatalf("batches = %v, want one batch with the valid event", rt.batches)
+	}
+	shipped := rt.batches[0][0]
+	if got, ok := shipped.Data.(order); !ok || got.ID != "o-1" {
+		t.Errorf("shipped data = %#v, want the order struct", shipped.Data)
+	}
+	if st := Stats(); st.TotalDropped != 1 {
+		t.Errorf("TotalDropped = %d, want 1", st.TotalDropped)
+	}
+
+	if err := EmitSync(ctx, "order.bad", withFunc{}); err == nil {
+		t.Error("EmitSync() with unmarshalable data should fail")
+	}
+}
+
+func TestMaxDataBytes(t *testing.T) {
+	var mu sync.Mutex
+	var truncated []int
+	rt := &recordingTransport{}
+	var out lockedBuffer
+	if err := Init(Config{
+		Service:       "test-max-data",
+		CaptureSource: new(bool),
+		Output:        &out,
+		Transport:     rt,
+		MaxDataBytes:  1024,
+		OnTruncate: func(event Event, originalBytes int) {
+			mu.Lock()
+			defer mu.Unlock()
+			truncated = append(truncated, originalBytes)
+		},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	ctx := context.Background()
+	Emit(ctx, "test.small", map[string]any{"id": "s-1"})
+	Emit(ctx, "test.large", map[string]any{"blob": strings.Repeat("x", 4096)})
+	EmitAudit(ctx, "test.audit", map[string]any{"blob": strings.Repeat("x", 4096)})
+	Flush()
+
+	wantBytes := len(`{"blob":""}`) + 4096
+	mu.Lock()
+	gotTruncated := truncated
+	mu.Unlock()
+	if !slices.Equal(gotTruncated, []int{wantBytes}) {
+		t.Errorf("OnTruncate sizes = %v, want [%d]", gotTruncated, wantBytes)
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	byName := make(map[string]Event)
+	for _, batch := range rt.batches {
+		for _, event := range batch {
+			byName[event.Name] = event
+		}
+	}
+	if len(byName) != 3 {
+		t.Fatalf("batches = %v, want all events shipped", rt.batches)
+	}
+	if got := byName["test.small"].Data.(map[string]any); got["id"] != "s-1" {
+		t.Errorf("small event data = %v, want it unchanged", got)
+	}
+	want := map[string]any{"_truncated": true, "_original_bytes": wantBytes}
+	if got := byName["test.large"].Data; !reflect.DeepEqual(got, want) {
+		t.Errorf("large event data = %v, want %v", got, want)
+	}
+	// Audit data stays whole, so its hash covers what was recorded
+	if got := byName["test.audit"].Data.(map[string]any); len(got["blob"].(string)) != 4096 {
+		t.Error("audit event data was truncated, want it whole")
+	}
+	if lines := out.lines(); len(lines) != 3 || strings.Contains(lines[1], "xxxx") {
+		t.Errorf("stdout = %v, want the large event truncated", lines)
+	}
+}
+
+func TestTryEmit(t *testing.T) {
+	ctx := context.Background()
+	newMonitor := func(t *testing.T, cfg Config) *Monitor {
+		t.Helper()
+		cfg.Service = "test-try-emit"
+		cfg.DisableStdout = true
+		if cfg.Transport == nil {
+			cfg.Transport = &recordingTransport{}
+		}
+		m, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		t.Cleanup(m.Shutdown)
+		return m
+	}
+
+	t.Run("accepted", func(t *testing.T) {
+		m := newMonitor(t, Config{})
+		if err := m.TryEmit(ctx, "test.ok", nil); err != nil {
+			t.Errorf("TryEmit() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("not initialized", func(t *testing.T) {
+		m := newMonitor(t, Config{})
+		m.Shutdown()
+		if err := m.TryEmit(ctx, "test.closed", nil); !errors.Is(err, ErrNotInitialized) {
+			t.Errorf("TryEmit() error = %v, want ErrNotInitialized", err)
+		}
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		m := newMonitor(t, Config{})
+		if err := m.TryEmit(WithSampleRate(ctx, 0), "test.sampled", nil); !errors.Is(err, ErrSampled) {
+			t.Errorf("TryEmit() error = %v, want ErrSampled", err)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		m := newMonitor(t, Config{MaxEventsPerSecond: 1})
+		m.TryEmit(ctx, "test.limited", nil)
+		if err := m.TryEmit(ctx, "test.limited", nil); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("TryEmit() error = %v, want ErrRateLimited", err)
+		}
+	})
+
+	t.Run("schema violation", func(t *testing.T) {
+		m := newMonitor(t, Config{StrictSchema: true})
+		m.RegisterSchema("user.created", []string{"user_id"})
+		if err := m.TryEmit(ctx, "user.created", map[string]any{}); !errors.Is(err, ErrSchemaViolation) {
+			t.Errorf("TryEmit() error = %v, want ErrSchemaViolation", err)
+		}
+	})
+
+	t.Run("unmarshalable", func(t *testing.T) {
+		m := newMonitor(t, Config{RejectUnmarshalable: true})
+		if err := m.TryEmit(ctx, "test.bad", map[string]any{"fn": func() {}}); !errors.Is(err, ErrUnmarshalable) {
+			t.Errorf("TryEmit() error = %v, want ErrUnmarshalable", err)
+		}
+	})
+
+	t.Run("buffer full", func(t *testing.T) {
+		m := newMonitor(t, Config{})
+		// A shipper that is never started, so its one-slot queue stays full
+		running := m.shipper.Load()
+		m.shipper.Store(newShipper(m, &Config{Service: "test-try-emit", Transport: &recordingTransport{}, BatchSize: 1, QueueSize: 1}))
+		defer m.shipper.Store(running)
+
+		if err := m.TryEmit(ctx, "test.first", nil); err != nil {
+			t.Fatalf("TryEmit() error = %v, want nil", err)
+		}
+		if err := m.TryEmit(ctx, "test.second", nil); !errors.Is(err, ErrBufferFull) {
+			t.Errorf("TryEmit() error = %v, want ErrBufferFull", err)
+		}
+	})
+}
+
+func TestEventFields(t *testing.T) {
+	if err := Init(Config{
+		Service:       "test-fields",
+		DisableStdout: true,
+		StaticFields:  map[string]any{"tenant_id": "static", "region": "us-east-1", "service": "spoofed"},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	events := Captured(func() {
+		Emit(context.Background(), "test.fields", map[string]any{"key": "value"},
+			WithField("tenant_id", "acme"),
+			WithField("timestamp", "spoofed"),
+		)
+		Info(context.Background(), "test.static", nil)
+	})
+	if len(events) != 2 {
+		t.Fatalf("captured %d events, want 2", len(events))
+	}
+
+	jsonBytes, err := events[0].ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(jsonBytes, &decoded); err != nil {
+		t.Fatalf("invalid JSON %s: %v", jsonBytes, err)
+	}
+	want := map[string]any{
+		"tenant_id":       "acme", // WithField overrides StaticFields
+		"region":          "us-east-1",
+		"service":         "test-fields",
+		"field_service":   "spoofed",
+		"field_timestamp": "spoofed",
+	}
+	for k, v := range want {
+		if decoded[k] != v {
+			t.Errorf("%s = %v, want %v (JSON %s)", k, decoded[k], v, jsonBytes)
+		}
+	}
+	if ts, _ := decoded["timestamp"].(string); ts == "spoofed" || ts == "" {
+		t.Errorf("timestamp = %q, want the event time", ts)
+	}
+	if data, _ := decoded["data"].(map[string]any); data["tenant_id"] != nil {
+		t.Errorf("data = %v, want fields only at the top level", data)
+	}
+
+	if events[1].Fields["tenant_id"] != "static" {
+		t.Errorf("Fields = %v, want StaticFields on every event", events[1].Fields)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		var event Event
+		if err := json.Unmarshal(jsonBytes, &event); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if event.Service != "test-fields" || event.Fields["tenant_id"] != "acme" || event.Fields["field_service"] != "spoofed" {
+			t.Errorf("decoded event = %+v, want service and fields restored", event)
+		}
+		if _, ok := event.Fields["service"]; ok {
+			t.Errorf("Fields = %v, want struct keys excluded", event.Fields)
+		}
+	})
+
+	t.Run("omitted when unset", func(t *testing.T) {
+		jsonBytes, _ := Event{Name: "test.plain", Level: LevelInfo}.ToJSON()
+		if strings.Contains(string(jsonBytes), "field_") || strings.HasSuffix(string(jsonBytes), ",}") {
+			t.Errorf("JSON = %s, want no extra fields", jsonBytes)
+		}
+	})
+}
@@ -0,0 +1,457 @@
+// Package otel exports go-monitor spans to an OpenTelemetry collector (or any
+// backend that accepts OTLP, such as Jaeger) over OTLP/HTTP with JSON
+// encoding.
+//
+// Spans come from monitor.StartSpan and Span.Finish and are exported
+// independently of the event pipeline: a deployment can ship events only
+// (no SpanExporter), spans only (DisableStdout and no IngestURL/Transport),
+// or both. Exporting is asynchronous: finished spans are queued and sent in
+// batches, and spans are dropped (see Dropped) if the queue is full.
+//
+// go-monitor trace IDs are converted to OTLP's 16-byte form: UUIDs and 32-hex
+// IDs map directly (dashes removed), AWS X-Ray roots drop their "1-" version
+// prefix, and any other ID is hashed with SHA-256, so a trace's spans still
+// share one trace ID. Span IDs that aren't 16 hex characters are hashed the
+// same way.
+//
+// Usage:
+//
+//	exp, err := otel.New(otel.Config{Endpoint: "http://otel-collector:4318"})
+//	monitor.Init(monitor.Config{Service: "api", SpanExporter: exp})
+//	monitor.RegisterShutdownHook(exp.Shutdown)
+package otel
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+// tracesPath is the OTLP/HTTP traces endpoint.
+const tracesPath = "/v1/traces"
+
+// scopeName identifies go-monitor as the instrumentation scope.
+const scopeName = "github.com/aidenappl/go-monitor"
+
+// ErrEndpointRequired is returned when Config.Endpoint is empty.
+var ErrEndpointRequired = errors.New("otel: Config.Endpoint is required")
+
+// ErrShutdown is returned by Shutdown when the exporter was already shut down.
+var ErrShutdown = errors.New("otel: exporter already shut down")
+
+// Config configures the OTLP span exporter.
+type Config struct {
+	// Endpoint is the OTLP/HTTP base URL (e.g., "http://otel-collector:4318").
+	// The traces path is appended unless the URL already ends with it. Required.
+	Endpoint string
+
+	// Headers are added to every export request (e.g., an API key). Optional.
+	Headers map[string]string
+
+	// BatchSize is the maximum number of spans per export. Default: 256.
+	BatchSize int
+
+	// QueueSize is how many finished spans can wait for export before new
+	// ones are dropped. Default: 2048.
+	QueueSize int
+
+	// FlushEvery is how often queued spans are exported. Default: 5s.
+	FlushEvery time.Duration
+
+	// HTTPClient is used for export requests. Default: a client with a 30s timeout.
+	HTTPClient *http.Client
+}
+
+// Exporter implements monitor.SpanExporter over OTLP/HTTP JSON.
+type Exporter struct {
+	cfg       Config
+	tracesURL string
+	client    *http.Client
+
+	spans   chan monitor.FinishedSpan
+	flushCh chan flushRequest
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+
+	// stopCtx bounds the final export. Set by Shutdown before stopCh is
+	// closed, so the export goroutine sees it once stopCh is closed.
+	stopCtx context.Context
+}
+
+// flushRequest asks the export goroutine to export all queued spans within
+// ctx and close done.
+type flushRequest struct {
+	ctx  context.Context
+	done chan struct{}
+}
+
+// New creates an OTLP span exporter and starts its export goroutine.
+func New(cfg Config) (*Exporter, error) {
+	if cfg.Endpoint == "" {
+		return nil, ErrEndpointRequired
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 256
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 2048
+	}
+	if cfg.FlushEvery <= 0 {
+		cfg.FlushEvery = 5 * time.Second
+	}
+
+	tracesURL := strings.TrimSuffix(cfg.Endpoint, "/")
+	if !strings.HasSuffix(tracesURL, tracesPath) {
+		tracesURL += tracesPath
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	e := &Exporter{
+		cfg:       cfg,
+		tracesURL: tracesURL,
+		client:    client,
+		spans:     make(chan monitor.FinishedSpan, cfg.QueueSize),
+		flushCh:   make(chan flushRequest),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+	go e.run()
+	return e, nil
+}
+
+// ExportSpan implements monitor.SpanExporter. It queues the span without
+// blocking and drops it if the queue is full or the exporter is shut down.
+func (e *Exporter) ExportSpan(span monitor.FinishedSpan) {
+	select {
+	case <-e.stopCh:
+		e.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case e.spans <- span:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many spans were discarded because the queue was full,
+// the exporter was shut down, or an export failed.
+func (e *Exporter) Dropped() uint64 {
+	return e.dropped.Load()
+}
+
+// Flush exports all queued spans, returning when done or when ctx is done.
+// Exports still in flight when ctx is done are canceled.
+func (e *Exporter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case e.flushCh <- flushRequest{ctx: ctx, done: done}:
+	case <-e.doneCh:
+		return ErrShutdown
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exports queued spans and stops the exporter. It has the signature
+// of a monitor shutdown hook, so it can be passed to RegisterShutdownHook.
+// When ctx is done first, the final export is canceled.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	err := ErrShutdown
+	e.once.Do(func() {
+		e.stopCtx = ctx
+		close(e.stopCh)
+		err = nil
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case <-e.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Exporter) run() {
+	defer close(e.doneCh)
+
+	ticker := time.NewTicker(e.cfg.FlushEvery)
+	defer ticker.Stop()
+
+	batch := make([]monitor.FinishedSpan, 0, e.cfg.BatchSize)
+	export := func(ctx context.Context) {
+		if len(batch) > 0 {
+			e.export(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+	drain := func(ctx context.Context) {
+		for n := len(e.spans); n > 0; n-- {
+			batch = append(batch, <-e.spans)
+			if len(batch) >= e.cfg.BatchSize {
+				export(ctx)
+			}
+		}
+		export(ctx)
+	}
+
+	for {
+		select {
+		case span := <-e.spans:
+			batch = append(batch, span)
+			if len(batch) >= e.cfg.BatchSize {
+				export(context.Background())
+			}
+		case <-ticker.C:
+			export(context.Background())
+		case req := <-e.flushCh:
+			drain(req.ctx)
+			close(req.done)
+		case <-e.stopCh:
+			drain(e.stopCtx)
+			return
+		}
+	}
+}
+
+// export sends one batch within ctx. Failures are reported on stderr and
+// counted as dropped; spans are not retried.
+func (e *Exporter) export(ctx context.Context, batch []monitor.FinishedSpan) {
+	if err := e.send(ctx, batch); err != nil {
+		e.dropped.Add(uint64(len(batch)))
+		fmt.Fprintf(os.Stderr, "otel: failed to export %d spans: %v\n", len(batch), err)
+	}
+}
+
+func (e *Exporter) send(ctx context.Context, batch []monitor.FinishedSpan) error {
+	body, err := json.Marshal(buildRequest(batch))
+	if err != nil {
+		return fmt.Errorf("marshal spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.tracesURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// OTLP/JSON request types (opentelemetry-proto ExportTraceServiceRequest).
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// OTLP enum values.
+const (
+	spanKindInternal = 1
+	statusCodeUnset  = 0
+	statusCodeError  = 2
+)
+
+// buildRequest groups spans into one resource per service and env.
+func buildRequest(batch []monitor.FinishedSpan) exportRequest {
+	byResource := make(map[[2]string]int)
+	var req exportRequest
+
+	for _, s := range batch {
+		key := [2]string{s.Service, s.Env}
+		i, ok := byResource[key]
+		if !ok {
+			attrs := []keyValue{stringAttr("service.name", s.Service)}
+			if s.Env != "" {
+				attrs = append(attrs, stringAttr("deployment.environment", s.Env))
+			}
+			req.ResourceSpans = append(req.ResourceSpans, resourceSpans{
+				Resource:   resource{Attributes: attrs},
+				ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName}}},
+			})
+			i = len(req.ResourceSpans) - 1
+			byResource[key] = i
+		}
+		ss := &req.ResourceSpans[i].ScopeSpans[0]
+		ss.Spans = append(ss.Spans, convertSpan(s))
+	}
+	return req
+}
+
+func convertSpan(s monitor.FinishedSpan) span {
+	out := span{
+		TraceID:           traceID(s.TraceID),
+		SpanID:            spanID(s.SpanID),
+		Name:              s.Name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+		Status:            status{Code: statusCodeUnset},
+	}
+	if s.ParentSpanID != "" {
+		out.ParentSpanID = spanID(s.ParentSpanID)
+	}
+	if s.Err != nil {
+		out.Status = status{Code: statusCodeError, Message: s.Err.Error()}
+	}
+	if s.RequestID != "" {
+		out.Attributes = append(out.Attributes, stringAttr("request_id", s.RequestID))
+	}
+	for k, v := range s.Attributes {
+		out.Attributes = append(out.Attributes, attr(k, v))
+	}
+	return out
+}
+
+// traceID converts a go-monitor trace ID to 32 lowercase hex characters.
+func traceID(id string) string {
+	// AWS X-Ray roots are "1-<8 hex time>-<24 hex random>"
+	if strings.HasPrefix(id, "1-") && strings.Count(id, "-") == 2 {
+		id = id[2:]
+	}
+	return hexID(strings.ReplaceAll(id, "-", ""), 16)
+}
+
+// spanID converts a go-monitor span ID to 16 lowercase hex characters.
+func spanID(id string) string {
+	return hexID(id, 8)
+}
+
+// hexID returns id lowercased if it is already n bytes of non-zero hex,
+// otherwise the first n bytes of its SHA-256.
+func hexID(id string, n int) string {
+	lower := strings.ToLower(id)
+	if len(lower) == 2*n && strings.Trim(lower, "0") != "" {
+		if _, err := hex.DecodeString(lower); err == nil {
+			return lower
+		}
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:n])
+}
+
+func stringAttr(key, value string) keyValue {
+	return keyValue{Key: key, Value: anyValue{StringValue: &value}}
+}
+
+// attr converts a span data value to an OTLP attribute. Values that aren't
+// strings, booleans, or numbers are formatted with %v.
+func attr(key string, value any) keyValue {
+	var v anyValue
+	switch x := value.(type) {
+	case string:
+		v.StringValue = &x
+	case bool:
+		v.BoolValue = &x
+	case int:
+		v.IntValue = intString(int64(x))
+	case int32:
+		v.IntValue = intString(int64(x))
+	case int64:
+		v.IntValue = intString(x)
+	case uint32:
+		v.IntValue = intString(int64(x))
+	case float32:
+		f := float64(x)
+		v.DoubleValue = &f
+	case float64:
+		v.DoubleValue = &x
+	default:
+		s := fmt.Sprintf("%v", x)
+		v.StringValue = &s
+	}
+	return keyValue{Key: key, Value: v}
+}
+
+func intString(n int64) *string {
+	s := strconv.FormatInt(n, 10)
+	return &s
+}
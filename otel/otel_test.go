@@ -0,0 +1,217 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+func TestNewRequiresEndpoint(t *testing.T) {
+	if _, err := New(Config{}); !errors.Is(err, ErrEndpointRequired) {
+		t.Errorf("New() error = %v, want ErrEndpointRequired", err)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	var mu sync.Mutex
+	var path, apiKey string
+	var bodies []exportRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var req exportRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			t.Errorf("invalid OTLP JSON: %v", err)
+		}
+		mu.Lock()
+		path, apiKey = r.URL.Path, r.Header.Get("X-Api-Key")
+		bodies = append(bodies, req)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	exp, err := New(Config{
+		Endpoint:   server.URL,
+		Headers:    map[string]string{"X-Api-Key": "secret"},
+		FlushEvery: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	start := time.Unix(1700000000, 0)
+	exp.ExportSpan(monitor.FinishedSpan{
+		Service:      "api",
+		Env:          "prod",
+		Name:         "db.query",
+		TraceID:      "4bf92f35-77b3-4da6-a3ce-929d0e0e4736",
+		SpanID:       "00f067aa0ba902b7",
+		ParentSpanID: "not-hex-parent",
+		RequestID:    "req-1",
+		Start:        start,
+		End:          start.Add(25 * time.Millisecond),
+		Attributes:   map[string]any{"table": "users", "rows": 3, "cached": false},
+		Err:          errors.New("timeout"),
+	})
+
+	if err := exp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := exp.Shutdown(context.Background()); !errors.Is(err, ErrShutdown) {
+		t.Errorf("second Shutdown() error = %v, want ErrShutdown", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if path != "/v1/traces" || apiKey != "secret" {
+		t.Errorf("request path = %q, X-Api-Key = %q, want /v1/traces and secret", path, apiKey)
+	}
+	if len(bodies) != 1 || len(bodies[0].ResourceSpans) != 1 {
+		t.Fatalf("bodies = %+v, want one request with one resource", bodies)
+	}
+	rs := bodies[0].ResourceSpans[0]
+	if got := *rs.Resource.Attributes[0].Value.StringValue; got != "api" {
+		t.Errorf("service.name = %q, want api", got)
+	}
+	s := rs.ScopeSpans[0].Spans[0]
+	if s.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || s.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("IDs = %s/%s, want UUID without dashes and span ID as-is", s.TraceID, s.SpanID)
+	}
+	if len(s.ParentSpanID) != 16 {
+		t.Errorf("parentSpanId = %q, want 16 hex characters", s.ParentSpanID)
+	}
+	if s.StartTimeUnixNano != "1700000000000000000" || s.EndTimeUnixNano != "1700000000025000000" {
+		t.Errorf("times = %s..%s, want start and end in unix nanos", s.StartTimeUnixNano, s.EndTimeUnixNano)
+	}
+	if s.Status.Code != statusCodeError || s.Status.Message != "timeout" {
+		t.Errorf("status = %+v, want error with message", s.Status)
+	}
+	attrs := map[string]anyValue{}
+	for _, kv := range s.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if v := attrs["rows"].IntValue; v == nil || *v != "3" {
+		t.Errorf("rows attribute = %+v, want intValue 3", attrs["rows"])
+	}
+	if v := attrs["cached"].BoolValue; v == nil || *v {
+		t.Errorf("cached attribute = %+v, want boolValue false", attrs["cached"])
+	}
+	if v := attrs["request_id"].StringValue; v == nil || *v != "req-1" {
+		t.Errorf("request_id attribute = %+v, want req-1", attrs["request_id"])
+	}
+}
+
+func TestFlushCanceled(t *testing.T) {
+	canceled := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server notices a client disconnect only after the body is read
+		_, _ = io.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			canceled <- true
+		case <-time.After(5 * time.Second):
+			canceled <- false
+		}
+	}))
+	defer server.Close()
+
+	exp, err := New(Config{Endpoint: server.URL, FlushEvery: time.Hour})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer exp.Shutdown(context.Background())
+
+	exp.ExportSpan(monitor.FinishedSpan{Name: "stalled", TraceID: "t", SpanID: "s"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := exp.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush() error = %v, want context.DeadlineExceeded", err)
+	}
+	if !<-canceled {
+		t.Fatal("export request outlived the Flush context")
+	}
+	// The goroutine counts the drop once the canceled request returns
+	if err := exp.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := exp.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{"uuid", "4bf92f35-77b3-4da6-a3ce-929d0e0e4736", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"hex32 uppercase", "4BF92F3577B34DA6A3CE929D0E0E4736", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"xray root", "1-5759e988-bd862e3fe1be46a994272793", "5759e988bd862e3fe1be46a994272793"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := traceID(tt.id); got != tt.want {
+				t.Errorf("traceID(%q) = %q, want %q", tt.id, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("other IDs hash stably", func(t *testing.T) {
+		a, b := traceID("trace-custom"), traceID("trace-custom")
+		if a != b || len(a) != 32 {
+			t.Errorf("traceID(trace-custom) = %q then %q, want the same 32 hex characters", a, b)
+		}
+	})
+}
+
+func TestExporterWithMonitor(t *testing.T) {
+	var mu sync.Mutex
+	var names []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req exportRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, s := range rs.ScopeSpans[0].Spans {
+				names = append(names, s.Name)
+			}
+		}
+	}))
+	defer server.Close()
+
+	exp, err := New(Config{Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := monitor.Init(monitor.Config{Service: "otel-test", DisableStdout: true, SpanExporter: exp}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	monitor.RegisterShutdownHook(exp.Shutdown)
+
+	ctx, span := monitor.StartSpan(context.Background(), "job.run")
+	_, child := monitor.StartSpan(ctx, "job.step")
+	child.Finish()
+	span.Finish()
+
+	if err := exp.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	mu.Lock()
+	if len(names) != 2 || names[0] != "job.step" || names[1] != "job.run" {
+		t.Errorf("exported spans = %v, want [job.step job.run]", names)
+	}
+	mu.Unlock()
+
+	if err := monitor.ShutdownContext(context.Background()); err != nil {
+		t.Errorf("ShutdownContext() error = %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats for Config.ExportFormat.
+const (
+	// ExportFormatNDJSON posts each batch as newline-delimited Event JSON.
+	// This is the default.
+	ExportFormatNDJSON = "ndjson"
+
+	// ExportFormatOTLPLogs posts each batch as an OTLP/HTTP JSON
+	// ExportLogsServiceRequest, for an OpenTelemetry Collector's logs
+	// endpoint (e.g., "http://otel-collector:4318/v1/logs").
+	ExportFormatOTLPLogs = "otlp-logs"
+)
+
+// otlpContentType is the Content-Type of OTLP/HTTP JSON requests.
+const otlpContentType = "application/json"
+
+// otlpScopeName identifies go-monitor as the instrumentation scope.
+const otlpScopeName = "github.com/aidenappl/go-monitor"
+
+// validExportFormat reports whether format is empty (the default) or a known export format.
+func validExportFormat(format string) bool {
+	switch format {
+	case "", ExportFormatNDJSON, ExportFormatOTLPLogs:
+		return true
+	}
+	return false
+}
+
+// otlpSeverities maps levels to OTLP SeverityNumber values. Audit events
+// are reported at INFO; unknown levels are left unspecified (0).
+var otlpSeverities = map[string]int{
+	LevelDebug: 5,
+	LevelInfo:  9,
+	LevelAudit: 9,
+	LevelWarn:  13,
+	LevelError: 17,
+	LevelFatal: 21,
+}
+
+// OTLP/JSON request types (opentelemetry-proto ExportLogsServiceRequest).
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// encodeOTLPLogs builds an OTLP logs request for batch, with one resource per
+// service and env. It returns the events encoded, in order; events whose data
+// can't be marshaled are returned in failed instead.
+func encodeOTLPLogs(batch []Event) (payload []byte, encoded, failed []Event, err error) {
+	byResource := make(map[[2]string]int)
+	var req otlpLogsRequest
+	for _, event := range batch {
+		record, err := otlpRecord(event)
+		if err != nil {
+			failed = append(failed, event)
+			continue
+		}
+		key := [2]string{event.Service, event.Env}
+		i, ok := byResource[key]
+		if !ok {
+			attrs := []otlpKeyValue{otlpString("service.name", event.Service)}
+			if event.Env != "" {
+				attrs = append(attrs, otlpString("deployment.environment", event.Env))
+			}
+			req.ResourceLogs = append(req.ResourceLogs, otlpResourceLogs{
+				Resource:  otlpResource{Attributes: attrs},
+				ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: otlpScopeName}}},
+			})
+			i = len(req.ResourceLogs) - 1
+			byResource[key] = i
+		}
+		sl := &req.ResourceLogs[i].ScopeLogs[0]
+		sl.LogRecords = append(sl.LogRecords, record)
+		encoded = append(encoded, event)
+	}
+	if len(encoded) == 0 {
+		return nil, nil, failed, nil
+	}
+	payload, err = json.Marshal(req)
+	return payload, encoded, failed, err
+}
+
+// otlpRecord converts event to a log record: the name is the body, IDs and
+// correlations are attributes, and map data becomes "data.<key>" attributes
+// (other data a single "data" attribute), with nested values as JSON strings.
+// Map keys are visited in sorted order so output is deterministic.
+func otlpRecord(event Event) (otlpLogRecord, error) {
+	ts := time.Now()
+	if t, err := event.Time(); err == nil {
+		ts = t
+	}
+	nanos := strconv.FormatInt(ts.UnixNano(), 10)
+	record := otlpLogRecord{
+		TimeUnixNano:         nanos,
+		ObservedTimeUnixNano: nanos,
+		SeverityNumber:       otlpSeverities[event.Level],
+		SeverityText:         event.Level,
+		Body:                 otlpStringValue(event.Name),
+	}
+	if event.TraceID != "" {
+		record.TraceID = otlpHexID(otlpTraceID(event.TraceID), 16)
+	}
+	if event.SpanID != "" {
+		record.SpanID = otlpHexID(event.SpanID, 8)
+	}
+
+	for _, id := range [][2]string{
+		{"job_id", event.JobID},
+		{"parent_job_id", event.ParentJobID},
+		{"request_id", event.RequestID},
+		{"user_id", event.UserID},
+		{"session_id", event.SessionID},
+		{"parent_span_id", event.ParentSpanID},
+		{"caller", event.Caller},
+	} {
+		if id[1] != "" {
+			record.Attributes = append(record.Attributes, otlpString(id[0], id[1]))
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(event.Correlations)) {
+		record.Attributes = append(record.Attributes, otlpString("correlations."+k, event.Correlations[k]))
+	}
+
+	switch data := event.Data.(type) {
+	case nil:
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(data)) {
+			kv, err := otlpAttr("data."+k, data[k])
+			if err != nil {
+				return otlpLogRecord{}, err
+			}
+			record.Attributes = append(record.Attributes, kv)
+		}
+	default:
+		kv, err := otlpAttr("data", data)
+		if err != nil {
+			return otlpLogRecord{}, err
+		}
+		record.Attributes = append(record.Attributes, kv)
+	}
+	for _, k := range slices.Sorted(maps.Keys(event.Fields)) {
+		kv, err := otlpAttr(k, event.Fields[k])
+		if err != nil {
+			return otlpLogRecord{}, err
+		}
+		record.Attributes = append(record.Attributes, kv)
+	}
+	return record, nil
+}
+
+// otlpAttr converts a data value to an OTLP attribute. Scalars keep their
+// type; anything else is encoded as a JSON string.
+func otlpAttr(key string, v any) (otlpKeyValue, error) {
+	var value otlpAnyValue
+	switch x := v.(type) {
+	case string:
+		value.StringValue = &x
+	case bool:
+		value.BoolValue = &x
+	case int:
+		value = otlpInt(int64(x))
+	case int32:
+		value = otlpInt(int64(x))
+	case int64:
+		value = otlpInt(x)
+	case uint32:
+		value = otlpInt(int64(x))
+	case float32:
+		f := float64(x)
+		value.DoubleValue = &f
+	case float64:
+		value.DoubleValue = &x
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return otlpKeyValue{}, fmt.Errorf("monitor: failed to marshal %s: %w", key, err)
+		}
+		s := string(b)
+		value.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: value}, nil
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpStringValue(value)}
+}
+
+func otlpStringValue(s string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &s}
+}
+
+// otlpInt encodes an integer the way OTLP/JSON expects: as a decimal string.
+func otlpInt(n int64) otlpAnyValue {
+	s := strconv.FormatInt(n, 10)
+	return otlpAnyValue{IntValue: &s}
+}
+
+// otlpTraceID strips separators from a go-monitor trace ID: the dashes of a
+// UUID, and the "1-" version prefix and dashes of an AWS X-Ray root.
+func otlpTraceID(id string) string {
+	if strings.HasPrefix(id, "1-") && strings.Count(id, "-") == 2 {
+		id = id[2:]
+	}
+	return strings.ReplaceAll(id, "-", "")
+}
+
+// otlpHexID returns id lowercased if it is already n bytes of non-zero hex,
+// otherwise the first n bytes of its SHA-256, so IDs in other formats still
+// group consistently.
+func otlpHexID(id string, n int) string {
+	lower := strings.ToLower(id)
+	if len(lower) == 2*n && strings.Trim(lower, "0") != "" {
+		if _, err := hex.DecodeString(lower); err == nil {
+			return lower
+		}
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:n])
+}
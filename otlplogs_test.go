@@ -0,0 +1,166 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// otlpAttrs returns a record's attributes keyed by name.
+func otlpAttrs(record otlpLogRecord) map[string]otlpAnyValue {
+	attrs := make(map[string]otlpAnyValue, len(record.Attributes))
+	for _, kv := range record.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestOTLPRecord(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	event := Event{
+		Timestamp:    ts.Format(time.RFC3339Nano),
+		Service:      "api",
+		Env:          "prod",
+		JobID:        "job-1",
+		RequestID:    "req-1",
+		TraceID:      "4bf92f35-77b3-4da6-a3ce-929d0e0e4736",
+		SpanID:       "00f067aa0ba902b7",
+		Name:         "order.created",
+		Level:        LevelWarn,
+		Data:         map[string]any{"amount": 120, "paid": true, "ratio": 0.5, "items": []string{"a"}},
+		Correlations: map[string]string{"order": "ord-1"},
+	}
+
+	payload, encoded, failed, err := encodeOTLPLogs([]Event{event})
+	if err != nil || len(failed) != 0 || len(encoded) != 1 {
+		t.Fatalf("encodeOTLPLogs() = %d encoded, %d failed, %v", len(encoded), len(failed), err)
+	}
+	var req otlpLogsRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		t.Fatalf("invalid OTLP JSON: %v", err)
+	}
+	if len(req.ResourceLogs) != 1 || len(req.ResourceLogs[0].ScopeLogs) != 1 {
+		t.Fatalf("resourceLogs = %+v, want one resource with one scope", req.ResourceLogs)
+	}
+	resource := otlpAttrs(otlpLogRecord{Attributes: req.ResourceLogs[0].Resource.Attributes})
+	if v := resource["service.name"].StringValue; v == nil || *v != "api" {
+		t.Errorf("service.name = %v, want api", v)
+	}
+	if v := resource["deployment.environment"].StringValue; v == nil || *v != "prod" {
+		t.Errorf("deployment.environment = %v, want prod", v)
+	}
+
+	records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(records) != 1 {
+		t.Fatalf("logRecords = %d, want 1", len(records))
+	}
+	r := records[0]
+	if r.TimeUnixNano != "1714564800123456789" {
+		t.Errorf("timeUnixNano = %s, want 1714564800123456789", r.TimeUnixNano)
+	}
+	if r.SeverityNumber != 13 || r.SeverityText != "warn" {
+		t.Errorf("severity = %d %q, want 13 warn", r.SeverityNumber, r.SeverityText)
+	}
+	if r.Body.StringValue == nil || *r.Body.StringValue != "order.created" {
+		t.Errorf("body = %+v, want order.created", r.Body)
+	}
+	if r.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || r.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("traceId, spanId = %s, %s", r.TraceID, r.SpanID)
+	}
+
+	attrs := otlpAttrs(r)
+	for key, want := range map[string]string{"job_id": "job-1", "request_id": "req-1", "correlations.order": "ord-1", "data.items": `["a"]`} {
+		if v := attrs[key].StringValue; v == nil || *v != want {
+			t.Errorf("%s = %v, want %s", key, v, want)
+		}
+	}
+	if v := attrs["data.amount"].IntValue; v == nil || *v != "120" {
+		t.Errorf("data.amount = %v, want intValue 120", v)
+	}
+	if v := attrs["data.paid"].BoolValue; v == nil || !*v {
+		t.Errorf("data.paid = %v, want true", v)
+	}
+	if v := attrs["data.ratio"].DoubleValue; v == nil || *v != 0.5 {
+		t.Errorf("data.ratio = %v, want 0.5", v)
+	}
+}
+
+func TestOTLPSeverity(t *testing.T) {
+	for level, want := range map[string]int{
+		LevelDebug: 5, LevelInfo: 9, LevelAudit: 9, LevelWarn: 13, LevelError: 17, LevelFatal: 21,
+	} {
+		r, err := otlpRecord(Event{Name: "x", Level: level})
+		if err != nil {
+			t.Fatalf("otlpRecord(%s) error = %v", level, err)
+		}
+		if r.SeverityNumber != want || r.SeverityText != level {
+			t.Errorf("%s: severity = %d %q, want %d", level, r.SeverityNumber, r.SeverityText, want)
+		}
+	}
+}
+
+func TestExportFormatOTLPLogs(t *testing.T) {
+	var mu sync.Mutex
+	var contentType string
+	var requests []otlpLogsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req otlpLogsRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid OTLP JSON: %v", err)
+		}
+		mu.Lock()
+		contentType = r.Header.Get("Content-Type")
+		requests = append(requests, req)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	if err := Init(Config{
+		Service:       "test-otlp",
+		IngestURL:     server.URL,
+		ExportFormat:  ExportFormatOTLPLogs,
+		DisableStdout: true,
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+	if err := Verify(*defaultMonitor.config.Load()); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	Error(context.Background(), "test.otlp.error", nil)
+	Info(context.Background(), "test.otlp.info", nil)
+	Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+	var records []otlpLogRecord
+	for _, req := range requests {
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				records = append(records, sl.LogRecords...)
+			}
+		}
+	}
+	if len(records) != 2 {
+		t.Fatalf("shipped %d log records, want 2", len(records))
+	}
+	if records[0].SeverityNumber != 17 || *records[0].Body.StringValue != "test.otlp.error" {
+		t.Errorf("first record = %d %s, want 17 test.otlp.error", records[0].SeverityNumber, *records[0].Body.StringValue)
+	}
+}
+
+func TestExportFormatValidation(t *testing.T) {
+	if err := Init(Config{Service: "test-otlp", ExportFormat: "protobuf"}); err == nil {
+		t.Error("Init() accepted an unknown ExportFormat")
+	}
+}
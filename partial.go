@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// maxPartialFailureBody bounds how much of an ingest response is read when
+// looking for partial-failure details.
+const maxPartialFailureBody = 1 << 20
+
+// PartialFailureParser inspects a successful (2xx) ingest response and reports
+// the zero-based indices, in NDJSON line order, of events the endpoint failed
+// to ingest. It returns ok=false when the response carries no partial-failure
+// information. An empty slice with ok=true means every event was accepted.
+//
+// A 207 Multi-Status response for which ok is false is treated as a failure
+// of the whole batch and retried in full.
+type PartialFailureParser func(statusCode int, body []byte) (failed []int, ok bool)
+
+// DefaultPartialFailureParser handles 207 Multi-Status responses with a JSON
+// body of the form {"failed": [0, 3, 7]}. All other responses report no
+// partial-failure information.
+func DefaultPartialFailureParser(statusCode int, body []byte) ([]int, bool) {
+	if statusCode != http.StatusMultiStatus {
+		return nil, false
+	}
+
+	var resp struct {
+		Failed *[]int `json:"failed"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Failed == nil {
+		return nil, false
+	}
+	return *resp.Failed, true
+}
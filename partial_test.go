@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDefaultPartialFailureParser(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantFailed []int
+		wantOK     bool
+	}{
+		{"200 ignored", http.StatusOK, `{"failed":[1]}`, nil, false},
+		{"207 with failures", http.StatusMultiStatus, `{"failed":[0,2]}`, []int{0, 2}, true},
+		{"207 all accepted", http.StatusMultiStatus, `{"failed":[]}`, []int{}, true},
+		{"207 without details", http.StatusMultiStatus, `{"status":"partial"}`, nil, false},
+		{"207 invalid JSON", http.StatusMultiStatus, `not json`, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed, ok := DefaultPartialFailureParser(tt.status, []byte(tt.body))
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if len(failed) != len(tt.wantFailed) {
+				t.Fatalf("failed = %v, want %v", failed, tt.wantFailed)
+			}
+			for i := range failed {
+				if failed[i] != tt.wantFailed[i] {
+					t.Errorf("failed = %v, want %v", failed, tt.wantFailed)
+				}
+			}
+		})
+	}
+}
+
+// partialServer answers the first request with the given status and body,
+// then 200 OK, recording every request body.
+func partialServer(status int, body string) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		first := len(bodies) == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), bodies...)
+	}
+}
+
+func partialTestShipper(url string, parser PartialFailureParser) *shipper {
+	s := newShipper(defaultMonitor, &Config{
+		Service:              "test-partial",
+		IngestURL:            url,
+		BatchSize:            10,
+		FlushEvery:           time.Second,
+		PartialFailureParser: parser,
+	})
+	for _, name := range []string{"event.a", "event.b", "event.c"} {
+		s.events.push(Event{Name: name, Level: "info"})
+	}
+	return s
+}
+
+func TestShipperPartialFailure(t *testing.T) {
+	t.Run("retries only failed events", func(t *testing.T) {
+		server, bodies := partialServer(http.StatusMultiStatus, `{"failed":[1]}`)
+		defer server.Close()
+
+		partialTestShipper(server.URL, nil).doFlush(context.Background())
+
+		got := bodies()
+		if len(got) != 2 {
+			t.Fatalf("requests = %d, want 2", len(got))
+		}
+		if strings.Count(got[1], "\n") != 1 || !strings.Contains(got[1], `"name":"event.b"`) {
+			t.Errorf("retry body = %q, want only event.b", got[1])
+		}
+	})
+
+	t.Run("whole batch retried without details", func(t *testing.T) {
+		server, bodies := partialServer(http.StatusMultiStatus, `{}`)
+		defer server.Close()
+
+		partialTestShipper(server.URL, nil).doFlush(context.Background())
+
+		got := bodies()
+		if len(got) != 2 {
+			t.Fatalf("requests = %d, want 2", len(got))
+		}
+		if got[1] != got[0] {
+			t.Errorf("retry body = %q, want whole batch %q", got[1], got[0])
+		}
+	})
+
+	t.Run("custom parser", func(t *testing.T) {
+		server, bodies := partialServer(http.StatusOK, `{"errors":"event.c"}`)
+		defer server.Close()
+
+		parser := func(status int, body []byte) ([]int, bool) {
+			if strings.Contains(string(body), "event.c") {
+				return []int{2}, true
+			}
+			return nil, false
+		}
+		partialTestShipper(server.URL, parser).doFlush(context.Background())
+
+		got := bodies()
+		if len(got) != 2 {
+			t.Fatalf("requests = %d, want 2", len(got))
+		}
+		if strings.Count(got[1], "\n") != 1 || !strings.Contains(got[1], `"name":"event.c"`) {
+			t.Errorf("retry body = %q, want only event.c", got[1])
+		}
+	})
+}
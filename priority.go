@@ -0,0 +1,163 @@
+package monitor
+
+import "time"
+
+// Event priorities. When the shipper can't send everything at once (a backlog
+// larger than BatchSize, or a flush deadline), higher-priority events leave
+// the buffer first. Events of equal priority keep their emit order.
+const (
+	// PriorityLow is the default for debug events.
+	PriorityLow = iota
+
+	// PriorityNormal is the default for info events and unknown levels.
+	PriorityNormal
+
+	// PriorityHigh is the default for warn events.
+	PriorityHigh
+
+	// PriorityCritical is the default for error and fatal events.
+	PriorityCritical
+
+	numPriorities
+)
+
+// levelPriority returns the default priority for a level.
+func levelPriority(level string) int {
+	switch level {
+	case LevelDebug:
+		return PriorityLow
+	case LevelWarn:
+		return PriorityHigh
+	case LevelError, LevelFatal, LevelAudit:
+		return PriorityCritical
+	default:
+		return PriorityNormal
+	}
+}
+
+// WithPriority overrides the priority derived from the event's level (e.g.,
+// PriorityCritical for an info-level audit event that must not be lost).
+// Values outside PriorityLow..PriorityCritical are clamped.
+func WithPriority(priority int) EmitOption {
+	return func(o *emitOptions) {
+		o.priority = min(max(priority, PriorityLow), PriorityCritical)
+		o.hasPriority = true
+	}
+}
+
+// eventBuffer is the shipper's batch buffer: one FIFO queue per priority.
+// It is not safe for concurrent use; the shipper guards it with its mutex.
+type eventBuffer struct {
+	queues [numPriorities][]Event
+	n      int
+
+	// bytes is the total NDJSON size of the events with a cached line.
+	bytes int
+}
+
+// lineSize is an event's size in an NDJSON payload, counting the newline, or
+// 0 if its encoding isn't cached.
+func lineSize(e Event) int {
+	if e.line == nil {
+		return 0
+	}
+	return len(e.line) + 1
+}
+
+// len returns the number of buffered events.
+func (b *eventBuffer) len() int {
+	return b.n
+}
+
+// push appends events to the back of their priority queues, stamping each
+// with its enqueue time if it doesn't have one yet.
+func (b *eventBuffer) push(events ...Event) {
+	now := time.Now()
+	for _, e := range events {
+		if e.queuedAt.IsZero() {
+			e.queuedAt = now
+		}
+		b.queues[e.priority] = append(b.queues[e.priority], e)
+		b.bytes += lineSize(e)
+	}
+	b.n += len(events)
+}
+
+// pushFront puts events back at the front of their priority queues, keeping
+// their relative order, so a requeued batch goes out before newer events.
+func (b *eventBuffer) pushFront(events []Event) {
+	var byPriority [numPriorities][]Event
+	for _, e := range events {
+		byPriority[e.priority] = append(byPriority[e.priority], e)
+		b.bytes += lineSize(e)
+	}
+	for p, front := range byPriority {
+		if len(front) > 0 {
+			b.queues[p] = append(front, b.queues[p]...)
+		}
+	}
+	b.n += len(events)
+}
+
+// oldest returns the earliest enqueue time among the buffered events, or
+// false if the buffer is empty. Each queue's head is its oldest event, since
+// push appends and pushFront only returns events enqueued earlier.
+func (b *eventBuffer) oldest() (time.Time, bool) {
+	var oldest time.Time
+	for _, q := range b.queues {
+		if len(q) > 0 && (oldest.IsZero() || q[0].queuedAt.Before(oldest)) {
+			oldest = q[0].queuedAt
+		}
+	}
+	return oldest, !oldest.IsZero()
+}
+
+// take removes and returns up to max events, highest priority first. If
+// maxBytes is positive, it stops before the event that would take the batch's
+// cached lines past maxBytes, but always returns at least one event.
+func (b *eventBuffer) take(max, maxBytes int) []Event {
+	if b.n == 0 {
+		return nil
+	}
+	batch := make([]Event, 0, min(max, b.n))
+	size := 0
+	full := false
+	for p := numPriorities - 1; p >= 0 && len(batch) < max && !full; p-- {
+		q := b.queues[p]
+		k := 0
+		for ; k < len(q) && len(batch) < max; k++ {
+			n := lineSize(q[k])
+			if maxBytes > 0 && len(batch) > 0 && size+n > maxBytes {
+				full = true
+				break
+			}
+			size += n
+			batch = append(batch, q[k])
+		}
+		if k == len(q) {
+			b.queues[p] = nil
+		} else {
+			b.queues[p] = q[k:]
+		}
+	}
+	b.n -= len(batch)
+	b.bytes -= size
+	return batch
+}
+
+// trim drops events until at most max remain, newest first from the lowest
+// priority, and returns the dropped events.
+func (b *eventBuffer) trim(max int) []Event {
+	var dropped []Event
+	for p := 0; p < numPriorities && b.n > max; p++ {
+		q := b.queues[p]
+		k := min(b.n-max, len(q))
+		for _, e := range q[len(q)-k:] {
+			b.bytes -= lineSize(e)
+		}
+		dropped = append(dropped, q[len(q)-k:]...)
+		b.queues[p] = q[:len(q)-k]
+		b.n -= k
+	}
+	return dropped
+}
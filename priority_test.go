@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func eventNames(events []Event) []string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = e.Name
+	}
+	return names
+}
+
+func TestEventBuffer(t *testing.T) {
+	var b eventBuffer
+	b.push(
+		Event{Name: "debug.1", priority: PriorityLow},
+		Event{Name: "info.1", priority: PriorityNormal},
+		Event{Name: "error.1", priority: PriorityCritical},
+		Event{Name: "info.2", priority: PriorityNormal},
+		Event{Name: "error.2", priority: PriorityCritical},
+	)
+
+	got := eventNames(b.take(3, 0))
+	want := []string{"error.1", "error.2", "info.1"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("take(3) = %v, want %v", got, want)
+	}
+
+	// A requeued batch goes back ahead of newer events of the same priority
+	b.pushFront([]Event{{Name: "info.0", priority: PriorityNormal}})
+	got = eventNames(b.take(10, 0))
+	want = []string{"info.0", "info.2", "debug.1"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("take(10) = %v, want %v", got, want)
+	}
+	if b.len() != 0 {
+		t.Errorf("len() = %d, want 0", b.len())
+	}
+
+	t.Run("oldest keeps the first enqueue time", func(t *testing.T) {
+		var b eventBuffer
+		if _, ok := b.oldest(); ok {
+			t.Error("oldest() ok = true for an empty buffer")
+		}
+		b.push(Event{Name: "info.1", priority: PriorityNormal})
+		requeued := b.take(1, 0)
+		first := requeued[0].queuedAt
+		time.Sleep(time.Millisecond)
+		b.push(Event{Name: "error.1", priority: PriorityCritical})
+		b.pushFront(requeued)
+		if got, ok := b.oldest(); !ok || !got.Equal(first) {
+			t.Errorf("oldest() = %v, %v; want the requeued event's %v", got, ok, first)
+		}
+	})
+}
+
+func TestWithPriority(t *testing.T) {
+	if err := Init(Config{Service: "test-priority", DisableStdout: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		level string
+		opts  []EmitOption
+		want  int
+	}{
+		{"debug default", LevelDebug, nil, PriorityLow},
+		{"info default", LevelInfo, nil, PriorityNormal},
+		{"warn default", LevelWarn, nil, PriorityHigh},
+		{"error default", LevelError, nil, PriorityCritical},
+		{"override", LevelInfo, []EmitOption{WithPriority(PriorityCritical)}, PriorityCritical},
+		{"clamped", LevelInfo, []EmitOption{WithPriority(99)}, PriorityCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &emitOptions{}
+			for _, opt := range tt.opts {
+				opt(o)
+			}
+			event := newEvent(context.Background(), defaultMonitor.config.Load(), "test.priority", nil, tt.level)
+			o.applyTo(&event)
+			if event.priority != tt.want {
+				t.Errorf("priority = %d, want %d", event.priority, tt.want)
+			}
+		})
+	}
+
+	t.Run("follows a level changed by a processor", func(t *testing.T) {
+		escalate := func(event *Event) { event.Level = LevelError }
+		m, err := New(Config{Service: "test-priority", DisableStdout: true, Processors: []Processor{escalate}})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer m.Shutdown()
+		cfg := m.config.Load()
+
+		event := newEvent(context.Background(), cfg, "test.priority", nil, LevelDebug)
+		_ = m.outputEvent(cfg, &event)
+		if event.priority != PriorityCritical {
+			t.Errorf("priority = %d, want %d from the processor's level", event.priority, PriorityCritical)
+		}
+
+		event = newEvent(context.Background(), cfg, "test.priority", nil, LevelDebug)
+		o := &emitOptions{}
+		WithPriority(PriorityLow)(o)
+		o.applyTo(&event)
+		_ = m.outputEvent(cfg, &event)
+		if event.priority != PriorityLow {
+			t.Errorf("priority = %d, want WithPriority's %d kept", event.priority, PriorityLow)
+		}
+	})
+}
+
+func TestShipperFlushesByPriority(t *testing.T) {
+	rt := &recordingTransport{}
+	s := newShipper(defaultMonitor, &Config{Service: "test-priority", Transport: rt, BatchSize: 2, FlushEvery: time.Second})
+
+	ctx := context.Background()
+	s.events.push(
+		newEvent(ctx, defaultMonitor.config.Load(), "routine.1", nil, LevelDebug),
+		newEvent(ctx, defaultMonitor.config.Load(), "routine.2", nil, LevelInfo),
+		newEvent(ctx, defaultMonitor.config.Load(), "failure", nil, LevelError),
+		newEvent(ctx, defaultMonitor.config.Load(), "routine.3", nil, LevelDebug),
+		newEvent(ctx, defaultMonitor.config.Load(), "slow", nil, LevelWarn),
+	)
+	if err := s.doFlush(ctx); err != nil {
+		t.Fatalf("doFlush() error = %v", err)
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	var got []string
+	for _, batch := range rt.batches {
+		got = append(got, eventNames(batch)...)
+	}
+	want := []string{"failure", "slow", "routine.2", "routine.1", "routine.3"}
+	if len(rt.batches) != 3 || len(got) != len(want) {
+		t.Fatalf("batches = %v, want 3 batches of %v", rt.batches, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("shipped order = %v, want %v", got, want)
+			break
+		}
+	}
+}
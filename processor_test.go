@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProcessors(t *testing.T) {
+	rt := &recordingTransport{}
+	var seenService, seenRequestID, seenTimestamp string
+
+	if err := Init(Config{
+		Service:       "test-processor",
+		Env:           "test",
+		DisableStdout: true,
+		Transport:     rt,
+		Processors: []Processor{
+			func(e *Event) {
+				seenService = e.Service + "/" + e.Env
+				seenRequestID = e.RequestID
+				seenTimestamp = e.Timestamp
+			},
+			// Derive a shard from the resolved trace ID
+			func(e *Event) {
+				if data, ok := e.Data.(map[string]any); ok && e.TraceID != "" {
+					data["trace_shard"] = e.TraceID[:2]
+				}
+			},
+		},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	ctx := WithTraceID(context.Background(), "ab12cd34")
+	ctx = WithRequestID(ctx, "req-proc")
+	Emit(ctx, "test.processed", map[string]any{"k": "v"})
+	Flush()
+
+	if seenService != "test-processor/test" || seenRequestID != "req-proc" || seenTimestamp == "" {
+		t.Errorf("processor saw service=%q request_id=%q timestamp=%q, want resolved fields", seenService, seenRequestID, seenTimestamp)
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if len(rt.batches) != 1 || len(rt.batches[0]) != 1 {
+		t.Fatalf("batches = %v, want one event", rt.batches)
+	}
+	data, _ := rt.batches[0][0].Data.(map[string]any)
+	if data["trace_shard"] != "ab" {
+		t.Errorf("trace_shard = %v, want ab", data["trace_shard"])
+	}
+	if _, ok := data["source_file"]; !ok {
+		t.Error("processors should run after source location is attached")
+	}
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Ingest protocols for Config.IngestProtocol.
+const (
+	// ProtocolAuto uses Go's default transport: HTTP/2 when a TLS endpoint
+	// negotiates it via ALPN, HTTP/1.1 otherwise.
+	ProtocolAuto = ""
+
+	// ProtocolHTTP1 always uses HTTP/1.1.
+	ProtocolHTTP1 = "http1"
+
+	// ProtocolHTTP2 always attempts HTTP/2 over TLS on a connection pool
+	// dedicated to the shipper, falling back to HTTP/1.1 for endpoints that
+	// don't negotiate it and for plain http:// URLs.
+	ProtocolHTTP2 = "h2"
+
+	// ProtocolH2C uses unencrypted HTTP/2 with prior knowledge for http://
+	// URLs. The endpoint must accept h2c; there is no fallback to HTTP/1.1.
+	ProtocolH2C = "h2c"
+)
+
+// ingestTransport returns the RoundTripper for the given protocol, or nil for
+// ProtocolAuto so http.Client uses http.DefaultTransport.
+func ingestTransport(protocol string) (http.RoundTripper, error) {
+	var p http.Protocols
+	switch protocol {
+	case ProtocolAuto:
+		return nil, nil
+	case ProtocolHTTP1:
+		p.SetHTTP1(true)
+	case ProtocolHTTP2:
+		p.SetHTTP1(true)
+		p.SetHTTP2(true)
+	case ProtocolH2C:
+		p.SetUnencryptedHTTP2(true)
+	default:
+		return nil, fmt.Errorf("monitor: unknown IngestProtocol %q", protocol)
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ForceAttemptHTTP2 = protocol != ProtocolHTTP1
+	t.Protocols = &p
+	return t, nil
+}
+
+// ingestClient returns the client for posting to the ingest endpoint:
+// Config.HTTPClient if set, otherwise a client with the given timeout over the
+// IngestProtocol transport.
+func ingestClient(cfg *Config, timeout time.Duration) *http.Client {
+	if cfg.HTTPClient != nil {
+		return cfg.HTTPClient
+	}
+	// Init and Verify have already validated the protocol
+	transport, _ := ingestTransport(cfg.IngestProtocol)
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
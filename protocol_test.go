@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// protoServer records the protocol of each request it receives.
+func protoServer(t *testing.T, h2c bool) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var protos []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		protos = append(protos, r.Proto)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	if h2c {
+		var p http.Protocols
+		p.SetHTTP1(true)
+		p.SetUnencryptedHTTP2(true)
+		server.Config.Protocols = &p
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), protos...)
+	}
+}
+
+func TestIngestProtocol(t *testing.T) {
+	tests := []struct {
+		name      string
+		protocol  string
+		h2cServer bool
+		wantProto string
+	}{
+		{"auto against HTTP/1.1", ProtocolAuto, false, "HTTP/1.1"},
+		{"http1", ProtocolHTTP1, true, "HTTP/1.1"},
+		{"h2 falls back to HTTP/1.1", ProtocolHTTP2, false, "HTTP/1.1"},
+		{"h2c", ProtocolH2C, true, "HTTP/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, protos := protoServer(t, tt.h2cServer)
+
+			s := newShipper(defaultMonitor, &Config{
+				Service:        "test-protocol",
+				IngestURL:      server.URL,
+				IngestProtocol: tt.protocol,
+				BatchSize:      10,
+				FlushEvery:     time.Second,
+			})
+			s.events.push(Event{Name: "test.protocol"})
+			s.doFlush(context.Background())
+
+			got := protos()
+			if len(got) != 1 || got[0] != tt.wantProto {
+				t.Errorf("request protocols = %v, want [%s]", got, tt.wantProto)
+			}
+		})
+	}
+
+	t.Run("unknown protocol", func(t *testing.T) {
+		if err := Init(Config{Service: "test-protocol", IngestProtocol: "spdy"}); err == nil {
+			t.Error("Init() should reject an unknown IngestProtocol")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRateLimited is returned by EmitSync and TryEmit for an event dropped by
+// Config.MaxEventsPerSecond.
+var ErrRateLimited = errors.New("monitor: event dropped by MaxEventsPerSecond")
+
+// rateLimiter is a token bucket implemented as a generic cell rate algorithm:
+// instead of counting tokens it tracks the theoretical arrival time (TAT) of
+// the next event, so allow is a single compare-and-swap with no lock.
+type rateLimiter struct {
+	// interval is the time one token takes to refill.
+	interval int64
+
+	// burst is how far TAT may run ahead of now: burst tokens' worth of intervals.
+	burst int64
+
+	// tat is the theoretical arrival time in nanoseconds since start.
+	tat atomic.Int64
+
+	start time.Time
+}
+
+// newRateLimiter returns the limiter configured by cfg, or nil if
+// MaxEventsPerSecond is not positive.
+func newRateLimiter(cfg *Config) *rateLimiter {
+	if cfg.MaxEventsPerSecond <= 0 {
+		return nil
+	}
+	burst := cfg.RateLimitBurst
+	if burst <= 0 {
+		burst = cfg.MaxEventsPerSecond
+	}
+	interval := int64(time.Second) / int64(cfg.MaxEventsPerSecond)
+	return &rateLimiter{
+		interval: interval,
+		burst:    int64(burst) * interval,
+		start:    time.Now(),
+	}
+}
+
+// allow reports whether an event may be emitted now, taking a token if so.
+// A nil *rateLimiter allows everything.
+func (l *rateLimiter) allow() bool {
+	if l == nil {
+		return true
+	}
+	now := int64(time.Since(l.start))
+	for {
+		tat := l.tat.Load()
+		next := max(tat, now) + l.interval
+		if next-now > l.burst {
+			return false
+		}
+		if l.tat.CompareAndSwap(tat, next) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxEventsPerSecond(t *testing.T) {
+	var dropped atomic.Int64
+	if err := Init(Config{
+		Service:            "test-rate-limit",
+		DisableStdout:      true,
+		MaxEventsPerSecond: 100,
+		RateLimitBurst:     10,
+		OnDrop: func(e Event, reason string) {
+			if reason == DropReasonRateLimited {
+				dropped.Add(1)
+			}
+		},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	const window = 200 * time.Millisecond
+	attempted := 0
+	events := Captured(func() {
+		var wg sync.WaitGroup
+		var n atomic.Int64
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for start := time.Now(); time.Since(start) < window; {
+					Emit(context.Background(), "test.flood", nil)
+					n.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		attempted = int(n.Load())
+	})
+
+	// The burst plus 100/s over the window, with slack for scheduling
+	limit := 10 + int(100*window.Seconds()) + 10
+	if len(events) > limit {
+		t.Errorf("emitted %d events in %v, want at most %d", len(events), window, limit)
+	}
+	if len(events) < 10 {
+		t.Errorf("emitted %d events, want at least the burst of 10", len(events))
+	}
+	if got := int(dropped.Load()); got != attempted-len(events) {
+		t.Errorf("OnDrop saw %d rate_limited drops, want %d", got, attempted-len(events))
+	}
+
+	// Audit events are never limited
+	audit := Captured(func() {
+		for range 5 {
+			EmitAudit(context.Background(), "test.audit", nil)
+		}
+	})
+	if len(audit) != 5 {
+		t.Errorf("emitted %d audit events while limited, want 5", len(audit))
+	}
+}
+
+func TestEmitSyncRateLimited(t *testing.T) {
+	if err := Init(Config{Service: "test-rate-limit", DisableStdout: true, MaxEventsPerSecond: 1}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	if err := EmitSync(context.Background(), "test.sync", nil); err != nil {
+		t.Fatalf("first EmitSync() error = %v", err)
+	}
+	if err := EmitSync(context.Background(), "test.sync", nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("second EmitSync() error = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	l := newRateLimiter(&Config{MaxEventsPerSecond: 1000, RateLimitBurst: 5})
+	allowed := 0
+	for range 20 {
+		if l.allow() {
+			allowed++
+		}
+	}
+	if allowed != 5 {
+		t.Errorf("allowed %d of an instant burst of 20, want 5", allowed)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if !l.allow() {
+		t.Error("no token available after refilling for 10ms at 1000/s")
+	}
+
+	if newRateLimiter(&Config{}) != nil || !(*rateLimiter)(nil).allow() {
+		t.Error("a zero MaxEventsPerSecond should disable the limiter")
+	}
+}
@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// recentEvents keeps the last Config.RingBufferSize output events, overwriting
+// the oldest once full.
+type recentEvents struct {
+	mu     sync.Mutex
+	events []Event
+	next   int  // index the next event is written to
+	full   bool // whether events has wrapped around
+}
+
+func newRecentEvents(size int) *recentEvents {
+	return &recentEvents{events: make([]Event, size)}
+}
+
+// add records event, overwriting the oldest if the buffer is full.
+func (r *recentEvents) add(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[r.next] = event
+	r.next++
+	if r.next == len(r.events) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// snapshot returns the recorded events, oldest first.
+func (r *recentEvents) snapshot() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]Event(nil), r.events[:r.next]...)
+	}
+	out := make([]Event, 0, len(r.events))
+	out = append(out, r.events[r.next:]...)
+	return append(out, r.events[:r.next]...)
+}
+
+// RecentEvents returns the last Config.RingBufferSize events output by the
+// default monitor, oldest first, as they were written: after processors and
+// redaction, and regardless of stdout or shipping. It returns nil when
+// RingBufferSize is unset. The slice is a copy, but event data is shared with
+// the emitted events and must not be modified.
+func RecentEvents() []Event {
+	return defaultMonitor.RecentEvents()
+}
+
+// RecentEvents is like the package-level RecentEvents, but returns m's events.
+func (m *Monitor) RecentEvents() []Event {
+	if r := m.recent.Load(); r != nil {
+		return r.snapshot()
+	}
+	return nil
+}
+
+// RecentEventsHandler serves RecentEvents as NDJSON, for inspecting a running
+// process. The events are unauthenticated; mount it behind whatever protects
+// other debug endpoints.
+//
+// Usage:
+//
+//	http.Handle("/debug/monitor/events", monitor.RecentEventsHandler())
+func RecentEventsHandler() http.Handler {
+	return defaultMonitor.RecentEventsHandler()
+}
+
+// RecentEventsHandler is like the package-level RecentEventsHandler, but
+// serves m's events.
+func (m *Monitor) RecentEventsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		for _, event := range m.RecentEvents() {
+			line, err := event.ToJSON()
+			if err != nil {
+				continue
+			}
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+		w.Header().Set("Content-Type", defaultIngestContentType)
+		_, _ = w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecentEvents(t *testing.T) {
+	m, err := New(Config{Service: "test-recent", DisableStdout: true, RingBufferSize: 3})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+
+	if got := m.RecentEvents(); len(got) != 0 {
+		t.Fatalf("RecentEvents() before emitting = %d events, want 0", len(got))
+	}
+	for _, name := range []string{"e1", "e2", "e3", "e4", "e5"} {
+		m.Emit(context.Background(), name, nil)
+	}
+
+	want := []string{"e3", "e4", "e5"}
+	got := m.RecentEvents()
+	if len(got) != len(want) {
+		t.Fatalf("RecentEvents() = %d events, want %d", len(got), len(want))
+	}
+	for i, e := range got {
+		if e.Name != want[i] {
+			t.Errorf("RecentEvents()[%d] = %s, want %s", i, e.Name, want[i])
+		}
+	}
+
+	// The result is a copy
+	got[0].Name = "changed"
+	if m.RecentEvents()[0].Name != "e3" {
+		t.Error("modifying the RecentEvents() result changed the buffer")
+	}
+
+	rec := httptest.NewRecorder()
+	m.RecentEventsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", ct)
+	}
+	var names []string
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("bad NDJSON line %q: %v", scanner.Text(), err)
+		}
+		names = append(names, e.Name)
+	}
+	if len(names) != len(want) || names[0] != "e3" || names[2] != "e5" {
+		t.Errorf("handler served %v, want %v", names, want)
+	}
+}
+
+func TestRecentEventsDisabled(t *testing.T) {
+	m, err := New(Config{Service: "test-recent", DisableStdout: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+
+	m.Emit(context.Background(), "e1", nil)
+	if got := m.RecentEvents(); got != nil {
+		t.Errorf("RecentEvents() = %v without RingBufferSize, want nil", got)
+	}
+}
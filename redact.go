@@ -0,0 +1,65 @@
+package monitor
+
+import "strings"
+
+// compileRedactKeys lowercases Config.RedactKeys into a set for
+// case-insensitive matching, or returns nil if there are none.
+func compileRedactKeys(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = true
+	}
+	return set
+}
+
+// redactData returns a copy of data with the value of every map key in keys
+// (lowercased) replaced by "[REDACTED]", at any depth, including maps inside
+// slices. Data that isn't a map is returned unchanged. The caller's maps and
+// slices are never modified.
+func redactData(data any, keys map[string]bool) any {
+	switch data.(type) {
+	case map[string]any, map[string]string:
+		return redactValue(data, keys)
+	}
+	return data
+}
+
+func redactValue(value any, keys map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, nested := range v {
+			if keys[strings.ToLower(k)] {
+				out[k] = redacted
+			} else {
+				out[k] = redactValue(nested, keys)
+			}
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			if keys[strings.ToLower(k)] {
+				s = redacted
+			}
+			out[k] = s
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, nested := range v {
+			out[i] = redactValue(nested, keys)
+		}
+		return out
+	case []map[string]any:
+		out := make([]map[string]any, len(v))
+		for i, nested := range v {
+			out[i] = redactValue(nested, keys).(map[string]any)
+		}
+		return out
+	}
+	return value
+}
@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactData(t *testing.T) {
+	keys := compileRedactKeys([]string{"password", "SSN"})
+
+	tests := []struct {
+		name string
+		data any
+		want any
+	}{
+		{
+			name: "top-level keys, any case",
+			data: map[string]any{"user": "ada", "Password": "hunter2", "ssn": "123-45-6789"},
+			want: map[string]any{"user": "ada", "Password": redacted, "ssn": redacted},
+		},
+		{
+			name: "nested maps",
+			data: map[string]any{
+				"user":    map[string]any{"name": "ada", "PASSWORD": "hunter2"},
+				"headers": map[string]string{"ssn": "123-45-6789", "accept": "json"},
+			},
+			want: map[string]any{
+				"user":    map[string]any{"name": "ada", "PASSWORD": redacted},
+				"headers": map[string]string{"ssn": redacted, "accept": "json"},
+			},
+		},
+		{
+			name: "slices of maps",
+			data: map[string]any{
+				"users": []any{map[string]any{"ssn": "1"}, "plain"},
+				"rows":  []map[string]any{{"password": "x", "id": 1}},
+			},
+			want: map[string]any{
+				"users": []any{map[string]any{"ssn": redacted}, "plain"},
+				"rows":  []map[string]any{{"password": redacted, "id": 1}},
+			},
+		},
+		{
+			name: "redacted key holding a map",
+			data: map[string]any{"password": map[string]any{"old": "a", "new": "b"}},
+			want: map[string]any{"password": redacted},
+		},
+		{
+			name: "non-map data unchanged",
+			data: "password=hunter2",
+			want: "password=hunter2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactData(tt.data, keys); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redactData() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("caller's data not modified", func(t *testing.T) {
+		nested := map[string]any{"password": "hunter2"}
+		list := []any{map[string]any{"ssn": "1"}}
+		data := map[string]any{"user": nested, "list": list, "ssn": "2"}
+		redactData(data, keys)
+
+		if nested["password"] != "hunter2" || list[0].(map[string]any)["ssn"] != "1" || data["ssn"] != "2" {
+			t.Errorf("redactData() modified the caller's data: %v", data)
+		}
+	})
+}
+
+func TestRedactKeysConfig(t *testing.T) {
+	out := captureStdout(t)
+	rt := &recordingTransport{}
+	if err := Init(Config{
+		Service:    "test-redact",
+		Transport:  rt,
+		RedactKeys: []string{"password"},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	data := map[string]any{"user": "ada", "password": "hunter2"}
+	Emit(context.Background(), "user.login", data)
+	Flush()
+
+	if lines := out.lines(); len(lines) != 1 || strings.Contains(lines[0], "hunter2") || !strings.Contains(lines[0], redacted) {
+		t.Errorf("stdout = %v, want the password redacted", lines)
+	}
+	rt.mu.Lock()
+	shipped := rt.batches[0][0].Data.(map[string]any)
+	rt.mu.Unlock()
+	if shipped["password"] != redacted {
+		t.Errorf("shipped data = %v, want the password redacted", shipped)
+	}
+	if data["password"] != "hunter2" {
+		t.Error("Emit modified the caller's map")
+	}
+}
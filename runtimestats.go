@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// runtimeStatsEmitter periodically emits a "runtime.stats" event.
+type runtimeStatsEmitter struct {
+	monitor *Monitor
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+func newRuntimeStatsEmitter(m *Monitor, interval time.Duration) *runtimeStatsEmitter {
+	r := &runtimeStatsEmitter{
+		monitor: m,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	go r.run(interval)
+	return r
+}
+
+func (r *runtimeStatsEmitter) run(interval time.Duration) {
+	defer close(r.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Not tied to any request, so emitted with a background context
+			r.monitor.emitInternal(context.Background(), "runtime.stats", runtimeStats(), LevelInfo)
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+func (r *runtimeStatsEmitter) stop() {
+	close(r.stopCh)
+	<-r.doneCh
+}
+
+// runtimeStats returns the runtime.stats event data.
+func runtimeStats() map[string]any {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	return map[string]any{
+		"goroutines":        runtime.NumGoroutine(),
+		"heap_alloc_bytes":  m.HeapAlloc,
+		"heap_inuse_bytes":  m.HeapInuse,
+		"heap_objects":      m.HeapObjects,
+		"sys_bytes":         m.Sys,
+		"total_alloc_bytes": m.TotalAlloc,
+		"num_gc":            m.NumGC,
+		"gc_pause_total_ms": time.Duration(m.PauseTotalNs).Milliseconds(),
+		"gc_cpu_fraction":   m.GCCPUFraction,
+	}
+}
@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRuntimeStatsInterval(t *testing.T) {
+	if err := Init(Config{Service: "test-runtime-stats", DisableStdout: true, RuntimeStatsInterval: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	events := Captured(func() {
+		time.Sleep(100 * time.Millisecond)
+	})
+	if len(events) == 0 {
+		t.Fatal("no runtime.stats events emitted")
+	}
+	e := events[0]
+	if e.Name != "runtime.stats" || e.Service != "test-runtime-stats" {
+		t.Errorf("event = %s from %s, want runtime.stats from test-runtime-stats", e.Name, e.Service)
+	}
+	data, _ := e.Data.(map[string]any)
+	if g, _ := data["goroutines"].(int); g <= 0 {
+		t.Errorf("goroutines = %v, want > 0", data["goroutines"])
+	}
+	if h, _ := data["heap_alloc_bytes"].(uint64); h == 0 {
+		t.Errorf("heap_alloc_bytes = %v, want > 0", data["heap_alloc_bytes"])
+	}
+
+	Shutdown()
+	if defaultMonitor.runtimeStats.Load() != nil {
+		t.Fatal("runtime stats emitter still registered after Shutdown")
+	}
+	after := Captured(func() {
+		time.Sleep(50 * time.Millisecond)
+	})
+	if len(after) != 0 {
+		t.Errorf("emitted %d events after Shutdown, want 0", len(after))
+	}
+}
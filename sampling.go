@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand/v2"
+)
+
+// WithSampleRate returns a new context whose events are sampled at rate
+// (clamped to 0..1) instead of Config.SampleRate, letting a handler thin out
+// its own low-value events. The override replaces the global rate rather than
+// multiplying it, and applies to events emitted with the context or one
+// derived from it.
+func WithSampleRate(ctx context.Context, rate float64) context.Context {
+	return context.WithValue(ctx, ctxKeySampleRate, min(max(rate, 0), 1))
+}
+
+// WithSampled returns a new context carrying a trace-level sampling decision,
+// typically made by Middleware from Config.DefaultSampleRate or a trusted
+// HeaderSampled. Events emitted with a context marked not sampled are dropped
+// regardless of SampleRate (reported to OnDrop with
+// DropReasonTraceNotSampled), except error, fatal, and audit events, which
+// are sampled as if no decision had been made. Those marked sampled are kept
+// regardless of SampleRate. InjectHeaders forwards the decision to downstream
+// services.
+func WithSampled(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, ctxKeySampled, sampled)
+}
+
+// Sampled returns the context's trace-level sampling decision. ok is false if
+// none has been made.
+func Sampled(ctx context.Context) (sampled, ok bool) {
+	sampled, ok = ctx.Value(ctxKeySampled).(bool)
+	return sampled, ok
+}
+
+// parseSampledHeader parses a HeaderSampled value: "1" or "0" (or "true" or
+// "false"). ok is false for anything else, including an absent header.
+func parseSampledHeader(value string) (sampled, ok bool) {
+	switch value {
+	case "1", "true":
+		return true, true
+	case "0", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// formatSampledHeader formats a sampling decision for HeaderSampled.
+func formatSampledHeader(sampled bool) string {
+	if sampled {
+		return "1"
+	}
+	return "0"
+}
+
+// traceDecision returns the WithSampled decision that applies to an event at
+// level: a "not sampled" decision doesn't apply to error, fatal, or audit
+// events.
+func traceDecision(ctx context.Context, level string) (sampled, ok bool) {
+	sampled, ok = Sampled(ctx)
+	if ok && !sampled {
+		switch level {
+		case LevelError, LevelFatal, LevelAudit:
+			return false, false
+		}
+	}
+	return sampled, ok
+}
+
+// traceUnsampled reports whether a "not sampled" decision in ctx applies to
+// an event at level, in which case the event is dropped and, if OnDrop is
+// set, built and reported with DropReasonTraceNotSampled.
+func (m *Monitor) traceUnsampled(ctx context.Context, cfg *Config, name string, data any, level string) bool {
+	if s, ok := traceDecision(ctx, level); !ok || s {
+		return false
+	}
+	if cfg.OnDrop != nil {
+		cfg.OnDrop(newEvent(ctx, cfg, name, data, level), DropReasonTraceNotSampled)
+	}
+	return true
+}
+
+// sampleRate returns the context's override if set, otherwise the global rate.
+func sampleRate(ctx context.Context, cfg *Config) float64 {
+	if rate, ok := ctx.Value(ctxKeySampleRate).(float64); ok {
+		return rate
+	}
+	return cfg.SampleRate
+}
+
+// sampled reports whether an event emitted with ctx should be kept. Events
+// with a trace ID are decided by a hash of it, so at a given rate a trace is
+// kept or dropped as a whole (head-based); other events are decided at random.
+// A lower override therefore drops a subset of the traces the global rate keeps.
+// A decision set with WithSampled that applies at level takes precedence over
+// both.
+func sampled(ctx context.Context, cfg *Config, level string) bool {
+	if s, ok := traceDecision(ctx, level); ok {
+		return s
+	}
+	rate := sampleRate(ctx, cfg)
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	if traceID := TraceID(ctx); traceID != "" {
+		return traceFraction(traceID) < rate
+	}
+	return rand.Float64() < rate
+}
+
+// nameSampleRate returns Config.SampleRates[name], or 1 if name has no rate.
+func nameSampleRate(cfg *Config, name string) float64 {
+	if rate, ok := cfg.SampleRates[name]; ok {
+		return rate
+	}
+	return 1
+}
+
+// sampledAt reports whether to keep an event sampled at random at rate.
+func sampledAt(rate float64) bool {
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// annotateSampleRate records the fraction of events like this one that are
+// kept under data._sample_rate.
+func annotateSampleRate(event *Event, rate float64) {
+	addDataFields(event, map[string]any{"_sample_rate": rate})
+}
+
+// traceFraction maps a trace ID to a stable value in [0, 1).
+func traceFraction(traceID string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(traceID))
+	x := h.Sum64()
+
+	// FNV barely changes the high bits for IDs that differ only at the end
+	// (e.g., sequential IDs), so mix them with the splitmix64 finalizer
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11) / (1 << 53)
+}
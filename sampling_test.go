@@ -0,0 +1,292 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestSampleRate(t *testing.T) {
+	// emitTraces emits one event for each of n traces and returns how many were kept
+	emitTraces := func(ctx context.Context, n int) int {
+		events := Captured(func() {
+			for i := 0; i < n; i++ {
+				Emit(WithTraceID(ctx, fmt.Sprintf("trace-%d", i)), "test.sampled", nil)
+			}
+		})
+		return len(events)
+	}
+
+	t.Run("keeps everything by default", func(t *testing.T) {
+		// 0 is indistinguishable from unset, so it keeps everything too
+		if err := Init(Config{Service: "test-sampling", DisableStdout: true, SampleRate: 0}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if got := emitTraces(context.Background(), 100); got != 100 {
+			t.Errorf("kept %d of 100 events, want 100", got)
+		}
+	})
+
+	if err := Init(Config{Service: "test-sampling", DisableStdout: true, SampleRate: 0.5}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	t.Run("falls back to global rate", func(t *testing.T) {
+		if got := emitTraces(context.Background(), 1000); got < 400 || got > 600 {
+			t.Errorf("kept %d of 1000 events at rate 0.5, want about 500", got)
+		}
+	})
+
+	t.Run("context override", func(t *testing.T) {
+		if got := emitTraces(WithSampleRate(context.Background(), 0), 100); got != 0 {
+			t.Errorf("kept %d events at override 0, want 0", got)
+		}
+		if got := emitTraces(WithSampleRate(context.Background(), 1), 100); got != 100 {
+			t.Errorf("kept %d of 100 events at override 1, want 100", got)
+		}
+		if got := emitTraces(WithSampleRate(context.Background(), 0.1), 1000); got < 50 || got > 150 {
+			t.Errorf("kept %d of 1000 events at override 0.1, want about 100", got)
+		}
+	})
+
+	t.Run("trace kept or dropped as a whole", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			ctx := WithTraceID(context.Background(), fmt.Sprintf("trace-whole-%d", i))
+			events := Captured(func() {
+				for j := 0; j < 10; j++ {
+					Emit(ctx, "test.sampled", nil)
+				}
+			})
+			if len(events) != 0 && len(events) != 10 {
+				t.Fatalf("trace %d kept %d of 10 events, want all or none", i, len(events))
+			}
+		}
+	})
+
+	t.Run("invalid rate", func(t *testing.T) {
+		if err := Init(Config{Service: "test-sampling", DisableStdout: true, SampleRate: 1.5}); err == nil {
+			t.Error("Init() error = nil, want error for SampleRate > 1")
+		}
+	})
+}
+
+func TestSampleRates(t *testing.T) {
+	if err := Init(Config{
+		Service:       "test-sampling",
+		DisableStdout: true,
+		SampleRates:   map[string]float64{"hot.path": 0.1, "muted": 0},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	const n = 10000
+	events := Captured(func() {
+		for i := 0; i < n; i++ {
+			Emit(context.Background(), "hot.path", map[string]any{"i": i})
+			Info(context.Background(), "muted", nil)
+		}
+		Emit(context.Background(), "cold.path", nil)
+	})
+
+	hot, cold := 0, 0
+	for _, e := range events {
+		data, _ := e.Data.(map[string]any)
+		switch e.Name {
+		case "hot.path":
+			hot++
+			if data["_sample_rate"] != 0.1 {
+				t.Fatalf("hot.path data = %v, want _sample_rate 0.1", data)
+			}
+		case "cold.path":
+			cold++
+			if _, ok := data["_sample_rate"]; ok {
+				t.Errorf("cold.path data = %v, want no _sample_rate", data)
+			}
+		default:
+			t.Fatalf("unexpected event %s, want muted events dropped", e.Name)
+		}
+	}
+	if hot < n*8/100 || hot > n*12/100 {
+		t.Errorf("kept %d of %d hot.path events at rate 0.1, want about %d", hot, n, n/10)
+	}
+	if cold != 1 {
+		t.Errorf("kept %d cold.path events, want 1", cold)
+	}
+
+	t.Run("combined with SampleRate", func(t *testing.T) {
+		if err := Init(Config{
+			Service:       "test-sampling",
+			DisableStdout: true,
+			SampleRate:    0.5,
+			SampleRates:   map[string]float64{"hot.path": 0.5},
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		events := Captured(func() {
+			for i := 0; i < 100; i++ {
+				Emit(context.Background(), "hot.path", "raw")
+			}
+		})
+		if len(events) == 0 {
+			t.Fatal("no events kept")
+		}
+		data := events[0].Data.(map[string]any)
+		if data["_sample_rate"] != 0.25 || data["_data"] != "raw" {
+			t.Errorf("data = %v, want _sample_rate 0.25 with the original data under _data", data)
+		}
+	})
+
+	t.Run("caller's map not modified", func(t *testing.T) {
+		if err := Init(Config{Service: "test-sampling", DisableStdout: true, SampleRates: map[string]float64{"hot.path": 0.999}}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		data := map[string]any{"k": "v"}
+		events := Captured(func() {
+			for i := 0; i < 10; i++ {
+				Emit(context.Background(), "hot.path", data)
+			}
+		})
+		if len(events) == 0 {
+			t.Fatal("no events kept")
+		}
+		if len(data) != 1 {
+			t.Errorf("data = %v, want the caller's map unchanged", data)
+		}
+	})
+
+	t.Run("invalid rate", func(t *testing.T) {
+		if err := Init(Config{Service: "test-sampling", DisableStdout: true, SampleRates: map[string]float64{"x": 2}}); err == nil {
+			t.Error("Init() error = nil, want error for a rate > 1")
+		}
+	})
+}
+
+func TestSampledHeader(t *testing.T) {
+	// serve handles one request through Middleware, emitting an event and
+	// recording the headers a downstream call would carry
+	serve := func(header string, level string) (events []Event, outgoing http.Header) {
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Emit(r.Context(), "test.handled", nil, WithLevel(level))
+			outgoing = http.Header{}
+			InjectHeaders(r.Context(), outgoing)
+		}))
+		req := httptest.NewRequest("GET", "/test", nil)
+		if header != "" {
+			req.Header.Set(HeaderSampled, header)
+		}
+		events = Captured(func() {
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		})
+		return events, outgoing
+	}
+
+	var dropped []string
+	setup := func(defaultRate float64, trust bool) {
+		t.Helper()
+		dropped = nil
+		if err := Init(Config{
+			Service:            "test-sampled-header",
+			DisableStdout:      true,
+			SampleRate:         0.000001,
+			DefaultSampleRate:  defaultRate,
+			TrustSampledHeader: trust,
+			OnDrop: func(e Event, reason string) {
+				dropped = append(dropped, e.Name+":"+reason)
+			},
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+	}
+
+	t.Run("sampled=1", func(t *testing.T) {
+		setup(0, true)
+		events, outgoing := serve("1", LevelInfo)
+		if len(events) != 1 {
+			t.Errorf("kept %d events, want 1 despite SampleRate", len(events))
+		}
+		if got := outgoing.Get(HeaderSampled); got != "1" {
+			t.Errorf("outgoing %s = %q, want 1", HeaderSampled, got)
+		}
+	})
+
+	t.Run("sampled=0", func(t *testing.T) {
+		setup(1, true)
+		events, outgoing := serve("0", LevelInfo)
+		if len(events) != 0 {
+			t.Errorf("kept %d events, want 0", len(events))
+		}
+		if want := []string{"test.handled:" + DropReasonTraceNotSampled}; !slices.Equal(dropped, want) {
+			t.Errorf("OnDrop calls = %v, want %v", dropped, want)
+		}
+		if got := outgoing.Get(HeaderSampled); got != "0" {
+			t.Errorf("outgoing %s = %q, want 0", HeaderSampled, got)
+		}
+	})
+
+	t.Run("sampled=0 keeps errors", func(t *testing.T) {
+		// Sampled as if undecided, so SampleRate (1 here) applies instead
+		if err := Init(Config{
+			Service:            "test-sampled-header",
+			DisableStdout:      true,
+			TrustSampledHeader: true,
+			OnDrop: func(e Event, reason string) {
+				dropped = append(dropped, e.Name+":"+reason)
+			},
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		dropped = nil
+		for _, level := range []string{LevelError, LevelFatal, LevelAudit} {
+			if events, _ := serve("0", level); len(events) != 1 {
+				t.Errorf("kept %d %s events, want 1", len(events), level)
+			}
+		}
+		if len(dropped) != 0 {
+			t.Errorf("OnDrop calls = %v, want none", dropped)
+		}
+	})
+
+	t.Run("untrusted header ignored", func(t *testing.T) {
+		setup(1, false)
+		events, outgoing := serve("0", LevelInfo)
+		if len(events) != 1 {
+			t.Errorf("kept %d events, want 1 from DefaultSampleRate", len(events))
+		}
+		if got := outgoing.Get(HeaderSampled); got != "1" {
+			t.Errorf("outgoing %s = %q, want the local decision 1", HeaderSampled, got)
+		}
+
+		setup(0, false)
+		if events, _ := serve("1", LevelInfo); len(events) != 0 {
+			t.Errorf("kept %d events, want 0: the header must not bypass SampleRate", len(events))
+		}
+	})
+
+	t.Run("absent with DefaultSampleRate", func(t *testing.T) {
+		setup(1, false)
+		events, outgoing := serve("", LevelInfo)
+		if len(events) != 1 {
+			t.Errorf("kept %d events, want 1 with DefaultSampleRate 1", len(events))
+		}
+		if got := outgoing.Get(HeaderSampled); got != "1" {
+			t.Errorf("outgoing %s = %q, want the local decision 1", HeaderSampled, got)
+		}
+	})
+
+	t.Run("absent without DefaultSampleRate", func(t *testing.T) {
+		setup(0, false)
+		_, outgoing := serve("", LevelInfo)
+		if got := outgoing.Get(HeaderSampled); got != "" {
+			t.Errorf("outgoing %s = %q, want no decision", HeaderSampled, got)
+		}
+	})
+
+	t.Run("invalid DefaultSampleRate", func(t *testing.T) {
+		if err := Init(Config{Service: "test-sampled-header", DefaultSampleRate: 2}); err == nil {
+			t.Error("Init() error = nil, want error for DefaultSampleRate > 1")
+		}
+	})
+}
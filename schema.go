@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrSchemaViolation is returned by EmitSync and TryEmit for an event dropped by
+// Config.StrictSchema.
+var ErrSchemaViolation = errors.New("monitor: event violates its schema")
+
+// schemas holds the required data keys registered with RegisterSchema, by
+// event name.
+type schemas struct {
+	mu       sync.RWMutex
+	required map[string][]string
+}
+
+// RegisterSchema declares the data keys an event named name must carry. When
+// such an event is emitted without one of them, it is annotated with
+// data._schema_warnings listing what is missing, or with Config.StrictSchema
+// dropped and reported to OnDrop with DropReasonSchemaViolation. Events
+// without a registered schema are not checked. Registering a name again
+// replaces its schema; schemas persist across Init.
+//
+//	monitor.RegisterSchema("user.created", []string{"user_id", "plan"})
+func RegisterSchema(name string, required []string) {
+	defaultMonitor.RegisterSchema(name, required)
+}
+
+// RegisterSchema registers a schema for events emitted through m, like the
+// package-level RegisterSchema.
+func (m *Monitor) RegisterSchema(name string, required []string) {
+	m.schemas.mu.Lock()
+	defer m.schemas.mu.Unlock()
+	if m.schemas.required == nil {
+		m.schemas.required = make(map[string][]string)
+	}
+	m.schemas.required[name] = slices.Clone(required)
+}
+
+// missing returns the required keys of name's schema that data lacks, in
+// registration order, or nil if there are none or name has no schema.
+// Non-map data lacks every key.
+func (s *schemas) missing(name string, data any) []string {
+	s.mu.RLock()
+	required := s.required[name]
+	s.mu.RUnlock()
+
+	fields, _ := data.(map[string]any)
+	var out []string
+	for _, key := range required {
+		if _, ok := fields[key]; !ok {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// checkSchema validates event against its registered schema. A violation
+// drops the event with DropReasonSchemaViolation under Config.StrictSchema,
+// returning an error wrapping ErrSchemaViolation; otherwise the missing keys
+// are recorded under data._schema_warnings.
+func (m *Monitor) checkSchema(cfg *Config, event *Event) error {
+	missing := m.schemas.missing(event.Name, event.Data)
+	if len(missing) == 0 {
+		return nil
+	}
+	if cfg.StrictSchema {
+		m.dropUnshipped(cfg, *event, DropReasonSchemaViolation)
+		return fmt.Errorf("%w: %q is missing %s", ErrSchemaViolation, event.Name, strings.Join(missing, ", "))
+	}
+	warnings := make([]string, len(missing))
+	for i, key := range missing {
+		warnings[i] = fmt.Sprintf("missing required field %q", key)
+	}
+	addDataFields(event, map[string]any{"_schema_warnings": warnings})
+	return nil
+}
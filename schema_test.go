@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	m, err := New(Config{Service: "test-schema", DisableStdout: true, CaptureSource: new(bool)})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+	m.RegisterSchema("user.created", []string{"user_id", "plan"})
+
+	events := Captured(func() {
+		m.Emit(context.Background(), "user.created", map[string]any{"user_id": "u1", "plan": "pro"})
+		m.Emit(context.Background(), "user.created", map[string]any{"user_id": "u1"})
+		m.Emit(context.Background(), "user.deleted", map[string]any{"anything": true})
+	})
+	if len(events) != 3 {
+		t.Fatalf("captured %d events, want 3", len(events))
+	}
+
+	t.Run("satisfied", func(t *testing.T) {
+		if _, ok := events[0].Data.(map[string]any)["_schema_warnings"]; ok {
+			t.Errorf("data = %v, want no schema warnings", events[0].Data)
+		}
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		warnings, _ := events[1].Data.(map[string]any)["_schema_warnings"].([]string)
+		if !slices.Equal(warnings, []string{`missing required field "plan"`}) {
+			t.Errorf("_schema_warnings = %v, want the missing plan field", warnings)
+		}
+	})
+
+	t.Run("unregistered", func(t *testing.T) {
+		if got := events[2].Data.(map[string]any); len(got) != 1 || got["anything"] != true {
+			t.Errorf("data = %v, want it untouched", got)
+		}
+	})
+}
+
+func TestStrictSchema(t *testing.T) {
+	var dropped []Event
+	m, err := New(Config{
+		Service:       "test-schema",
+		DisableStdout: true,
+		StrictSchema:  true,
+		OnDrop: func(e Event, reason string) {
+			if reason == DropReasonSchemaViolation {
+				dropped = append(dropped, e)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+	m.RegisterSchema("order.paid", []string{"order_id", "amount"})
+
+	events := Captured(func() {
+		m.Emit(context.Background(), "order.paid", map[string]any{"order_id": "o1", "amount": 10})
+		m.Emit(context.Background(), "order.paid", map[string]any{"order_id": "o2"})
+		m.Emit(context.Background(), "order.paid", "not a map")
+		m.Emit(context.Background(), "order.refunded", nil)
+	})
+	if len(events) != 2 || events[0].Name != "order.paid" || events[1].Name != "order.refunded" {
+		t.Fatalf("captured %v, want the valid order.paid and the unregistered event", events)
+	}
+	if len(dropped) != 2 {
+		t.Errorf("OnDrop saw %d schema violations, want 2", len(dropped))
+	}
+
+	err = m.EmitSync(context.Background(), "order.paid", map[string]any{"amount": 5})
+	if !errors.Is(err, ErrSchemaViolation) {
+		t.Errorf("EmitSync() error = %v, want ErrSchemaViolation", err)
+	}
+}
@@ -2,69 +2,217 @@ package monitor
 
 import (
 	"bytes"
-	"compress/gzip"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // shipper handles async batching and shipping of events to an ingest URL.
 type shipper struct {
+	monitor  *Monitor
 	cfg      *Config
 	client   *http.Client
-	events   []Event
+	events   eventBuffer
 	mu       sync.Mutex
 	stopCh   chan struct{}
 	doneCh   chan struct{}
-	flushCh  chan chan struct{}
+	flushCh  chan flushRequest
 	eventsCh chan Event
+
+	// stopCtx bounds the final flush. Set by stopContext before it closes stopCh.
+	stopCtx context.Context
+
+	// queueHighWater is the deepest eventsCh has been since the shipper started.
+	queueHighWater atomic.Int64
+
+	// spill holds queue overflow on disk when Config.SpillDir is set.
+	spill *spillBuffer
+
+	// breaker pauses delivery to a failing endpoint. Nil unless
+	// Config.CircuitThreshold is set.
+	breaker *circuitBreaker
+
+	// deliveryOK reports whether the most recent batch reached the endpoint;
+	// spilled events are replayed only while it is true.
+	deliveryOK atomic.Bool
+
+	// Delivery counters and the last flush's outcome, read by Stats.
+	totalShipped atomic.Uint64
+	totalDropped atomic.Uint64
+	totalBatches atomic.Uint64
+	totalRetries atomic.Uint64
+	lastFlush    atomic.Pointer[flushResult]
+
+	// attemptErr is the latest delivery failure for the batch being shipped.
+	// Only the goroutine running doFlush touches it.
+	attemptErr error
+
+	// peers ship the same events to the endpoints in Config.IngestURLs and
+	// Config.Endpoints. They are started, flushed, and stopped with s.
+	peers []*shipper
 }
 
-// newShipper creates a new shipper with the given config.
-func newShipper(cfg *Config) *shipper {
-	return &shipper{
+// flushResult records when a flush that had events to ship finished, and the
+// error that kept its last undelivered batch from the endpoint, if any.
+type flushResult struct {
+	at  time.Time
+	err error
+}
+
+// newShipper creates a new shipper with the given config. m receives the
+// shipper's pipeline-health events.
+func newShipper(m *Monitor, cfg *Config) *shipper {
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = cfg.BatchSize * 2
+	}
+	s := &shipper{
+		monitor:  m,
 		cfg:      cfg,
-		client:   &http.Client{Timeout: 30 * time.Second},
-		events:   make([]Event, 0, cfg.BatchSize),
+		client:   ingestClient(cfg, 30*time.Second),
 		stopCh:   make(chan struct{}),
 		doneCh:   make(chan struct{}),
-		flushCh:  make(chan chan struct{}),
-		eventsCh: make(chan Event, cfg.BatchSize*2),
+		flushCh:  make(chan flushRequest),
+		eventsCh: make(chan Event, queueSize),
+		breaker:  newCircuitBreaker(cfg),
 	}
+	if cfg.SpillDir != "" {
+		sb, err := newSpillBuffer(cfg.SpillDir, cfg.MaxSpillBytes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v, spilling disabled\n", err)
+		} else {
+			s.spill = sb
+		}
+	}
+	return s
 }
 
-// start begins the shipper's background goroutine.
+// start begins the background goroutines of the shipper and its peers.
 func (s *shipper) start() {
-	go s.run()
+	for _, sh := range s.all() {
+		go sh.run()
+	}
 }
 
-// stop signals the shipper to stop and waits for it to finish.
+// stop signals the shipper and its peers to stop and waits for them to
+// finish.
 func (s *shipper) stop() {
-	close(s.stopCh)
-	<-s.doneCh
+	_ = s.stopContext(context.Background())
 }
 
-// send queues an event for shipping.
-func (s *shipper) send(event Event) {
+// stopContext signals the shipper and its peers to stop and waits for them
+// to finish their final flush. They drain concurrently, so a slow endpoint
+// doesn't delay the rest. When ctx is done first, the final flushes give up,
+// dropping what they couldn't deliver, and ctx.Err() is returned without
+// waiting further.
+func (s *shipper) stopContext(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, sh := range s.all() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sh.stopCtx = ctx
+			close(sh.stopCh)
+			select {
+			case <-sh.doneCh:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// ErrBufferFull is returned by TryEmit for an event dropped because the
+// shipper's queue was full (and SpillDir, if set, couldn't take it).
+var ErrBufferFull = errors.New("monitor: shipper buffer full")
+
+// send queues an event for shipping by the shipper and each of its peers. It
+// returns ErrBufferFull if any of them had to drop it.
+func (s *shipper) send(event Event) error {
+	var peerErr error
+	for _, p := range s.peers {
+		if err := p.send(event); err != nil {
+			peerErr = err
+		}
+	}
 	select {
 	case s.eventsCh <- event:
+		s.recordQueueDepth(len(s.eventsCh))
+		return peerErr
 	default:
-		// Channel full, drop event (could log this in debug mode)
+		if event.internal {
+			return ErrBufferFull
+		}
+		// Channel full: spill to disk if configured, otherwise drop
+		if s.spill != nil {
+			evicted, err := s.spill.write(event)
+			if len(evicted) > 0 {
+				s.emitBatchDropped(DropReasonSpillFull, evicted)
+				s.dropped(DropReasonSpillFull, evicted...)
+			}
+			if err == nil {
+				return peerErr
+			}
+			fmt.Fprintf(os.Stderr, "monitor: failed to spill event: %v\n", err)
+		}
 		fmt.Fprintf(os.Stderr, "monitor: shipper buffer full, dropping event\n")
+		s.monitor.emitSelf("monitor.event_dropped", map[string]any{
+			"reason":     DropReasonBufferFull,
+			"event_name": event.Name,
+		}, LevelWarn)
+		s.dropped(DropReasonBufferFull, event)
+		return ErrBufferFull
 	}
 }
 
+// recordQueueDepth raises the queue high-water mark to depth if it is deeper.
+func (s *shipper) recordQueueDepth(depth int) {
+	for {
+		high := s.queueHighWater.Load()
+		if int64(depth) <= high || s.queueHighWater.CompareAndSwap(high, int64(depth)) {
+			return
+		}
+	}
+}
+
+// flushRequest asks the run loop for a synchronous flush bounded by ctx.
+// done is buffered so the run loop never blocks on a caller that gave up.
+type flushRequest struct {
+	ctx  context.Context
+	done chan error
+}
+
 // flush synchronously flushes all buffered events.
 func (s *shipper) flush() {
-	done := make(chan struct{})
+	_ = s.flushContext(context.Background())
+}
+
+// flushOwn synchronously flushes all of s's buffered events, without its
+// peers, giving up when ctx is done. Events that couldn't be delivered in time
+// stay buffered for the next flush.
+func (s *shipper) flushOwn(ctx context.Context) error {
+	req := flushRequest{ctx: ctx, done: make(chan error, 1)}
 	select {
-	case s.flushCh <- done:
-		<-done
+	case s.flushCh <- req:
 	case <-s.stopCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-req.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -75,146 +223,720 @@ func (s *shipper) run() {
 	ticker := time.NewTicker(s.cfg.FlushEvery)
 	defer ticker.Stop()
 
+	// The age timer runs while events are buffered and fires when the oldest
+	// of them has waited Config.MaxEventAge, counting from its first enqueue
+	var ageTimer *time.Timer
+	var ageC <-chan time.Time
+	updateAgeTimer := func() {
+		if s.cfg.MaxEventAge <= 0 {
+			return
+		}
+		s.mu.Lock()
+		oldest, pending := s.events.oldest()
+		s.mu.Unlock()
+		switch {
+		case !pending && ageTimer != nil:
+			ageTimer.Stop()
+			ageTimer, ageC = nil, nil
+		case pending:
+			wait := max(s.cfg.MaxEventAge-time.Since(oldest), 0)
+			if ageTimer == nil {
+				ageTimer = time.NewTimer(wait)
+				ageC = ageTimer.C
+			} else {
+				ageTimer.Reset(wait)
+			}
+		}
+	}
+
 	for {
 		select {
 		case event := <-s.eventsCh:
+			s.encodeLine(&event)
 			s.mu.Lock()
-			s.events = append(s.events, event)
-			shouldFlush := len(s.events) >= s.cfg.BatchSize
+			s.events.push(event)
+			shouldFlush := s.events.len() >= s.cfg.BatchSize ||
+				(s.cfg.MaxBatchBytes > 0 && s.events.bytes >= s.cfg.MaxBatchBytes)
 			s.mu.Unlock()
 
 			if shouldFlush {
-				s.doFlush()
+				if s.doFlush(context.Background()) == nil {
+					_ = s.replaySpill(context.Background())
+				}
 			}
+			updateAgeTimer()
 
 		case <-ticker.C:
-			s.doFlush()
+			if s.doFlush(context.Background()) == nil {
+				_ = s.replaySpill(context.Background())
+			}
+			updateAgeTimer()
+
+		case <-ageC:
+			ageTimer, ageC = nil, nil
+			if s.doFlush(context.Background()) == nil {
+				_ = s.replaySpill(context.Background())
+			}
+			updateAgeTimer()
 
-		case done := <-s.flushCh:
-			s.doFlush()
-			close(done)
+		case req := <-s.flushCh:
+			// Pick up events already queued so Flush covers everything emitted before it
+			s.drainQueued()
+			err := s.doFlush(req.ctx)
+			if err == nil {
+				err = s.replaySpill(req.ctx)
+			}
+			updateAgeTimer()
+			req.done <- err
 
 		case <-s.stopCh:
-			// Drain remaining events from channel
-			for {
-				select {
-				case event := <-s.eventsCh:
-					s.mu.Lock()
-					s.events = append(s.events, event)
-					s.mu.Unlock()
-				default:
-					s.doFlush()
-					return
-				}
+			// Drain remaining events from channel; spilled events stay on disk
+			s.drainQueued()
+			if s.doFlush(s.stopCtx) != nil {
+				s.abandon()
 			}
+			if s.spill != nil {
+				s.spill.close()
+			}
+			return
 		}
 	}
 }
 
-// doFlush sends the current batch to the ingest URL.
-func (s *shipper) doFlush() {
-	s.mu.Lock()
-	if len(s.events) == 0 {
+// drainQueued moves the events waiting in eventsCh into the batch buffer.
+// It takes at most as many events as were queued when it started, so
+// producers that keep emitting during shutdown can't keep it looping and
+// growing the buffer.
+func (s *shipper) drainQueued() {
+	// Only the run loop receives from eventsCh, so these receives never block
+	for n := len(s.eventsCh); n > 0; n-- {
+		event := <-s.eventsCh
+		s.encodeLine(&event)
+		s.mu.Lock()
+		s.events.push(event)
+		s.mu.Unlock()
+	}
+	s.enforceBufferCap()
+}
+
+// replaySpill ships spilled events, oldest file first, while the endpoint is
+// accepting batches and nothing newer is waiting in the buffer. Each file is
+// deleted once its events have been through doFlush: delivered, dropped and
+// reported, or (if ctx ends) requeued in memory. A crash mid-replay leaves the
+// file in place, so spilled events are delivered at least once.
+func (s *shipper) replaySpill(ctx context.Context) error {
+	for s.spill != nil && s.deliveryOK.Load() {
+		s.mu.Lock()
+		pending := s.events.len()
+		s.mu.Unlock()
+		if pending > 0 {
+			return nil
+		}
+
+		path, events, ok := s.spill.takeOldest()
+		if !ok {
+			return nil
+		}
+		// Not trimmed to maxBuffered: the buffer was empty and a spill file is
+		// at most maxSpillFileBytes
+		for i := range events {
+			s.encodeLine(&events[i])
+		}
+		s.mu.Lock()
+		s.events.push(events...)
 		s.mu.Unlock()
+
+		err := s.doFlush(ctx)
+		s.spill.done(path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeLine caches the event's encoding for batch sizing when
+// Config.MaxBatchBytes is set. An event that fails to marshal is left
+// uncached and dropped when its batch is encoded.
+func (s *shipper) encodeLine(event *Event) {
+	if s.cfg.MaxBatchBytes <= 0 {
 		return
 	}
+	if line, err := encodeEvent(s.cfg, *event); err == nil {
+		event.line = line
+	}
+}
 
-	// Take the current batch
-	batch := s.events
-	s.events = make([]Event, 0, s.cfg.BatchSize)
+// abandon drops the events left buffered when the final flush's deadline
+// passes.
+func (s *shipper) abandon() {
+	s.mu.Lock()
+	events := s.events.take(s.events.len(), 0)
 	s.mu.Unlock()
+	if len(events) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "monitor: shutdown deadline exceeded, dropping %d undelivered events\n", len(events))
+	s.emitBatchDropped(DropReasonShutdown, events)
+	s.dropped(DropReasonShutdown, events...)
+}
 
-	// Build NDJSON payload
-	var buf bytes.Buffer
-	for _, event := range batch {
-		jsonBytes, err := json.Marshal(event)
+// maxBuffered returns the most events the batch buffer may hold, including
+// the batch in flight: one queue's worth plus one batch. Together with the
+// queue itself this bounds the shipper at 2*QueueSize + BatchSize events.
+func (s *shipper) maxBuffered() int {
+	return cap(s.eventsCh) + s.cfg.BatchSize
+}
+
+// enforceBufferCap drops the lowest-priority, newest events beyond maxBuffered.
+func (s *shipper) enforceBufferCap() {
+	s.mu.Lock()
+	dropped := s.events.trim(s.maxBuffered())
+	s.mu.Unlock()
+
+	if len(dropped) > 0 {
+		fmt.Fprintf(os.Stderr, "monitor: shipper buffer full, dropping %d events\n", len(dropped))
+		s.emitBatchDropped(DropReasonBufferFull, dropped)
+		s.dropped(DropReasonBufferFull, dropped...)
+	}
+}
+
+// doFlush ships the buffered events in batches of up to BatchSize events and
+// MaxBatchBytes bytes of NDJSON, highest priority first. When ctx is done,
+// delivery and retries stop, the undelivered events stay buffered, and
+// ctx.Err() is returned. Otherwise every batch was either delivered or
+// dropped, and nil is returned.
+func (s *shipper) doFlush(ctx context.Context) error {
+	var flushErr error
+	for shipped := false; ; shipped = true {
+		s.mu.Lock()
+		batch := s.events.take(s.cfg.BatchSize, s.cfg.MaxBatchBytes)
+		s.mu.Unlock()
+		if len(batch) == 0 {
+			if shipped {
+				s.lastFlush.Store(&flushResult{at: time.Now(), err: flushErr})
+			}
+			return nil
+		}
+
+		s.attemptErr = nil
+		before := s.totalShipped.Load()
+		var err error
+		if s.cfg.GroupByTrace {
+			err = s.shipGroups(ctx, groupByTrace(batch))
+		} else {
+			err = s.shipBatch(ctx, batch)
+		}
+		if s.totalShipped.Load()-before < uint64(len(batch)) {
+			flushErr = s.attemptErr
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "monitor: failed to marshal event: %v\n", err)
-			continue
+			s.lastFlush.Store(&flushResult{at: time.Now(), err: flushErr})
+			return err
 		}
-		buf.Write(jsonBytes)
-		buf.WriteByte('\n')
 	}
+}
 
-	if buf.Len() == 0 {
-		return
+// shipBatch delivers one batch through the Transport or over HTTP.
+func (s *shipper) shipBatch(ctx context.Context, batch []Event) error {
+	if s.cfg.Transport != nil {
+		return s.shipTransport(ctx, batch)
 	}
+	return s.shipHTTP(ctx, batch)
+}
 
-	// Prepare payload bytes for potential retries
-	payload := buf.Bytes()
+// shipGroups delivers each group as its own batch. If ctx is done partway,
+// the groups not yet attempted are requeued with the one that failed.
+func (s *shipper) shipGroups(ctx context.Context, groups [][]Event) error {
+	for i, group := range groups {
+		if err := s.shipBatch(ctx, group); err != nil {
+			var rest []Event
+			for _, g := range groups[i+1:] {
+				rest = append(rest, g...)
+			}
+			if len(rest) > 0 {
+				s.mu.Lock()
+				s.events.pushFront(rest)
+				s.mu.Unlock()
+				s.enforceBufferCap()
+			}
+			return err
+		}
+	}
+	return nil
+}
 
-	// Compress once before the retry loop if gzip is enabled
-	var shipPayload []byte
-	if s.cfg.GzipEnabled {
-		var gzipBuf bytes.Buffer
-		gw := gzip.NewWriter(&gzipBuf)
-		if _, err := gw.Write(payload); err != nil {
-			fmt.Fprintf(os.Stderr, "monitor: gzip write failed: %v\n", err)
-			return
+// groupByTrace partitions batch by TraceID for Config.GroupByTrace, in order
+// of each trace's first event and keeping event order within a group. Events
+// without a trace ID form one group of their own.
+func groupByTrace(batch []Event) [][]Event {
+	var groups [][]Event
+	index := make(map[string]int)
+	for _, e := range batch {
+		i, ok := index[e.TraceID]
+		if !ok {
+			i = len(groups)
+			index[e.TraceID] = i
+			groups = append(groups, nil)
 		}
-		if err := gw.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "monitor: gzip close failed: %v\n", err)
-			return
+		groups[i] = append(groups[i], e)
+	}
+	return groups
+}
+
+// delivered counts events accepted by the endpoint. A batch counts once all
+// of its events are accepted.
+func (s *shipper) delivered(events int, batchDone bool) {
+	s.totalShipped.Add(uint64(events))
+	if batchDone {
+		s.totalBatches.Add(1)
+		s.deliveryOK.Store(true)
+	}
+}
+
+// Retry schedule shared by HTTP and Transport delivery.
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = time.Second
+	retryMaxDelay         = 30 * time.Second
+)
+
+// maxRetries returns Config.MaxRetries with its default applied.
+func (s *shipper) maxRetries() int {
+	switch {
+	case s.cfg.MaxRetries == 0:
+		return defaultMaxRetries
+	case s.cfg.MaxRetries < 0:
+		return 0
+	}
+	return s.cfg.MaxRetries
+}
+
+// retryBackoff returns the wait before retry attempt n (1-based): base
+// doubled per attempt, capped at retryMaxDelay, with "equal jitter" (a random
+// point in the upper half) so many instances failing together don't retry in
+// lockstep.
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	d := retryMaxDelay
+	if shift := attempt - 1; shift < 30 {
+		if exp := base << uint(shift); exp > 0 && exp < retryMaxDelay {
+			d = exp
+		}
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// waitRetry sleeps before retry attempt n, returning false without waiting
+// out the backoff if ctx is done or the shipper is stopping.
+func (s *shipper) waitRetry(ctx context.Context, attempt int) bool {
+	backoff := retryBackoff(s.cfg.RetryBaseDelay, attempt)
+	fmt.Fprintf(os.Stderr, "monitor: retrying flush (attempt %d/%d) after %v\n", attempt, s.maxRetries(), backoff)
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		s.totalRetries.Add(1)
+		return true
+	case <-ctx.Done():
+		return false
+	case <-s.stopCh:
+		return false
+	}
+}
+
+// retryAborted handles a batch whose retry wait was cut short. If ctx is
+// done the batch is requeued and ctx's error returned. Otherwise the shipper
+// is stopping: the batch is dropped so shutdown isn't held up by backoff, and
+// the final flush moves on to the remaining batches.
+func (s *shipper) retryAborted(ctx context.Context, batch []Event) error {
+	if ctx.Err() != nil {
+		return s.requeue(ctx, batch)
+	}
+	fmt.Fprintf(os.Stderr, "monitor: shutting down, dropping %d undelivered events\n", len(batch))
+	s.emitBatchDropped(DropReasonShutdown, batch)
+	s.dropped(DropReasonShutdown, batch...)
+	return nil
+}
+
+// requeue puts events that couldn't be delivered before ctx was done back at
+// the front of the buffer and returns ctx's error.
+func (s *shipper) requeue(ctx context.Context, batch []Event) error {
+	fmt.Fprintf(os.Stderr, "monitor: flush deadline exceeded, %d events left buffered\n", len(batch))
+	s.attemptErr = ctx.Err()
+	s.mu.Lock()
+	s.events.pushFront(batch)
+	s.mu.Unlock()
+	s.enforceBufferCap()
+	return ctx.Err()
+}
+
+// shipTransport hands the batch to the configured Transport, retrying with
+// the same backoff schedule as HTTP delivery.
+func (s *shipper) shipTransport(ctx context.Context, batch []Event) error {
+	maxRetries := s.maxRetries()
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if !s.breaker.allow() {
+			return s.shortCircuit(batch)
+		}
+		if attempt > 0 && !s.waitRetry(ctx, attempt) {
+			return s.retryAborted(ctx, batch)
+		}
+
+		err := s.cfg.Transport.Send(ctx, batch)
+		if err == nil {
+			s.breaker.success()
+			s.delivered(len(batch), true)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return s.requeue(ctx, batch)
+		}
+		s.breaker.failure()
+		s.attemptErr = err
+		fmt.Fprintf(os.Stderr, "monitor: transport failed to ship events: %v\n", err)
+	}
+	fmt.Fprintf(os.Stderr, "monitor: dropping batch after %d retries\n", maxRetries)
+	s.deliveryOK.Store(false)
+	s.emitBatchDropped(DropReasonRetriesExhausted, batch)
+	s.dropped(DropReasonRetriesExhausted, batch...)
+	return nil
+}
+
+// defaultIngestContentType is the Content-Type for NDJSON batches.
+const defaultIngestContentType = "application/x-ndjson"
+
+// defaultAuthHeader carries APIKey when Config.AuthHeader is empty.
+const defaultAuthHeader = "X-Api-Key"
+
+// ingestContentType returns the Content-Type for shipped batches: the
+// configured override, else the one for Config.ExportFormat.
+func ingestContentType(cfg *Config) string {
+	switch {
+	case cfg.IngestContentType != "":
+		return cfg.IngestContentType
+	case cfg.ExportFormat == ExportFormatOTLPLogs:
+		return otlpContentType
+	}
+	if ct, ok := cfg.Encoder.(contentTyper); ok {
+		return ct.ContentType()
+	}
+	return defaultIngestContentType
+}
+
+// setIngestHeaders sets the extra, content type, and authentication headers
+// sent with every request to the ingest endpoint.
+func setIngestHeaders(req *http.Request, cfg *Config) {
+	for k, v := range cfg.ExtraHeaders {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", ingestContentType(cfg))
+	if cfg.APIKey != "" {
+		req.Header.Set(authHeader(cfg), authValue(cfg))
+	}
+}
+
+// authHeader returns the header that carries APIKey.
+func authHeader(cfg *Config) string {
+	if cfg.AuthHeader != "" {
+		return cfg.AuthHeader
+	}
+	return defaultAuthHeader
+}
+
+// authValue returns APIKey prefixed with AuthScheme, if any.
+func authValue(cfg *Config) string {
+	if cfg.AuthScheme == "" {
+		return cfg.APIKey
+	}
+	return cfg.AuthScheme + " " + cfg.APIKey
+}
+
+// Reasons passed to Config.OnDrop and reported in monitor.batch_dropped and
+// monitor.event_dropped events.
+const (
+	// DropReasonBufferFull: the queue and batch buffer were full.
+	DropReasonBufferFull = "buffer_full"
+
+	// DropReasonMarshalError: the event could not be encoded as JSON.
+	DropReasonMarshalError = "marshal_error"
+
+	// DropReasonPermanentHTTPError: the ingest endpoint rejected the batch
+	// with a non-retryable 4xx status.
+	DropReasonPermanentHTTPError = "permanent_http_error"
+
+	// DropReasonRetriesExhausted: delivery still failed after MaxRetries retries.
+	DropReasonRetriesExhausted = "retries_exhausted"
+
+	// DropReasonShutdown: Shutdown cut a retry backoff short, or
+	// ShutdownContext's deadline passed before the event was delivered.
+	DropReasonShutdown = "shutdown"
+
+	// DropReasonSpillFull: the spill file holding the event was evicted to
+	// keep SpillDir under MaxSpillBytes.
+	DropReasonSpillFull = "spill_full"
+
+	// DropReasonCircuitOpen: the circuit breaker was open and SpillDir is
+	// not set. See Config.CircuitThreshold.
+	DropReasonCircuitOpen = "circuit_open"
+
+	// DropReasonRateLimited: the event exceeded Config.MaxEventsPerSecond.
+	DropReasonRateLimited = "rate_limited"
+
+	// DropReasonSchemaViolation: the event lacked a data key required by its
+	// RegisterSchema schema and Config.StrictSchema is set.
+	DropReasonSchemaViolation = "schema_violation"
+
+	// DropReasonStdoutFull: the line was not written to stdout because the
+	// queue of Config.StdoutQueueSize lines was full. The event is still
+	// shipped.
+	DropReasonStdoutFull = "stdout_full"
+
+	// DropReasonTraceNotSampled: the event's context was marked not sampled,
+	// usually by an upstream service via HeaderSampled. See WithSampled. It
+	// is passed to OnDrop but not counted in Stats.
+	DropReasonTraceNotSampled = "trace_not_sampled"
+
+	// DropReasonRequestError: the batch could not be compressed or its HTTP
+	// request could not be built, so it was never sent.
+	DropReasonRequestError = "request_error"
+)
+
+// dropped counts discarded events and passes them to Config.OnDrop. Callers
+// must not hold s.mu.
+func (s *shipper) dropped(reason string, events ...Event) {
+	s.totalDropped.Add(uint64(len(events)))
+	if s.cfg.OnDrop == nil {
+		return
+	}
+	for _, event := range events {
+		s.cfg.OnDrop(event, reason)
+	}
+}
+
+// emitBatchDropped reports a dropped batch as an internal event. Internal
+// events in the batch aren't counted, and a batch of only internal events
+// isn't reported.
+func (s *shipper) emitBatchDropped(reason string, events []Event) {
+	count := 0
+	for _, event := range events {
+		if !event.internal {
+			count++
 		}
-		shipPayload = gzipBuf.Bytes()
-	} else {
-		shipPayload = payload
 	}
+	if count == 0 {
+		return
+	}
+	s.monitor.emitSelf("monitor.batch_dropped", map[string]any{
+		"reason": reason,
+		"events": count,
+	}, LevelError)
+}
 
-	const maxRetries = 3
+// shipHTTP encodes the batch as NDJSON and POSTs it to the ingest URL.
+// When the endpoint reports a partial failure, only the failed events are
+// re-encoded and retried.
+func (s *shipper) shipHTTP(ctx context.Context, batch []Event) error {
+	payload, batch := s.encodeBatch(batch)
+	if payload == nil {
+		return nil
+	}
 
+	maxRetries := s.maxRetries()
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 1s, 2s, 4s
-			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
-			fmt.Fprintf(os.Stderr, "monitor: retrying flush (attempt %d/%d) after %v\n", attempt, maxRetries, backoff)
-			time.Sleep(backoff)
+		if !s.breaker.allow() {
+			return s.shortCircuit(batch)
+		}
+		if attempt > 0 && !s.waitRetry(ctx, attempt) {
+			return s.retryAborted(ctx, batch)
 		}
 
-		req, err := http.NewRequest(http.MethodPost, s.cfg.IngestURL, bytes.NewReader(shipPayload))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.IngestURL, bytes.NewReader(payload))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "monitor: failed to create request: %v\n", err)
-			return
+			s.attemptErr = err
+			s.emitBatchDropped(DropReasonRequestError, batch)
+			s.dropped(DropReasonRequestError, batch...)
+			return nil
 		}
 
-		req.Header.Set("Content-Type", "application/x-ndjson")
-		if s.cfg.GzipEnabled {
-			req.Header.Set("Content-Encoding", "gzip")
-		}
-		if s.cfg.APIKey != "" {
-			req.Header.Set("X-Api-Key", s.cfg.APIKey)
+		setIngestHeaders(req, s.cfg)
+		if encoding := contentEncoding(s.cfg); encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
 		}
 
 		resp, err := s.client.Do(req)
 		if err != nil {
+			if ctx.Err() != nil {
+				return s.requeue(ctx, batch)
+			}
 			// Network error — retry
 			fmt.Fprintf(os.Stderr, "monitor: failed to ship events: %v\n", err)
-			if attempt == maxRetries {
-				fmt.Fprintf(os.Stderr, "monitor: dropping batch after %d retries\n", maxRetries)
-				return
-			}
+			s.breaker.failure()
+			s.attemptErr = err
 			continue
 		}
 
-		// Drain response body to allow connection reuse
+		// Read a bounded body for partial-failure parsing, then drain the rest
+		// to allow connection reuse
+		var body []byte
+		if resp.StatusCode < 300 {
+			body, _ = io.ReadAll(io.LimitReader(resp.Body, maxPartialFailureBody))
+		}
 		_, _ = io.Copy(io.Discard, resp.Body)
 		resp.Body.Close()
 
+		if resp.StatusCode < 300 {
+			s.breaker.success()
+			failed, ok := s.partialFailures(resp.StatusCode, body, batch)
+			if !ok {
+				if resp.StatusCode != http.StatusMultiStatus {
+					s.delivered(len(batch), true)
+					return nil // Success
+				}
+				// Partial success without details — retry the whole batch
+				fmt.Fprintf(os.Stderr, "monitor: ingest returned status %d without failure details\n", resp.StatusCode)
+				s.attemptErr = fmt.Errorf("monitor: ingest returned status %d without failure details", resp.StatusCode)
+			} else {
+				if len(failed) == 0 {
+					s.delivered(len(batch), true)
+					return nil // Success
+				}
+				fmt.Fprintf(os.Stderr, "monitor: ingest rejected %d of %d events\n", len(failed), len(batch))
+				s.delivered(len(batch)-len(failed), false)
+				s.attemptErr = fmt.Errorf("monitor: ingest rejected %d of %d events", len(failed), len(batch))
+				batch = failed
+				if payload, batch = s.encodeBatch(batch); payload == nil {
+					return nil
+				}
+			}
+			continue
+		}
+
 		if resp.StatusCode < 400 {
-			return // Success
+			s.breaker.success()
+			s.delivered(len(batch), true)
+			return nil // Success
 		}
 
-		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-			// Client error — don't retry
+		s.attemptErr = fmt.Errorf("monitor: ingest returned status %d", resp.StatusCode)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			// Client error — don't retry. The endpoint is up, so the circuit stays closed
+			s.breaker.success()
 			fmt.Fprintf(os.Stderr, "monitor: ingest returned status %d, not retrying\n", resp.StatusCode)
-			return
+			s.emitBatchDropped(DropReasonPermanentHTTPError, batch)
+			s.dropped(DropReasonPermanentHTTPError, batch...)
+			return nil
 		}
 
-		// 5xx — retry
+		// 429 or 5xx — retry
 		fmt.Fprintf(os.Stderr, "monitor: ingest returned status %d\n", resp.StatusCode)
-		if attempt == maxRetries {
-			fmt.Fprintf(os.Stderr, "monitor: dropping batch after %d retries\n", maxRetries)
-			return
+		s.breaker.failure()
+	}
+
+	fmt.Fprintf(os.Stderr, "monitor: dropping batch after %d retries\n", maxRetries)
+	s.deliveryOK.Store(false)
+	s.emitBatchDropped(DropReasonRetriesExhausted, batch)
+	s.dropped(DropReasonRetriesExhausted, batch...)
+	return nil
+}
+
+// encodeBatch builds the payload for batch in Config.ExportFormat, by default
+// one Config.Encoder line per event, and compresses it if configured. It also
+// returns the events it encoded, in order, so indices reported by the ingest
+// endpoint still map to events when some fail to marshal. A nil payload means
+// there is nothing to send.
+func (s *shipper) encodeBatch(batch []Event) ([]byte, []Event) {
+	if s.cfg.ExportFormat == ExportFormatOTLPLogs {
+		return s.encodeOTLPBatch(batch)
+	}
+	var buf bytes.Buffer
+	encoded := make([]Event, 0, len(batch))
+	for _, event := range batch {
+		line := event.line
+		var err error
+		if line == nil {
+			line, err = encodeEvent(s.cfg, event)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monitor: failed to marshal event: %v\n", err)
+			s.attemptErr = fmt.Errorf("monitor: failed to marshal event: %w", err)
+			s.dropped(DropReasonMarshalError, event)
+			continue
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		encoded = append(encoded, event)
+	}
+
+	if buf.Len() == 0 {
+		return nil, nil
+	}
+
+	// Compress once before the retry loop
+	payload, err := compressPayload(s.cfg, buf.Bytes())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: %v\n", err)
+		s.attemptErr = err
+		s.emitBatchDropped(DropReasonRequestError, encoded)
+		s.dropped(DropReasonRequestError, encoded...)
+		return nil, nil
+	}
+	return payload, encoded
+}
+
+// encodeOTLPBatch is encodeBatch for ExportFormatOTLPLogs.
+func (s *shipper) encodeOTLPBatch(batch []Event) ([]byte, []Event) {
+	raw, encoded, failed, err := encodeOTLPLogs(batch)
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "monitor: failed to marshal %d events\n", len(failed))
+		s.attemptErr = fmt.Errorf("monitor: failed to marshal %d events", len(failed))
+		s.dropped(DropReasonMarshalError, failed...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: failed to marshal batch: %v\n", err)
+		s.attemptErr = fmt.Errorf("monitor: failed to marshal batch: %w", err)
+		s.dropped(DropReasonMarshalError, encoded...)
+		return nil, nil
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	payload, err := compressPayload(s.cfg, raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: %v\n", err)
+		s.attemptErr = err
+		s.emitBatchDropped(DropReasonRequestError, encoded)
+		s.dropped(DropReasonRequestError, encoded...)
+		return nil, nil
+	}
+	return payload, encoded
+}
+
+// partialFailures runs the configured PartialFailureParser and maps the
+// reported indices back to events. Out-of-range and duplicate indices are ignored.
+func (s *shipper) partialFailures(statusCode int, body []byte, batch []Event) ([]Event, bool) {
+	parse := s.cfg.PartialFailureParser
+	if parse == nil {
+		parse = DefaultPartialFailureParser
+	}
+	indices, ok := parse(statusCode, body)
+	if !ok {
+		return nil, false
+	}
+
+	seen := make(map[int]bool, len(indices))
+	failed := make([]Event, 0, len(indices))
+	for _, i := range indices {
+		if i < 0 || i >= len(batch) || seen[i] {
+			continue
 		}
+		seen[i] = true
+		failed = append(failed, batch[i])
 	}
+	return failed, true
 }
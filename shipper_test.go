@@ -1,8 +1,17 @@
 package monitor
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -29,17 +38,17 @@ func TestShipperRetry(t *testing.T) {
 			DisableStdout: true,
 		}
 
-		s := newShipper(cfg)
+		s := newShipper(defaultMonitor, cfg)
 
 		// Add an event and flush
-		s.events = append(s.events, Event{
+		s.events.push(Event{
 			Name:      "test.retry",
 			Service:   "test",
 			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
 			Level:     "info",
 		})
 
-		s.doFlush()
+		s.doFlush(context.Background())
 
 		got := int(attempts.Load())
 		if got != 3 {
@@ -63,15 +72,15 @@ func TestShipperRetry(t *testing.T) {
 			DisableStdout: true,
 		}
 
-		s := newShipper(cfg)
-		s.events = append(s.events, Event{
+		s := newShipper(defaultMonitor, cfg)
+		s.events.push(Event{
 			Name:      "test.no-retry",
 			Service:   "test",
 			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
 			Level:     "info",
 		})
 
-		s.doFlush()
+		s.doFlush(context.Background())
 
 		got := int(attempts.Load())
 		if got != 1 {
@@ -95,15 +104,15 @@ func TestShipperRetry(t *testing.T) {
 			DisableStdout: true,
 		}
 
-		s := newShipper(cfg)
-		s.events = append(s.events, Event{
+		s := newShipper(defaultMonitor, cfg)
+		s.events.push(Event{
 			Name:      "test.success",
 			Service:   "test",
 			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
 			Level:     "info",
 		})
 
-		s.doFlush()
+		s.doFlush(context.Background())
 
 		got := int(attempts.Load())
 		if got != 1 {
@@ -111,3 +120,691 @@ func TestShipperRetry(t *testing.T) {
 		}
 	})
 }
+
+func TestShipperContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        string
+	}{
+		{"default", "", "application/x-ndjson"},
+		{"override", "application/jsonlines", "application/jsonlines"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Content-Type")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			s := newShipper(defaultMonitor, &Config{
+				Service:           "test-content-type",
+				IngestURL:         server.URL,
+				IngestContentType: tt.contentType,
+				BatchSize:         10,
+				FlushEvery:        time.Second,
+			})
+			s.events.push(Event{Name: "test.content-type", Level: "info"})
+			s.doFlush(context.Background())
+
+			if got != tt.want {
+				t.Errorf("Content-Type = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShipperAuthHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		scheme     string
+		wantHeader string
+		wantValue  string
+	}{
+		{"default", "", "", "X-Api-Key", "secret"},
+		{"bearer", "Authorization", "Bearer", "Authorization", "Bearer secret"},
+		{"no scheme", "X-Api-Token", "", "X-Api-Token", "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			s := newShipper(defaultMonitor, &Config{
+				Service:           "test-auth-headers",
+				IngestURL:         server.URL,
+				APIKey:            "secret",
+				AuthHeader:        tt.header,
+				AuthScheme:        tt.scheme,
+				ExtraHeaders:      map[string]string{"X-Tenant": "acme", "Content-Type": "text/plain"},
+				IngestContentType: "application/jsonlines",
+				BatchSize:         10,
+				FlushEvery:        time.Second,
+			})
+			s.events.push(Event{Name: "test.auth-headers", Level: "info"})
+			s.doFlush(context.Background())
+
+			if got.Get(tt.wantHeader) != tt.wantValue {
+				t.Errorf("%s = %q, want %q", tt.wantHeader, got.Get(tt.wantHeader), tt.wantValue)
+			}
+			if tt.header != "" && got.Get(defaultAuthHeader) != "" {
+				t.Errorf("%s = %q, want it unset with AuthHeader %q", defaultAuthHeader, got.Get(defaultAuthHeader), tt.header)
+			}
+			if got.Get("X-Tenant") != "acme" {
+				t.Errorf("X-Tenant = %q, want acme from ExtraHeaders", got.Get("X-Tenant"))
+			}
+			if got.Get("Content-Type") != "application/jsonlines" {
+				t.Errorf("Content-Type = %q, want IngestContentType to win over ExtraHeaders", got.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+// recordingRoundTripper answers every request with 200 OK, recording it.
+type recordingRoundTripper struct {
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (rt *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.requests = append(rt.requests, req)
+	rt.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestShipperHTTPClient(t *testing.T) {
+	rt := &recordingRoundTripper{}
+	s := newShipper(defaultMonitor, &Config{
+		Service:    "test-http-client",
+		IngestURL:  "https://ingest.invalid/v1/events",
+		APIKey:     "secret",
+		HTTPClient: &http.Client{Transport: rt},
+		BatchSize:  10,
+		FlushEvery: time.Second,
+	})
+	s.events.push(Event{Name: "test.http-client", Level: "info"})
+	if err := s.doFlush(context.Background()); err != nil {
+		t.Fatalf("doFlush() error = %v", err)
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if len(rt.requests) != 1 {
+		t.Fatalf("custom RoundTripper got %d requests, want 1", len(rt.requests))
+	}
+	req := rt.requests[0]
+	if req.URL.Host != "ingest.invalid" || req.Header.Get("X-Api-Key") != "secret" {
+		t.Errorf("request = %s with X-Api-Key %q, want the ingest URL and API key", req.URL, req.Header.Get("X-Api-Key"))
+	}
+}
+
+func TestGzipPayloadReusesWriter(t *testing.T) {
+	inputs := []string{
+		`{"name":"first"}` + "\n",
+		`{"name":"second"}` + "\n" + `{"name":"third"}` + "\n",
+	}
+
+	// Compress several payloads in sequence so pooled writers get reused
+	for _, input := range inputs {
+		compressed, err := gzipPayload([]byte(input))
+		if err != nil {
+			t.Fatalf("gzipPayload() error = %v", err)
+		}
+
+		gr, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		got, err := io.ReadAll(gr)
+		if err != nil {
+			t.Fatalf("io.ReadAll() error = %v", err)
+		}
+		if string(got) != input {
+			t.Errorf("decompressed = %q, want %q", got, input)
+		}
+	}
+}
+
+func BenchmarkShipperFlushGzip(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		Service:       "bench-gzip",
+		IngestURL:     server.URL,
+		BatchSize:     10,
+		FlushEvery:    time.Second,
+		GzipEnabled:   true,
+		DisableStdout: true,
+	}
+	s := newShipper(defaultMonitor, cfg)
+
+	event := Event{
+		Name:      "bench.flush",
+		Service:   "bench",
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Level:     "info",
+		Data:      map[string]any{"key": "value"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.events.push(event, event, event)
+		s.doFlush(context.Background())
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for _, base := range []time.Duration{0, 50 * time.Millisecond, time.Second} {
+		want := base
+		if want == 0 {
+			want = defaultRetryBaseDelay
+		}
+		for attempt := 1; attempt <= 40; attempt++ {
+			full := retryMaxDelay
+			if attempt <= 30 {
+				full = min(want<<uint(attempt-1), retryMaxDelay)
+			}
+			for i := 0; i < 20; i++ {
+				d := retryBackoff(base, attempt)
+				if d < full/2 || d > full {
+					t.Fatalf("retryBackoff(%v, %d) = %v, want within [%v, %v]", base, attempt, d, full/2, full)
+				}
+			}
+		}
+	}
+}
+
+func TestShipperRetryConfig(t *testing.T) {
+	// failingServer returns status for the first n requests, then 200
+	failingServer := func(n int32, status int) (*httptest.Server, *atomic.Int32) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) <= n {
+				w.WriteHeader(status)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		return server, &attempts
+	}
+
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		maxRetries   int
+		wantAttempts int32
+	}{
+		{"429 is retried", 2, http.StatusTooManyRequests, 0, 3},
+		{"MaxRetries bounds 5xx retries", 10, http.StatusBadGateway, 2, 3},
+		{"negative MaxRetries disables retries", 10, http.StatusServiceUnavailable, -1, 1},
+		{"other 4xx is permanent", 10, http.StatusForbidden, 5, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, attempts := failingServer(tt.failures, tt.status)
+			defer server.Close()
+
+			s := newShipper(defaultMonitor, &Config{
+				Service:        "test-retry-config",
+				IngestURL:      server.URL,
+				BatchSize:      10,
+				FlushEvery:     time.Second,
+				MaxRetries:     tt.maxRetries,
+				RetryBaseDelay: time.Millisecond,
+			})
+			s.events.push(Event{Name: "test.retry", Level: "info"})
+			s.doFlush(context.Background())
+
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestShutdownSkipsRetryBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if err := Init(Config{
+		Service:        "test-shutdown-retry",
+		DisableStdout:  true,
+		IngestURL:      server.URL,
+		RetryBaseDelay: time.Minute,
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	Emit(context.Background(), "test.stuck", nil)
+	go Flush() // enters a minute-long backoff
+
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	Shutdown()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Shutdown() took %v, want it to skip the retry backoff", elapsed)
+	}
+}
+
+func TestFlushContextDeadline(t *testing.T) {
+	var healthy atomic.Bool
+	var posts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if err := Init(Config{Service: "test-flush-deadline", DisableStdout: true, IngestURL: server.URL}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	Emit(context.Background(), "test.deadline", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := FlushContext(ctx)
+	elapsed := time.Since(start)
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("FlushContext() error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("FlushContext() took %v, want bounded by the 200ms deadline", elapsed)
+	}
+
+	// The undelivered event is kept and goes out once the endpoint recovers
+	healthy.Store(true)
+	before := posts.Load()
+	if err := FlushContext(context.Background()); err != nil {
+		t.Fatalf("FlushContext() after recovery error = %v", err)
+	}
+	if posts.Load() == before {
+		t.Error("requeued event was not shipped after the endpoint recovered")
+	}
+}
+
+func TestOnDrop(t *testing.T) {
+	type drop struct {
+		name   string
+		reason string
+	}
+
+	// newDropShipper returns a shipper whose OnDrop records drops and checks
+	// it is never called with the shipper mutex held
+	newDropShipper := func(t *testing.T, cfg Config) (*shipper, *[]drop) {
+		var drops []drop
+		var s *shipper
+		cfg.Service = "test-on-drop"
+		cfg.FlushEvery = time.Second
+		cfg.OnDrop = func(e Event, reason string) {
+			if !s.mu.TryLock() {
+				t.Error("OnDrop called with the shipper mutex held")
+			} else {
+				s.mu.Unlock()
+			}
+			drops = append(drops, drop{e.Name, reason})
+		}
+		s = newShipper(defaultMonitor, &cfg)
+		return s, &drops
+	}
+
+	t.Run("buffer full", func(t *testing.T) {
+		s, drops := newDropShipper(t, Config{IngestURL: "http://127.0.0.1:0", BatchSize: 1, QueueSize: 1})
+		s.send(Event{Name: "test.queued"})
+		s.send(Event{Name: "test.overflow"})
+
+		if len(*drops) != 1 || (*drops)[0] != (drop{"test.overflow", DropReasonBufferFull}) {
+			t.Errorf("drops = %v, want [{test.overflow %s}]", *drops, DropReasonBufferFull)
+		}
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		s, drops := newDropShipper(t, Config{IngestURL: server.URL, BatchSize: 10})
+		s.events.push(
+			Event{Name: "test.ok", Level: "info"},
+			Event{Name: "test.unencodable", Level: "info", Data: map[string]any{"ch": make(chan int)}},
+		)
+		s.doFlush(context.Background())
+
+		if len(*drops) != 1 || (*drops)[0] != (drop{"test.unencodable", DropReasonMarshalError}) {
+			t.Errorf("drops = %v, want [{test.unencodable %s}]", *drops, DropReasonMarshalError)
+		}
+	})
+
+	t.Run("permanent HTTP error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		s, drops := newDropShipper(t, Config{IngestURL: server.URL, BatchSize: 10})
+		s.events.push(Event{Name: "test.a", Level: "info"}, Event{Name: "test.b", Level: "info"})
+		s.doFlush(context.Background())
+
+		want := []drop{{"test.a", DropReasonPermanentHTTPError}, {"test.b", DropReasonPermanentHTTPError}}
+		if len(*drops) != len(want) || (*drops)[0] != want[0] || (*drops)[1] != want[1] {
+			t.Errorf("drops = %v, want %v", *drops, want)
+		}
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		s, drops := newDropShipper(t, Config{IngestURL: server.URL, BatchSize: 10, MaxRetries: -1})
+		s.events.push(Event{Name: "test.lost", Level: "info"})
+		s.doFlush(context.Background())
+
+		if len(*drops) != 1 || (*drops)[0] != (drop{"test.lost", DropReasonRetriesExhausted}) {
+			t.Errorf("drops = %v, want [{test.lost %s}]", *drops, DropReasonRetriesExhausted)
+		}
+	})
+
+	t.Run("request error", func(t *testing.T) {
+		for name, cfg := range map[string]Config{
+			"compression": {IngestURL: "http://127.0.0.1:0", BatchSize: 10, Compression: "brotli"},
+			"bad URL":     {IngestURL: "http://bad host", BatchSize: 10},
+		} {
+			s, drops := newDropShipper(t, cfg)
+			s.events.push(Event{Name: "test.unsent", Level: "info"})
+			s.doFlush(context.Background())
+
+			if len(*drops) != 1 || (*drops)[0] != (drop{"test.unsent", DropReasonRequestError}) {
+				t.Errorf("%s: drops = %v, want [{test.unsent %s}]", name, *drops, DropReasonRequestError)
+			}
+		}
+	})
+}
+
+func TestMaxBatchBytes(t *testing.T) {
+	const maxBytes = 4096
+	type post struct {
+		size   int
+		events int
+	}
+	var mu sync.Mutex
+	var posts []post
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		posts = append(posts, post{size: len(body), events: bytes.Count(body, []byte("\n"))})
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	received := func() []post {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(posts)
+	}
+	padding := strings.Repeat("x", 1000)
+
+	t.Run("splits batches", func(t *testing.T) {
+		mu.Lock()
+		posts = nil
+		mu.Unlock()
+		s := newShipper(defaultMonitor, &Config{
+			Service:       "test-max-bytes",
+			IngestURL:     server.URL,
+			BatchSize:     100,
+			MaxBatchBytes: maxBytes,
+			FlushEvery:    time.Hour,
+		})
+		for range 10 {
+			event := Event{Name: "test.large", Level: "info", Data: map[string]any{"padding": padding}}
+			s.encodeLine(&event)
+			s.events.push(event)
+		}
+		s.doFlush(context.Background())
+
+		got := received()
+		if len(got) < 3 {
+			t.Fatalf("posts = %d, want the 10 events split into at least 3", len(got))
+		}
+		total := 0
+		for i, p := range got {
+			if p.size > maxBytes {
+				t.Errorf("post %d is %d bytes, want <= %d", i, p.size, maxBytes)
+			}
+			total += p.events
+		}
+		if total != 10 {
+			t.Errorf("shipped %d events, want 10", total)
+		}
+		if s.events.bytes != 0 {
+			t.Errorf("buffered bytes = %d after flush, want 0", s.events.bytes)
+		}
+	})
+
+	t.Run("flushes early", func(t *testing.T) {
+		mu.Lock()
+		posts = nil
+		mu.Unlock()
+		if err := Init(Config{
+			Service:       "test-max-bytes",
+			IngestURL:     server.URL,
+			BatchSize:     100,
+			MaxBatchBytes: maxBytes,
+			FlushEvery:    time.Hour,
+			DisableStdout: true,
+		}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		for range 5 {
+			Emit(context.Background(), "test.large", map[string]any{"padding": padding})
+		}
+		deadline := time.Now().Add(2 * time.Second)
+		for len(received()) == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		got := received()
+		if len(got) == 0 {
+			t.Fatal("no POST before FlushEvery, want an early flush once MaxBatchBytes was buffered")
+		}
+		if got[0].size > maxBytes {
+			t.Errorf("post is %d bytes, want <= %d", got[0].size, maxBytes)
+		}
+	})
+}
+
+func TestShutdownContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var dropped atomic.Int32
+	if err := Init(Config{
+		Service:       "test-shutdown-deadline",
+		DisableStdout: true,
+		IngestURL:     server.URL,
+		FlushEvery:    time.Hour,
+		OnDrop: func(event Event, reason string) {
+			if reason == DropReasonShutdown {
+				dropped.Add(1)
+			}
+		},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		Emit(context.Background(), "test.unresponsive", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := ShutdownContext(ctx)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ShutdownContext() took %v, want it to give up at the 200ms deadline", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ShutdownContext() error = %v, want context.DeadlineExceeded", err)
+	}
+	if !IsShutdown() {
+		t.Error("IsShutdown() = false after ShutdownContext")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for dropped.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := dropped.Load(); got != 3 {
+		t.Errorf("OnDrop(shutdown) called %d times, want 3", got)
+	}
+}
+
+func TestGroupByTrace(t *testing.T) {
+	var mu sync.Mutex
+	var posts [][]Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var events []Event
+		for _, line := range bytes.Split(bytes.TrimSpace(body), []byte("\n")) {
+			var e Event
+			if err := json.Unmarshal(line, &e); err != nil {
+				t.Errorf("bad NDJSON line %q: %v", line, err)
+			}
+			events = append(events, e)
+		}
+		mu.Lock()
+		posts = append(posts, events)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s := newShipper(defaultMonitor, &Config{
+		Service:      "test-group-by-trace",
+		IngestURL:    server.URL,
+		BatchSize:    100,
+		FlushEvery:   time.Hour,
+		GroupByTrace: true,
+	})
+	traces := []string{"trace-a", "trace-b", "trace-c", "trace-b", "trace-a", "trace-c", "trace-a"}
+	for i, traceID := range traces {
+		s.events.push(Event{Name: "test.grouped", Level: "info", TraceID: traceID, Data: map[string]any{"i": float64(i)}})
+	}
+	if err := s.doFlush(context.Background()); err != nil {
+		t.Fatalf("doFlush() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(posts) != 3 {
+		t.Fatalf("posts = %d, want one per trace (3)", len(posts))
+	}
+	want := map[string][]float64{"trace-a": {0, 4, 6}, "trace-b": {1, 3}, "trace-c": {2, 5}}
+	for _, post := range posts {
+		traceID := post[0].TraceID
+		var order []float64
+		for _, e := range post {
+			if e.TraceID != traceID {
+				t.Errorf("post for %s contains an event from %s", traceID, e.TraceID)
+			}
+			order = append(order, e.Data.(map[string]any)["i"].(float64))
+		}
+		if !slices.Equal(order, want[traceID]) {
+			t.Errorf("post for %s has events %v, want %v", traceID, order, want[traceID])
+		}
+	}
+	if got := s.totalShipped.Load(); got != uint64(len(traces)) {
+		t.Errorf("totalShipped = %d, want %d", got, len(traces))
+	}
+}
+
+func TestGroupByTraceUntraced(t *testing.T) {
+	groups := groupByTrace([]Event{
+		{Name: "a", TraceID: "t1"},
+		{Name: "b"},
+		{Name: "c", TraceID: "t1"},
+		{Name: "d"},
+	})
+	if len(groups) != 2 {
+		t.Fatalf("groups = %d, want 2", len(groups))
+	}
+	if len(groups[1]) != 2 || groups[1][0].Name != "b" || groups[1][1].Name != "d" {
+		t.Errorf("untraced group = %+v, want events b and d together", groups[1])
+	}
+}
+
+func TestMaxEventAge(t *testing.T) {
+	rt := &recordingTransport{}
+	if err := Init(Config{
+		Service:       "test-max-event-age",
+		DisableStdout: true,
+		Transport:     rt,
+		FlushEvery:    time.Hour,
+		MaxEventAge:   50 * time.Millisecond,
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	waitShipped := func(want int) time.Duration {
+		t.Helper()
+		start := time.Now()
+		for rt.eventCount() < want {
+			if time.Since(start) > 5*time.Second {
+				t.Fatalf("shipped %d events, want %d", rt.eventCount(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return time.Since(start)
+	}
+
+	Emit(context.Background(), "test.age", nil)
+	if elapsed := waitShipped(1); elapsed > time.Second {
+		t.Errorf("event shipped after %v, want within about MaxEventAge", elapsed)
+	}
+
+	// The timer re-arms for events buffered after a flush
+	Emit(context.Background(), "test.age", nil)
+	Emit(context.Background(), "test.age", nil)
+	if elapsed := waitShipped(3); elapsed > time.Second {
+		t.Errorf("later events shipped after %v, want within about MaxEventAge", elapsed)
+	}
+}
@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// shutdownSignals are the signals Config.HandleSignals shuts down on.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// signalHandler shuts its monitor down on SIGINT or SIGTERM, then re-raises
+// the signal so the process exits as it would have without the handler.
+type signalHandler struct {
+	monitor *Monitor
+	ch      chan os.Signal
+	stopCh  chan struct{}
+
+	// raise re-delivers the signal once the monitor is shut down.
+	raise func(os.Signal)
+}
+
+func newSignalHandler(m *Monitor, raise func(os.Signal)) *signalHandler {
+	h := &signalHandler{
+		monitor: m,
+		ch:      make(chan os.Signal, 1),
+		stopCh:  make(chan struct{}),
+		raise:   raise,
+	}
+	signal.Notify(h.ch, shutdownSignals...)
+	go h.run()
+	return h
+}
+
+func (h *signalHandler) run() {
+	select {
+	case sig := <-h.ch:
+		// Restore the default disposition first, so a second signal during a
+		// slow shutdown terminates the process immediately
+		signal.Stop(h.ch)
+		h.monitor.Shutdown()
+		h.raise(sig)
+	case <-h.stopCh:
+	}
+}
+
+// stop uninstalls the handler. It doesn't wait for run to return, because run
+// calls Shutdown, which stops the handler.
+func (h *signalHandler) stop() {
+	signal.Stop(h.ch)
+	close(h.stopCh)
+}
+
+// raiseSignal sends sig to the current process, exiting with status 1 where
+// that isn't supported (e.g., os.Interrupt on Windows).
+func raiseSignal(sig os.Signal) {
+	p, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = p.Signal(sig)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
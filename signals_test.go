@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalHandlerShutsDown(t *testing.T) {
+	rt := &recordingTransport{}
+	m, err := New(Config{
+		Service:       "test-signals",
+		DisableStdout: true,
+		Transport:     rt,
+		FlushEvery:    time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	raised := make(chan os.Signal, 1)
+	h := newSignalHandler(m, func(sig os.Signal) { raised <- sig })
+	m.signals.Store(h)
+
+	for i := 0; i < 5; i++ {
+		m.Emit(context.Background(), "test.signal", nil)
+	}
+	h.ch <- syscall.SIGTERM
+
+	select {
+	case sig := <-raised:
+		if sig != syscall.SIGTERM {
+			t.Errorf("re-raised %v, want SIGTERM", sig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal was not re-raised")
+	}
+	if !m.IsShutdown() {
+		t.Error("monitor not shut down after signal")
+	}
+	if n := rt.eventCount(); n != 5 {
+		t.Errorf("shipped %d events before re-raising, want 5", n)
+	}
+	if m.signals.Load() != nil {
+		t.Error("signal handler still registered after Shutdown")
+	}
+}
+
+func TestHandleSignalsLifecycle(t *testing.T) {
+	m, err := New(Config{Service: "test-signals", DisableStdout: true, HandleSignals: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	first := m.signals.Load()
+	if first == nil {
+		t.Fatal("HandleSignals did not install a handler")
+	}
+
+	// Re-Init replaces the handler rather than stacking a second one
+	if err := m.init(Config{Service: "test-signals", DisableStdout: true, HandleSignals: true}); err != nil {
+		t.Fatalf("init() error = %v", err)
+	}
+	if h := m.signals.Load(); h == nil || h == first {
+		t.Fatal("re-Init did not replace the signal handler")
+	}
+	select {
+	case <-first.stopCh:
+	default:
+		t.Error("previous signal handler not stopped on re-Init")
+	}
+
+	m.Shutdown()
+	if m.signals.Load() != nil {
+		t.Error("signal handler still registered after Shutdown")
+	}
+	// A second Shutdown is a no-op
+	m.Shutdown()
+}
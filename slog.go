@@ -0,0 +1,178 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// SlogHandlerOptions configures NewSlogHandler.
+type SlogHandlerOptions struct {
+	// Monitor receives the events. Default: the package-level monitor.
+	Monitor *Monitor
+
+	// Name, when set, is the event name for every record, with the message
+	// stored as data.msg. Otherwise the message is the event name.
+	Name string
+
+	// Level is the minimum level handled; records below it are discarded
+	// before any work is done. Default: slog.LevelInfo.
+	Level slog.Leveler
+}
+
+// slogHandler adapts slog records to events. See NewSlogHandler.
+type slogHandler struct {
+	m     *Monitor
+	name  string
+	level slog.Leveler
+
+	// attrs were added with WithAttrs, each under the groups open at the
+	// time; groups are those opened with WithGroup since.
+	attrs  []groupedAttr
+	groups []string
+}
+
+type groupedAttr struct {
+	groups []string
+	attr   slog.Attr
+}
+
+// NewSlogHandler returns a slog.Handler that emits each record as an event,
+// so code logging through log/slog gets the service, job, request, and trace
+// IDs every event carries. The IDs come from the context passed to the
+// logger (e.g., slog.InfoContext(r.Context(), ...) in a handler wrapped by
+// Middleware).
+//
+// The record's message becomes the event name, or data.msg when opts.Name is
+// set. Its attributes, including those added with WithAttrs, become data
+// fields, with WithGroup nesting them under a map. Levels map to LevelDebug,
+// LevelInfo, LevelWarn, and LevelError. Source locations are not captured.
+// opts may be nil.
+//
+//	logger := slog.New(monitor.NewSlogHandler(nil))
+//	logger.InfoContext(ctx, "user.created", "user_id", 42)
+func NewSlogHandler(opts *SlogHandlerOptions) slog.Handler {
+	h := &slogHandler{m: defaultMonitor, level: slog.LevelInfo}
+	if opts != nil {
+		if opts.Monitor != nil {
+			h.m = opts.Monitor
+		}
+		if opts.Level != nil {
+			h.level = opts.Level
+		}
+		h.name = opts.Name
+	}
+	return h
+}
+
+// Enabled implements slog.Handler.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	data := make(map[string]any, len(h.attrs)+r.NumAttrs()+1)
+	for _, ga := range h.attrs {
+		addSlogAttr(data, ga.groups, ga.attr)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(data, h.groups, a)
+		return true
+	})
+
+	name := r.Message
+	if h.name != "" {
+		name = h.name
+		data["msg"] = r.Message
+	}
+	h.m.Emit(ctx, name, data, WithLevel(slogLevel(r.Level)), WithoutSource())
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = slices.Clip(h.attrs)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, groupedAttr{groups: h.groups, attr: a})
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	return &h2
+}
+
+// slogLevel maps a slog level to the nearest monitor level at or below it.
+func slogLevel(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarn
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	}
+	return LevelDebug
+}
+
+// addSlogAttr stores a in data under the nested maps named by groups,
+// following slog's rules: empty attributes are skipped, and a group with an
+// empty key is inlined.
+func addSlogAttr(data map[string]any, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range attrs {
+			addSlogAttr(data, groups, ga)
+		}
+		return
+	}
+
+	for _, g := range groups {
+		sub, ok := data[g].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			data[g] = sub
+		}
+		data = sub
+	}
+	data[a.Key] = slogValue(a.Value)
+}
+
+// slogValue converts a resolved, non-group value for JSON: durations as
+// strings like "1.5s" and errors as their message, which would otherwise
+// marshal as nanoseconds and {}.
+func slogValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return v.Any()
+}
@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	if err := Init(Config{Service: "test-slog", DisableStdout: true, CaptureSource: new(bool)}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+
+	ctx := WithTraceID(WithRequestID(context.Background(), "req-1"), "trace-1")
+
+	t.Run("attributes and level", func(t *testing.T) {
+		logger := slog.New(NewSlogHandler(nil)).With("component", "billing")
+		events := Captured(func() {
+			logger.WarnContext(ctx, "invoice.late", "invoice_id", 7, "delay", 1500*time.Millisecond, "err", errors.New("boom"))
+		})
+		if len(events) != 1 {
+			t.Fatalf("got %d events, want 1", len(events))
+		}
+		e := events[0]
+		if e.Name != "invoice.late" || e.Level != LevelWarn {
+			t.Errorf("event = %s at %s, want invoice.late at warn", e.Name, e.Level)
+		}
+		if e.RequestID != "req-1" || e.TraceID != "trace-1" {
+			t.Errorf("IDs = %q/%q, want req-1/trace-1 from the context", e.RequestID, e.TraceID)
+		}
+		want := map[string]any{"component": "billing", "invoice_id": int64(7), "delay": "1.5s", "err": "boom"}
+		if !reflect.DeepEqual(e.Data, want) {
+			t.Errorf("data = %#v, want %#v", e.Data, want)
+		}
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		logger := slog.New(NewSlogHandler(&SlogHandlerOptions{Name: "app.log"})).
+			With("service_version", "1.2").
+			WithGroup("http").
+			With("method", "GET")
+		events := Captured(func() {
+			logger.InfoContext(ctx, "request done", "status", 200, slog.Group("client", "ip", "10.0.0.1"))
+		})
+		if len(events) != 1 {
+			t.Fatalf("got %d events, want 1", len(events))
+		}
+		want := map[string]any{
+			"msg":             "request done",
+			"service_version": "1.2",
+			"http": map[string]any{
+				"method": "GET",
+				"status": int64(200),
+				"client": map[string]any{"ip": "10.0.0.1"},
+			},
+		}
+		if e := events[0]; e.Name != "app.log" || !reflect.DeepEqual(e.Data, want) {
+			t.Errorf("event %s data = %#v, want app.log with %#v", e.Name, e.Data, want)
+		}
+	})
+
+	t.Run("levels", func(t *testing.T) {
+		logger := slog.New(NewSlogHandler(&SlogHandlerOptions{Level: slog.LevelDebug}))
+		events := Captured(func() {
+			logger.Debug("test.debug")
+			logger.Info("test.info")
+			logger.Error("test.error")
+			logger.Log(context.Background(), slog.LevelError+4, "test.critical")
+		})
+		var got []string
+		for _, e := range events {
+			got = append(got, e.Level)
+		}
+		want := []string{LevelDebug, LevelInfo, LevelError, LevelError}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("levels = %v, want %v", got, want)
+		}
+
+		if NewSlogHandler(nil).Enabled(context.Background(), slog.LevelDebug) {
+			t.Error("default handler should not be enabled for debug")
+		}
+	})
+}
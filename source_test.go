@@ -3,6 +3,10 @@ package monitor
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"testing"
 )
 
@@ -13,7 +17,7 @@ func TestSourceLocationCapture(t *testing.T) {
 
 	t.Run("Emit captures source location", func(t *testing.T) {
 		ctx := context.Background()
-		event := newEvent(ctx, "test.source", map[string]any{"key": "value"}, "info")
+		event := newEvent(ctx, defaultMonitor.config.Load(), "test.source", map[string]any{"key": "value"}, "info")
 		attachSourceLocation(&event, 1)
 
 		data, ok := event.Data.(map[string]any)
@@ -40,7 +44,7 @@ func TestSourceLocationCapture(t *testing.T) {
 
 	t.Run("source location with nil data", func(t *testing.T) {
 		ctx := context.Background()
-		event := newEvent(ctx, "test.nil-data", nil, "info")
+		event := newEvent(ctx, defaultMonitor.config.Load(), "test.nil-data", nil, "info")
 		attachSourceLocation(&event, 1)
 
 		data, ok := event.Data.(map[string]any)
@@ -55,7 +59,7 @@ func TestSourceLocationCapture(t *testing.T) {
 
 	t.Run("source location with non-map data", func(t *testing.T) {
 		ctx := context.Background()
-		event := newEvent(ctx, "test.string-data", "some string", "info")
+		event := newEvent(ctx, defaultMonitor.config.Load(), "test.string-data", "some string", "info")
 		attachSourceLocation(&event, 1)
 
 		data, ok := event.Data.(map[string]any)
@@ -79,10 +83,10 @@ func TestSourceLocationCapture(t *testing.T) {
 
 		ctx := context.Background()
 		// Create a test server to capture the emitted event
-		event := newEvent(ctx, "test.no-source", map[string]any{"key": "value"}, "info")
+		event := newEvent(ctx, defaultMonitor.config.Load(), "test.no-source", map[string]any{"key": "value"}, "info")
 
 		// When CaptureSource is false, Emit should not attach source
-		cfg := globalConfig.Load()
+		cfg := defaultMonitor.config.Load()
 		if captureSourceEnabled(cfg) {
 			t.Error("captureSourceEnabled should return false")
 		}
@@ -103,9 +107,75 @@ func TestSourceLocationCapture(t *testing.T) {
 			t.Fatalf("Init() error = %v", err)
 		}
 
-		cfg := globalConfig.Load()
+		cfg := defaultMonitor.config.Load()
 		if !captureSourceEnabled(cfg) {
 			t.Error("captureSourceEnabled should return true by default")
 		}
 	})
 }
+
+// lineAbove returns the location of the line above its call, formatted like
+// Event.Caller.
+func lineAbove() string {
+	_, file, line, _ := runtime.Caller(1)
+	return filepath.Base(filepath.Dir(file)) + "/" + filepath.Base(file) + ":" + strconv.Itoa(line-1)
+}
+
+func TestCaptureCaller(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		if err := Init(Config{Service: "test-caller", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		events := Captured(func() { Emit(ctx, "test.caller", nil) })
+		if len(events) != 1 || events[0].Caller != "" {
+			t.Errorf("Caller = %q, want empty without CaptureCaller", events[0].Caller)
+		}
+	})
+
+	if err := Init(Config{Service: "test-caller", DisableStdout: true, CaptureCaller: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	var want []string
+	events := Captured(func() {
+		Emit(ctx, "test.emit", nil)
+		want = append(want, lineAbove())
+		Info(ctx, "test.info", nil)
+		want = append(want, lineAbove())
+		EmitError(ctx, "test.error", errors.New("boom"))
+		want = append(want, lineAbove())
+		EmitAudit(ctx, "test.audit", nil)
+		want = append(want, lineAbove())
+		_, span := StartSpan(ctx, "test.span")
+		span.Finish()
+		want = append(want, lineAbove())
+	})
+	if len(events) != len(want) {
+		t.Fatalf("captured %d events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if event.Caller != want[i] {
+			t.Errorf("%s: Caller = %q, want %q", event.Name, event.Caller, want[i])
+		}
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		jsonBytes, _ := events[0].ToJSON()
+		var decoded map[string]any
+		if err := json.Unmarshal(jsonBytes, &decoded); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if decoded["caller"] != want[0] {
+			t.Errorf("caller = %v, want %q", decoded["caller"], want[0])
+		}
+	})
+
+	t.Run("WithoutSource", func(t *testing.T) {
+		events := Captured(func() { Emit(ctx, "test.caller", nil, WithoutSource()) })
+		if len(events) != 1 || events[0].Caller != "" {
+			t.Errorf("Caller = %q, want empty with WithoutSource", events[0].Caller)
+		}
+	})
+}
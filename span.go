@@ -0,0 +1,144 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SpanExporter receives finished spans, for export to a tracing backend
+// (see the otel subpackage). ExportSpan is called on the goroutine that calls
+// Span.Finish, so it must not block and must be safe for concurrent use.
+type SpanExporter interface {
+	ExportSpan(span FinishedSpan)
+}
+
+// FinishedSpan is a completed span as passed to a SpanExporter.
+type FinishedSpan struct {
+	Service      string
+	Env          string
+	Name         string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	RequestID    string
+	Start        time.Time
+	End          time.Time
+
+	// Attributes is the data set on the span, without duration_ms.
+	Attributes map[string]any
+
+	// Err is the error set with SetError, if any.
+	Err error
+}
+
+// Span times an operation as a child span of the context it was started
+// from. Finish emits an event named after the span and, when
+// Config.SpanExporter is set, exports it as a tracing span.
+type Span struct {
+	m     *Monitor
+	ctx   context.Context
+	name  string
+	start time.Time
+
+	mu       sync.Mutex
+	data     map[string]any
+	err      error
+	finished bool
+}
+
+// StartSpan starts a span named name. The returned context carries the span:
+// its span ID is new and the context's previous span becomes its parent. A
+// trace ID is generated if the context has none. Pass the context to work done
+// within the span so nested spans and events are attributed to it.
+//
+//	ctx, span := monitor.StartSpan(ctx, "db.load_user")
+//	defer span.Finish()
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	return defaultMonitor.StartSpan(ctx, name)
+}
+
+// StartSpan starts a span that is emitted and exported through m, like the
+// package-level StartSpan.
+func (m *Monitor) StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	if TraceID(ctx) == "" {
+		ctx = WithTraceID(ctx, newTraceID(m.config.Load()))
+	}
+	ctx = StartChildSpan(ctx)
+	return ctx, &Span{
+		m:     m,
+		ctx:   ctx,
+		name:  name,
+		start: time.Now(),
+		data:  make(map[string]any),
+	}
+}
+
+// SetData adds a key-value pair to the span's event data and span attributes.
+func (s *Span) SetData(key string, value any) *Span {
+	s.mu.Lock()
+	s.data[key] = value
+	s.mu.Unlock()
+	return s
+}
+
+// SetError marks the span as failed. The event is emitted at error level with
+// data["error"], and exported spans get an error status.
+func (s *Span) SetError(err error) *Span {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	return s
+}
+
+// Finish ends the span. It emits an event with the span's data plus
+// duration_ms, and exports the span if Config.SpanExporter is set. Events and
+// spans are independent: with DisableStdout and no shipper only the span is
+// exported, and without a SpanExporter only the event is emitted. Calls after
+// the first are no-ops.
+func (s *Span) Finish() {
+	end := time.Now()
+
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+	attrs := make(map[string]any, len(s.data))
+	for k, v := range s.data {
+		attrs[k] = v
+	}
+	spanErr := s.err
+	s.mu.Unlock()
+
+	data := make(map[string]any, len(attrs)+2)
+	for k, v := range attrs {
+		data[k] = v
+	}
+	data["duration_ms"] = end.Sub(s.start).Milliseconds()
+	level := LevelInfo
+	if spanErr != nil {
+		data["error"] = spanErr.Error()
+		level = LevelError
+	}
+	s.m.emitWithCallerDepth(s.ctx, s.name, data, level, 2)
+
+	cfg := s.m.activeConfig()
+	if cfg == nil || cfg.SpanExporter == nil || !sampled(s.ctx, cfg, level) {
+		return
+	}
+	cfg.SpanExporter.ExportSpan(FinishedSpan{
+		Service:      cfg.Service,
+		Env:          cfg.Env,
+		Name:         s.name,
+		TraceID:      TraceID(s.ctx),
+		SpanID:       SpanID(s.ctx),
+		ParentSpanID: ParentSpanID(s.ctx),
+		RequestID:    RequestID(s.ctx),
+		Start:        s.start,
+		End:          end,
+		Attributes:   attrs,
+		Err:          spanErr,
+	})
+}
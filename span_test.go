@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recordingSpanExporter records exported spans.
+type recordingSpanExporter struct {
+	mu    sync.Mutex
+	spans []FinishedSpan
+}
+
+func (r *recordingSpanExporter) ExportSpan(span FinishedSpan) {
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+}
+
+func TestSpan(t *testing.T) {
+	exp := &recordingSpanExporter{}
+	if err := Init(Config{Service: "test-span", Env: "test", DisableStdout: true, SpanExporter: exp}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	ctx := WithSpanID(WithTraceID(context.Background(), "trace-span"), "1111111111111111")
+	events := Captured(func() {
+		outerCtx, outer := StartSpan(ctx, "job.run")
+		_, inner := StartSpan(outerCtx, "db.query")
+		inner.SetData("table", "users").SetError(errors.New("timeout"))
+		inner.Finish()
+		inner.Finish() // no-op
+		outer.Finish()
+	})
+
+	if len(events) != 2 {
+		t.Fatalf("emitted %d events, want 2", len(events))
+	}
+	innerEvent, outerEvent := events[0], events[1]
+	if innerEvent.Name != "db.query" || innerEvent.Level != LevelError {
+		t.Errorf("inner event = %s at %s, want db.query at error", innerEvent.Name, innerEvent.Level)
+	}
+	data, _ := innerEvent.Data.(map[string]any)
+	if data["table"] != "users" || data["error"] != "timeout" || data["duration_ms"] == nil {
+		t.Errorf("inner data = %v, want table, error, and duration_ms", data)
+	}
+	if innerEvent.ParentSpanID != outerEvent.SpanID || outerEvent.ParentSpanID != "1111111111111111" {
+		t.Errorf("span chain = %s -> %s -> %s, want inner under outer under caller",
+			innerEvent.ParentSpanID, outerEvent.SpanID, outerEvent.ParentSpanID)
+	}
+
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	if len(exp.spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(exp.spans))
+	}
+	s := exp.spans[0]
+	if s.Name != "db.query" || s.Service != "test-span" || s.TraceID != "trace-span" || s.SpanID != innerEvent.SpanID {
+		t.Errorf("exported span = %+v, want db.query in trace-span with the event's span ID", s)
+	}
+	if s.Err == nil || s.Attributes["table"] != "users" || s.Attributes["duration_ms"] != nil {
+		t.Errorf("exported span attributes = %v err = %v, want table only and an error", s.Attributes, s.Err)
+	}
+	if !s.End.After(s.Start) && !s.End.Equal(s.Start) {
+		t.Errorf("span end %v before start %v", s.End, s.Start)
+	}
+}
+
+func TestStartSpanGeneratesTraceID(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "test.op")
+	if TraceID(ctx) == "" || SpanID(ctx) == "" {
+		t.Errorf("trace/span IDs = %q/%q, want both set", TraceID(ctx), SpanID(ctx))
+	}
+	span.Finish()
+}
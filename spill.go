@@ -0,0 +1,233 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Spill files are named spill-<unix nanos, zero-padded>.ndjson so sorting by
+// name is sorting by creation time.
+const (
+	spillPrefix = "spill-"
+	spillSuffix = ".ndjson"
+)
+
+// defaultMaxSpillBytes caps SpillDir when Config.MaxSpillBytes is unset.
+const defaultMaxSpillBytes = 100 << 20
+
+// maxSpillFileBytes is the size at which the current spill file is closed and
+// a new one started, so eviction and replay work in bounded chunks.
+const maxSpillFileBytes = 1 << 20
+
+// spillBuffer writes events that overflow the shipper queue to NDJSON files
+// under a directory and hands them back, oldest file first, for replay.
+type spillBuffer struct {
+	dir       string
+	maxBytes  int64
+	fileBytes int64
+
+	mu        sync.Mutex
+	f         *os.File
+	fPath     string
+	fSize     int64
+	total     int64
+	lastStamp int64
+	replaying string
+}
+
+// newSpillBuffer opens dir, creating it if needed. Spill files left by a
+// previous process count toward the cap and are replayed like new ones.
+func newSpillBuffer(dir string, maxBytes int64) (*spillBuffer, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxSpillBytes
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("monitor: create SpillDir: %w", err)
+	}
+	sb := &spillBuffer{
+		dir:       dir,
+		maxBytes:  maxBytes,
+		fileBytes: max(min(maxSpillFileBytes, maxBytes/8), 1),
+	}
+	files, err := sb.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		sb.total += f.size
+	}
+	return sb, nil
+}
+
+type spillFile struct {
+	path string
+	size int64
+}
+
+// files lists the spill files in dir, oldest first.
+func (sb *spillBuffer) files() ([]spillFile, error) {
+	entries, err := os.ReadDir(sb.dir)
+	if err != nil {
+		return nil, fmt.Errorf("monitor: read SpillDir: %w", err)
+	}
+	var files []spillFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, spillPrefix) || !strings.HasSuffix(name, spillSuffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, spillFile{path: filepath.Join(sb.dir, name), size: info.Size()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+// write appends event to the current spill file, then evicts the oldest
+// files while the directory is over its cap and returns their events.
+func (sb *spillBuffer) write(event Event) (evicted []Event, err error) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	line = append(line, '\n')
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	if sb.f == nil {
+		// Strictly increasing stamps keep names unique and in write order
+		stamp := max(time.Now().UnixNano(), sb.lastStamp+1)
+		sb.lastStamp = stamp
+		path := filepath.Join(sb.dir, fmt.Sprintf("%s%020d%s", spillPrefix, stamp, spillSuffix))
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		sb.f, sb.fPath, sb.fSize = f, path, 0
+	}
+
+	n, err := sb.f.Write(line)
+	sb.fSize += int64(n)
+	sb.total += int64(n)
+	if err != nil {
+		return nil, err
+	}
+	if sb.fSize >= sb.fileBytes {
+		sb.closeCurrent()
+	}
+
+	return sb.evict(), nil
+}
+
+// closeCurrent closes the file being written so the next write starts a new
+// one. Callers hold sb.mu.
+func (sb *spillBuffer) closeCurrent() {
+	if sb.f != nil {
+		sb.f.Close()
+		sb.f, sb.fPath, sb.fSize = nil, "", 0
+	}
+}
+
+// evict removes the oldest spill files until the directory is within
+// maxBytes and returns their events for the caller to report as dropped. The
+// file being replayed is skipped. Callers hold sb.mu.
+func (sb *spillBuffer) evict() (evicted []Event) {
+	if sb.total <= sb.maxBytes {
+		return nil
+	}
+	files, err := sb.files()
+	if err != nil {
+		return nil
+	}
+	for _, f := range files {
+		if sb.total <= sb.maxBytes {
+			break
+		}
+		if f.path == sb.replaying {
+			continue
+		}
+		if f.path == sb.fPath {
+			sb.closeCurrent()
+		}
+		events := readSpillFile(f.path)
+		if err := os.Remove(f.path); err != nil {
+			continue
+		}
+		sb.total -= f.size
+		fmt.Fprintf(os.Stderr, "monitor: SpillDir over MaxSpillBytes, dropping %d spilled events\n", len(events))
+		evicted = append(evicted, events...)
+	}
+	return evicted
+}
+
+// takeOldest reads the oldest spill file for replay, closing the current file
+// first so it can be replayed too. The file stays on disk until done is called
+// with its path. ok is false when there is nothing to replay.
+func (sb *spillBuffer) takeOldest() (path string, events []Event, ok bool) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	sb.closeCurrent()
+	files, err := sb.files()
+	if err != nil || len(files) == 0 {
+		return "", nil, false
+	}
+	path = files[0].path
+	sb.replaying = path
+	return path, readSpillFile(path), true
+}
+
+// done deletes a replayed spill file.
+func (sb *spillBuffer) done(path string) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	if info, err := os.Stat(path); err == nil {
+		if os.Remove(path) == nil {
+			sb.total -= info.Size()
+		}
+	}
+	sb.replaying = ""
+}
+
+// close closes the current spill file. Spilled events stay on disk for the
+// next process to replay.
+func (sb *spillBuffer) close() {
+	sb.mu.Lock()
+	sb.closeCurrent()
+	sb.mu.Unlock()
+}
+
+// readSpillFile parses the events in a spill file, skipping unreadable lines.
+func readSpillFile(path string) []Event {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: failed to read spill file: %v\n", err)
+		return nil
+	}
+	var events []Event
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			fmt.Fprintf(os.Stderr, "monitor: skipping unreadable spilled event: %v\n", err)
+			continue
+		}
+		event.priority = levelPriority(event.Level)
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedTransport blocks every Send until open is closed, then records the
+// delivered events' "seq" values in order.
+type gatedTransport struct {
+	open chan struct{}
+	mu   sync.Mutex
+	seqs []int
+}
+
+func (g *gatedTransport) Send(ctx context.Context, batch []Event) error {
+	select {
+	case <-g.open:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, e := range batch {
+		data, _ := e.Data.(map[string]any)
+		switch seq := data["seq"].(type) {
+		case int:
+			g.seqs = append(g.seqs, seq)
+		case float64: // round-tripped through a spill file
+			g.seqs = append(g.seqs, int(seq))
+		}
+	}
+	return nil
+}
+
+// spillFiles returns the spill files in dir and their total size.
+func spillFiles(t *testing.T, dir string) ([]string, int64) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, spillPrefix+"*"+spillSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += info.Size()
+	}
+	return matches, total
+}
+
+func TestSpillDir(t *testing.T) {
+	const batchSize, queueSize, emitted = 10, 10, 200
+
+	dir := t.TempDir()
+	gt := &gatedTransport{open: make(chan struct{})}
+	if err := Init(Config{
+		Service:       "test-spill",
+		DisableStdout: true,
+		Transport:     gt,
+		BatchSize:     batchSize,
+		QueueSize:     queueSize,
+		FlushEvery:    time.Hour,
+		SpillDir:      dir,
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	s := defaultMonitor.shipper.Load()
+
+	// The endpoint hangs: one batch is in flight, the queue fills, and the
+	// rest overflows to disk
+	for i := 0; i < emitted; i++ {
+		Emit(context.Background(), "test.spill", map[string]any{"seq": i})
+	}
+	files, _ := spillFiles(t, dir)
+	if len(files) == 0 {
+		t.Fatal("no spill files written on overflow")
+	}
+	spilled := make(map[int]bool)
+	for _, f := range files {
+		for _, e := range readSpillFile(f) {
+			spilled[int(e.Data.(map[string]any)["seq"].(float64))] = true
+		}
+	}
+	if held := s.held(); len(spilled)+held < emitted-batchSize {
+		t.Errorf("spilled %d + held %d events, want all %d accounted for", len(spilled), held, emitted)
+	}
+
+	// The endpoint recovers: the next successful flush replays the spill
+	close(gt.open)
+	if err := FlushContext(context.Background()); err != nil {
+		t.Fatalf("FlushContext() error = %v", err)
+	}
+
+	gt.mu.Lock()
+	seqs := append([]int(nil), gt.seqs...)
+	gt.mu.Unlock()
+	if len(seqs) != emitted {
+		t.Errorf("delivered %d events, want %d", len(seqs), emitted)
+	}
+	seen := make(map[int]bool, len(seqs))
+	for _, seq := range seqs {
+		seen[seq] = true
+	}
+	if len(seen) != emitted {
+		t.Errorf("delivered %d distinct events, want %d", len(seen), emitted)
+	}
+	// Spilled events replay in the order they were written
+	last := -1
+	for _, seq := range seqs {
+		if !spilled[seq] {
+			continue
+		}
+		if seq < last {
+			t.Fatalf("replayed events out of order: %v", seqs)
+		}
+		last = seq
+	}
+	if files, _ := spillFiles(t, dir); len(files) != 0 {
+		t.Errorf("spill files left after replay: %v", files)
+	}
+	Shutdown()
+}
+
+func TestSpillDirCap(t *testing.T) {
+	const maxSpill = 4096
+
+	var evicted atomic.Int32
+	dir := t.TempDir()
+	gt := &gatedTransport{open: make(chan struct{})}
+	if err := Init(Config{
+		Service:       "test-spill-cap",
+		DisableStdout: true,
+		Transport:     gt,
+		BatchSize:     10,
+		QueueSize:     10,
+		FlushEvery:    time.Hour,
+		SpillDir:      dir,
+		MaxSpillBytes: maxSpill,
+		OnDrop: func(e Event, reason string) {
+			if reason == DropReasonSpillFull {
+				evicted.Add(1)
+			}
+		},
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	for i := 0; i < 500; i++ {
+		Emit(context.Background(), "test.spill", map[string]any{"seq": i})
+	}
+
+	files, total := spillFiles(t, dir)
+	if total > maxSpill {
+		t.Errorf("SpillDir holds %d bytes, want at most %d", total, maxSpill)
+	}
+	if len(files) == 0 {
+		t.Fatal("no spill files kept")
+	}
+	if evicted.Load() == 0 {
+		t.Error("OnDrop not called for evicted spill files")
+	}
+
+	// The oldest files were evicted, so the oldest kept event is a late one
+	path, kept, ok := defaultMonitor.shipper.Load().spill.takeOldest()
+	if !ok || len(kept) == 0 {
+		t.Fatalf("takeOldest() = %v, %v", path, ok)
+	}
+	if first := kept[0].Data.(map[string]any)["seq"].(float64); first < 100 {
+		t.Errorf("oldest kept spilled event seq = %v, want oldest files evicted", first)
+	}
+	defaultMonitor.shipper.Load().spill.done(path)
+
+	close(gt.open)
+	Shutdown()
+}
@@ -0,0 +1,179 @@
+// Package sqlmonitor wraps database/sql drivers to emit a "db.query" event for
+// every query and exec, correlated with the request via the context passed to
+// QueryContext/ExecContext.
+//
+// Usage:
+//
+//	sqlmonitor.Register("monitor-postgres", &pq.Driver{}, sqlmonitor.Config{})
+//	db, err := sql.Open("monitor-postgres", dsn)
+//
+//	ctx = sqlmonitor.WithQueryName(r.Context(), "users.by_id")
+//	row := db.QueryRowContext(ctx, "SELECT * FROM users WHERE id = $1", id)
+//
+// Or, for drivers that expose a driver.Connector:
+//
+//	db := sql.OpenDB(sqlmonitor.WrapConnector(connector, sqlmonitor.Config{}))
+//
+// Events carry the operation ("query" or "exec"), the SQL text, duration_ms,
+// and the query name when one is set on the context. Query arguments are only
+// included when Config.IncludeArgs is set, and can be redacted with RedactArg.
+package sqlmonitor
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+// Config configures the emitted query events.
+type Config struct {
+	// EventName is the name of emitted events. Default: "db.query".
+	EventName string
+
+	// IncludeArgs adds the query arguments to events as "query_args". Default: false.
+	IncludeArgs bool
+
+	// RedactArg, when set, is called for each argument before it is emitted and
+	// its return value is used instead (e.g., return "[REDACTED]" for sensitive
+	// columns). Only consulted when IncludeArgs is true.
+	RedactArg func(ordinal int, name string, value any) any
+}
+
+type ctxKey int
+
+const ctxKeyQueryName ctxKey = iota
+
+// WithQueryName returns a new context carrying a logical name for the next
+// query (e.g., "users.by_id"), emitted as "query_name".
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, ctxKeyQueryName, name)
+}
+
+// QueryName returns the query name from the context, or empty string if not set.
+func QueryName(ctx context.Context) string {
+	if v, ok := ctx.Value(ctxKeyQueryName).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// Register wraps d and registers it with database/sql under name.
+func Register(name string, d driver.Driver, cfg Config) {
+	sql.Register(name, Wrap(d, cfg))
+}
+
+// Wrap returns a driver.Driver that emits events for queries run through d.
+func Wrap(d driver.Driver, cfg Config) driver.Driver {
+	if cfg.EventName == "" {
+		cfg.EventName = "db.query"
+	}
+	return &wrappedDriver{base: d, cfg: &cfg}
+}
+
+// WrapConnector returns a driver.Connector that emits events for queries run
+// through connections from c. Use with sql.OpenDB.
+func WrapConnector(c driver.Connector, cfg Config) driver.Connector {
+	if cfg.EventName == "" {
+		cfg.EventName = "db.query"
+	}
+	d := &wrappedDriver{base: c.Driver(), cfg: &cfg}
+	return &connector{base: c, driver: d}
+}
+
+type wrappedDriver struct {
+	base driver.Driver
+	cfg  *Config
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{base: c, cfg: d.cfg}, nil
+}
+
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.base.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &connector{base: c, driver: d}, nil
+	}
+	return &connector{base: dsnConnector{dsn: name, driver: d.base}, driver: d}, nil
+}
+
+type connector struct {
+	base   driver.Connector
+	driver *wrappedDriver
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	bc, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{base: bc, cfg: c.driver.cfg}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector adapts a driver without DriverContext to driver.Connector.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// emit records a single query or exec. driver.ErrSkip is not an execution
+// (database/sql retries via a prepared statement), so nothing is emitted.
+func (cfg *Config) emit(ctx context.Context, op, query string, args []driver.NamedValue, start time.Time, result driver.Result, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+
+	data := map[string]any{
+		"operation":   op,
+		"query":       query,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if name := QueryName(ctx); name != "" {
+		data["query_name"] = name
+	}
+	if cfg.IncludeArgs && len(args) > 0 {
+		values := make([]any, len(args))
+		for i, a := range args {
+			v := a.Value
+			if cfg.RedactArg != nil {
+				v = cfg.RedactArg(a.Ordinal, a.Name, v)
+			}
+			values[i] = v
+		}
+		data["query_args"] = values
+	}
+
+	level := monitor.LevelInfo
+	if err != nil {
+		data["error"] = err.Error()
+		level = monitor.LevelError
+	} else if result != nil {
+		if n, rerr := result.RowsAffected(); rerr == nil {
+			data["rows_affected"] = n
+		}
+	}
+
+	monitor.Emit(ctx, cfg.EventName, data, monitor.WithLevel(level), monitor.WithoutSource())
+}
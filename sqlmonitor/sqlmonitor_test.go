@@ -0,0 +1,225 @@
+package sqlmonitor
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+// recorder captures shipped events through a monitor.Transport.
+type recorder struct {
+	mu     sync.Mutex
+	events []monitor.Event
+}
+
+func (r *recorder) Send(ctx context.Context, batch []monitor.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, batch...)
+	return nil
+}
+
+func (r *recorder) flushed(t *testing.T) []monitor.Event {
+	t.Helper()
+	monitor.Flush()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]monitor.Event(nil), r.events...)
+}
+
+func initMonitor(t *testing.T) *recorder {
+	t.Helper()
+	rec := &recorder{}
+	if err := monitor.Init(monitor.Config{Service: "test-sql", DisableStdout: true, Transport: rec}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(monitor.Shutdown)
+	return rec
+}
+
+// fakeConn supports only the required driver.Conn methods, forcing
+// database/sql through prepared statements.
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+// fakeCtxConn adds direct query/exec support.
+type fakeCtxConn struct{ fakeConn }
+
+func (fakeCtxConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if query == "FAIL" {
+		return nil, errors.New("syntax error")
+	}
+	return &fakeRows{}, nil
+}
+
+func (fakeCtxConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(3), nil
+}
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                                    { return nil }
+func (fakeStmt) NumInput() int                                   { return -1 }
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error)  { return &fakeRows{}, nil }
+
+type fakeRows struct{}
+
+func (*fakeRows) Columns() []string              { return []string{"id"} }
+func (*fakeRows) Close() error                   { return nil }
+func (*fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+type fakeConnector struct{ conn driver.Conn }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{conn: c.conn} }
+
+type fakeDriver struct{ conn driver.Conn }
+
+// driverSeq numbers drivers registered by tests.
+var driverSeq atomic.Int64
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return d.conn, nil }
+
+func TestQueryEmitsEvent(t *testing.T) {
+	rec := initMonitor(t)
+
+	db := sql.OpenDB(WrapConnector(fakeConnector{conn: fakeCtxConn{}}, Config{}))
+	defer db.Close()
+
+	ctx := monitor.WithTraceID(context.Background(), "trace-db")
+	ctx = monitor.WithRequestID(ctx, "req-db")
+	ctx = WithQueryName(ctx, "users.by_id")
+
+	rows, err := db.QueryContext(ctx, "SELECT id FROM users WHERE id = ?", 42)
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	rows.Close()
+
+	events := rec.flushed(t)
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	e := events[0]
+	if e.Name != "db.query" {
+		t.Errorf("Name = %v, want db.query", e.Name)
+	}
+	if e.TraceID != "trace-db" || e.RequestID != "req-db" {
+		t.Errorf("IDs = (%v, %v), want (trace-db, req-db)", e.TraceID, e.RequestID)
+	}
+
+	data := e.Data.(map[string]any)
+	if data["query_name"] != "users.by_id" {
+		t.Errorf("query_name = %v, want users.by_id", data["query_name"])
+	}
+	if data["operation"] != "query" {
+		t.Errorf("operation = %v, want query", data["operation"])
+	}
+	if _, ok := data["duration_ms"]; !ok {
+		t.Error("data should contain duration_ms")
+	}
+	if _, ok := data["query_args"]; ok {
+		t.Error("query_args should be omitted unless IncludeArgs is set")
+	}
+	if _, ok := data["source_file"]; ok {
+		t.Error("source location should not be captured for driver events")
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	rec := initMonitor(t)
+
+	db := sql.OpenDB(WrapConnector(fakeConnector{conn: fakeCtxConn{}}, Config{}))
+	defer db.Close()
+
+	if _, err := db.QueryContext(context.Background(), "FAIL"); err == nil {
+		t.Fatal("QueryContext() should return the driver error")
+	}
+
+	events := rec.flushed(t)
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if events[0].Level != monitor.LevelError {
+		t.Errorf("Level = %v, want error", events[0].Level)
+	}
+	if data := events[0].Data.(map[string]any); data["error"] != "syntax error" {
+		t.Errorf("error = %v, want syntax error", data["error"])
+	}
+}
+
+func TestExecWithRedactedArgs(t *testing.T) {
+	rec := initMonitor(t)
+
+	db := sql.OpenDB(WrapConnector(fakeConnector{conn: fakeCtxConn{}}, Config{
+		IncludeArgs: true,
+		RedactArg: func(ordinal int, name string, value any) any {
+			if ordinal == 2 {
+				return "[REDACTED]"
+			}
+			return value
+		},
+	}))
+	defer db.Close()
+
+	if _, err := db.ExecContext(context.Background(), "UPDATE users SET password = ? WHERE id = ?", "alice", "hunter2"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	events := rec.flushed(t)
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	data := events[0].Data.(map[string]any)
+	args, ok := data["query_args"].([]any)
+	if !ok || len(args) != 2 {
+		t.Fatalf("query_args = %v, want 2 args", data["query_args"])
+	}
+	if args[0] != "alice" || args[1] != "[REDACTED]" {
+		t.Errorf("query_args = %v, want [alice [REDACTED]]", args)
+	}
+	if data["rows_affected"] != int64(3) {
+		t.Errorf("rows_affected = %v, want 3", data["rows_affected"])
+	}
+}
+
+func TestPreparedStatementFallback(t *testing.T) {
+	rec := initMonitor(t)
+
+	// sql.Register panics on a reused name, so each run (e.g. -count=2)
+	// registers its own
+	name := fmt.Sprintf("sqlmonitor-%s-%d", t.Name(), driverSeq.Add(1))
+	Register(name, fakeDriver{conn: fakeConn{}}, Config{EventName: "db.call"})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM sessions"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	events := rec.flushed(t)
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1 (ErrSkip must not be emitted)", len(events))
+	}
+	if events[0].Name != "db.call" {
+		t.Errorf("Name = %v, want db.call", events[0].Name)
+	}
+	if data := events[0].Data.(map[string]any); data["operation"] != "exec" {
+		t.Errorf("operation = %v, want exec", data["operation"])
+	}
+}
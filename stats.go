@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"errors"
+	"time"
+)
+
+// ShipperStats is a snapshot of the shipper's queue and delivery counters.
+// With several ingest endpoints (Config.IngestURLs or Config.Endpoints), the
+// counts are summed across endpoints, QueueHighWater is the deepest of their
+// queues, LastFlushTime the latest flush, and LastFlushError joins each
+// endpoint's last flush error.
+type ShipperStats struct {
+	// Enabled is false when no shipper is running (stdout-only mode); all
+	// other fields are then zero.
+	Enabled bool
+
+	// QueuedEvents is the number of events waiting in the queue.
+	QueuedEvents int
+
+	// QueueCapacity is the queue size (Config.QueueSize or its default).
+	QueueCapacity int
+
+	// QueueHighWater is the deepest the queue has been since Init. A value
+	// close to QueueCapacity means bursts are near the point of dropping events.
+	QueueHighWater int
+
+	// TotalShipped is the number of events the endpoint has accepted since Init.
+	TotalShipped uint64
+
+	// TotalDropped is the number of events discarded since Init, for any of
+	// the reasons reported to Config.OnDrop.
+	TotalDropped uint64
+
+	// TotalBatches is the number of batches delivered since Init.
+	TotalBatches uint64
+
+	// TotalRetries is the number of delivery attempts since Init that retried
+	// a failed batch.
+	TotalRetries uint64
+
+	// LastFlushError is why the most recent flush failed to deliver a batch
+	// (dropped, or left buffered at a FlushContext deadline), or nil if it
+	// delivered everything. Failed attempts that a retry recovered from are
+	// not reported.
+	LastFlushError error
+
+	// LastFlushTime is when the most recent flush with events to ship
+	// finished. Zero until the first one.
+	LastFlushTime time.Time
+
+	// CircuitState is the ingest circuit breaker's state: CircuitClosed,
+	// CircuitOpen, or CircuitHalfOpen (the cooldown has passed and the next
+	// batch probes the endpoint). Always CircuitClosed unless
+	// Config.CircuitThreshold is set. With several endpoints, the least
+	// healthy state is reported.
+	CircuitState string
+}
+
+// circuitSeverity orders circuit states from healthy to failing, for
+// reporting the least healthy endpoint.
+var circuitSeverity = map[string]int{CircuitClosed: 0, CircuitHalfOpen: 1, CircuitOpen: 2}
+
+// Stats returns a snapshot of the shipper's queue and delivery counters. It
+// is cheap, lock-free, and safe to call from any goroutine, e.g., from a
+// metrics scrape handler.
+func Stats() ShipperStats {
+	return defaultMonitor.Stats()
+}
+
+// Stats returns a snapshot of m's shipper counters, like the package-level Stats.
+func (m *Monitor) Stats() ShipperStats {
+	s := m.shipper.Load()
+	if s == nil {
+		return ShipperStats{}
+	}
+	st := ShipperStats{Enabled: true, CircuitState: CircuitClosed}
+	var errs []error
+	for _, sh := range s.all() {
+		st.QueuedEvents += len(sh.eventsCh)
+		st.QueueCapacity += cap(sh.eventsCh)
+		st.QueueHighWater = max(st.QueueHighWater, int(sh.queueHighWater.Load()))
+		st.TotalShipped += sh.totalShipped.Load()
+		st.TotalDropped += sh.totalDropped.Load()
+		st.TotalBatches += sh.totalBatches.Load()
+		st.TotalRetries += sh.totalRetries.Load()
+		if state := sh.breaker.currentState(); circuitSeverity[state] > circuitSeverity[st.CircuitState] {
+			st.CircuitState = state
+		}
+		if last := sh.lastFlush.Load(); last != nil {
+			if last.err != nil {
+				errs = append(errs, last.err)
+			}
+			if last.at.After(st.LastFlushTime) {
+				st.LastFlushTime = last.at
+			}
+		}
+	}
+	if len(errs) == 1 {
+		st.LastFlushError = errs[0]
+	} else {
+		st.LastFlushError = errors.Join(errs...)
+	}
+	return st
+}
@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsQueue(t *testing.T) {
+	t.Run("disabled without shipper", func(t *testing.T) {
+		if err := Init(Config{Service: "test-stats", DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if st := Stats(); st != (ShipperStats{}) {
+			t.Errorf("Stats() = %+v, want zero value", st)
+		}
+	})
+
+	t.Run("default queue size", func(t *testing.T) {
+		if err := Init(Config{Service: "test-stats", DisableStdout: true, Transport: &recordingTransport{}, BatchSize: 10}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		st := Stats()
+		if !st.Enabled || st.QueueCapacity != 20 {
+			t.Errorf("Stats() = %+v, want Enabled with QueueCapacity 20", st)
+		}
+	})
+
+	t.Run("QueueSize smaller than BatchSize", func(t *testing.T) {
+		err := Init(Config{Service: "test-stats", IngestURL: "http://unused", BatchSize: 100, QueueSize: 10})
+		if err != ErrQueueSizeTooSmall {
+			t.Errorf("Init() error = %v, want ErrQueueSizeTooSmall", err)
+		}
+	})
+
+	t.Run("high-water mark", func(t *testing.T) {
+		// Unstarted shipper so queued events stay put
+		s := newShipper(defaultMonitor, &Config{Service: "test-stats", IngestURL: "http://unused", BatchSize: 10, QueueSize: 50, FlushEvery: time.Second})
+		defaultMonitor.shipper.Store(s)
+		defer defaultMonitor.shipper.Store(nil)
+
+		for i := 0; i < 30; i++ {
+			s.send(Event{Name: "test.burst"})
+		}
+		st := Stats()
+		if st.QueueCapacity != 50 || st.QueuedEvents != 30 || st.QueueHighWater != 30 {
+			t.Errorf("Stats() = %+v, want capacity 50, queued 30, high-water 30", st)
+		}
+
+		s.drainQueued()
+		s.send(Event{Name: "test.after"})
+		st = Stats()
+		if st.QueuedEvents != 1 || st.QueueHighWater != 30 {
+			t.Errorf("Stats() = %+v, want queued 1 with high-water kept at 30", st)
+		}
+	})
+}
+
+func TestStatsDelivery(t *testing.T) {
+	t.Run("counts shipped events and batches", func(t *testing.T) {
+		rt := &recordingTransport{}
+		if err := Init(Config{Service: "test-stats", DisableStdout: true, Transport: rt, BatchSize: 10, QueueSize: 50}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		before := time.Now()
+		for i := 0; i < 25; i++ {
+			Emit(context.Background(), "test.stats", nil)
+		}
+		Flush()
+
+		st := Stats()
+		if st.TotalShipped != 25 || st.TotalBatches != 3 || st.TotalDropped != 0 {
+			t.Errorf("Stats() = %+v, want 25 shipped in 3 batches, none dropped", st)
+		}
+		if st.LastFlushError != nil {
+			t.Errorf("LastFlushError = %v, want nil", st.LastFlushError)
+		}
+		if st.LastFlushTime.Before(before) {
+			t.Errorf("LastFlushTime = %v, want after %v", st.LastFlushTime, before)
+		}
+	})
+
+	t.Run("counts dropped events and reports the error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		if err := Init(Config{Service: "test-stats", DisableStdout: true, IngestURL: server.URL, BatchSize: 10}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		for i := 0; i < 4; i++ {
+			Emit(context.Background(), "test.stats", nil)
+		}
+		Flush()
+
+		st := Stats()
+		if st.TotalShipped != 0 || st.TotalBatches != 0 || st.TotalDropped != 4 {
+			t.Errorf("Stats() = %+v, want 4 dropped, none shipped", st)
+		}
+		if st.LastFlushError == nil || !strings.Contains(st.LastFlushError.Error(), "400") {
+			t.Errorf("LastFlushError = %v, want the 400 status", st.LastFlushError)
+		}
+	})
+
+	t.Run("recovered retries are not errors", func(t *testing.T) {
+		rt := &recordingTransport{failN: 1}
+		if err := Init(Config{Service: "test-stats", DisableStdout: true, Transport: rt, BatchSize: 10, RetryBaseDelay: time.Millisecond}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		Emit(context.Background(), "test.stats", nil)
+		Flush()
+
+		if st := Stats(); st.TotalShipped != 1 || st.LastFlushError != nil {
+			t.Errorf("Stats() = %+v, want 1 shipped and no LastFlushError", st)
+		}
+	})
+}
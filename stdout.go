@@ -0,0 +1,177 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// stdout is where events are printed when Config.Output is nil. A variable so
+// tests can capture output.
+var stdout io.Writer = os.Stdout
+
+// syncWriter serializes writes to a Config.Output that may not be safe for
+// concurrent use, such as a bytes.Buffer.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// baseOutput returns the writer events are written to before any buffering:
+// Config.Output if set, otherwise stdout.
+func baseOutput(cfg *Config) io.Writer {
+	if cfg != nil && cfg.output != nil {
+		return cfg.output
+	}
+	return stdout
+}
+
+// syncStdoutEnabled returns true if stdout should be written synchronously.
+// Defaults to true when SyncStdout is nil (not explicitly set).
+func syncStdoutEnabled(cfg *Config) bool {
+	if cfg.SyncStdout == nil {
+		return true
+	}
+	return *cfg.SyncStdout
+}
+
+// errStdoutFull is returned by stdoutBuffer.Write when its queue is full.
+var errStdoutFull = errors.New("monitor: stdout queue full")
+
+// stdoutBuffer writes stdout asynchronously: lines are queued on a channel
+// and a single goroutine writes them to a bufio.Writer, flushed every
+// interval, on Flush, and on Shutdown. Emit never waits on a slow terminal or
+// pipe; when the queue is full the line is dropped instead.
+type stdoutBuffer struct {
+	lines   chan []byte
+	flushCh chan chan struct{}
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+
+	// w is only used by the run goroutine.
+	w *bufio.Writer
+}
+
+// newStdoutBuffer creates a buffer over w holding up to size queued lines and
+// starts its writer goroutine.
+func newStdoutBuffer(w io.Writer, interval time.Duration, size int) *stdoutBuffer {
+	b := &stdoutBuffer{
+		lines:   make(chan []byte, size),
+		flushCh: make(chan chan struct{}),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+		w:       bufio.NewWriter(w),
+	}
+	go b.run(interval)
+	return b
+}
+
+func (b *stdoutBuffer) run(interval time.Duration) {
+	defer close(b.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case line := <-b.lines:
+			b.write(line)
+		case <-ticker.C:
+			b.flushWriter()
+		case done := <-b.flushCh:
+			b.drain()
+			b.flushWriter()
+			close(done)
+		case <-b.stopCh:
+			b.drain()
+			b.flushWriter()
+			return
+		}
+	}
+}
+
+// drain writes the lines queued so far. Lines enqueued while it runs wait
+// for the next pass, so a steady stream of emits can't keep it going.
+func (b *stdoutBuffer) drain() {
+	for range len(b.lines) {
+		b.write(<-b.lines)
+	}
+}
+
+func (b *stdoutBuffer) write(line []byte) {
+	if _, err := b.w.Write(line); err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: failed to write event: %v\n", err)
+	}
+}
+
+func (b *stdoutBuffer) flushWriter() {
+	if err := b.w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "monitor: failed to write event: %v\n", err)
+	}
+}
+
+// enqueue queues one complete NDJSON line, taking ownership of it. It
+// reports false, without blocking, if the queue is full.
+func (b *stdoutBuffer) enqueue(line []byte) bool {
+	select {
+	case b.lines <- line:
+		return true
+	default:
+		return false
+	}
+}
+
+// Write queues a copy of one complete NDJSON line, returning errStdoutFull
+// if the queue is full.
+func (b *stdoutBuffer) Write(p []byte) (int, error) {
+	if !b.enqueue(bytes.Clone(p)) {
+		return 0, errStdoutFull
+	}
+	return len(p), nil
+}
+
+// flush writes every line queued before the call to the underlying writer,
+// giving up when ctx is done.
+func (b *stdoutBuffer) flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case b.flushCh <- done:
+	case <-b.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stop writes and flushes the remaining lines and stops the writer goroutine.
+func (b *stdoutBuffer) stop() {
+	close(b.stopCh)
+	<-b.doneCh
+}
+
+// stdoutTarget returns the writer for event output: the buffer when output
+// is buffered, otherwise Config.Output or stdout itself.
+func (m *Monitor) stdoutTarget() io.Writer {
+	if b := m.stdoutBuffer.Load(); b != nil {
+		return b
+	}
+	return baseOutput(m.config.Load())
+}
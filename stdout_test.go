@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer safe for the stdout flusher goroutine.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(b.buf.String()), "\n")
+}
+
+// osStdout is the real stdout writer, restored after captureStdout.
+var osStdout = stdout
+
+// captureStdout redirects event output to a buffer for the test.
+func captureStdout(t *testing.T) *lockedBuffer {
+	t.Helper()
+	out := &lockedBuffer{}
+	stdout = out
+	t.Cleanup(func() { stdout = osStdout })
+	return out
+}
+
+func TestSyncStdout(t *testing.T) {
+	t.Run("sync by default", func(t *testing.T) {
+		out := captureStdout(t)
+		if err := Init(Config{Service: "test-stdout"}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		Emit(context.Background(), "test.first", nil)
+		if got := out.lines(); len(got) != 1 || !strings.Contains(got[0], `"name":"test.first"`) {
+			t.Errorf("stdout = %v, want test.first written before Emit returns", got)
+		}
+	})
+
+	t.Run("buffered", func(t *testing.T) {
+		out := captureStdout(t)
+		syncStdout := false
+		if err := Init(Config{Service: "test-stdout", SyncStdout: &syncStdout, FlushEvery: time.Hour}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		Emit(context.Background(), "test.one", nil)
+		Emit(context.Background(), "test.two", nil)
+		if got := out.lines(); len(got) != 0 {
+			t.Errorf("stdout = %v, want nothing before Flush", got)
+		}
+
+		Flush()
+		got := out.lines()
+		if len(got) != 2 || !strings.Contains(got[0], "test.one") || !strings.Contains(got[1], "test.two") {
+			t.Errorf("stdout after Flush = %v, want test.one then test.two", got)
+		}
+	})
+
+	t.Run("buffered flushed on Shutdown", func(t *testing.T) {
+		out := captureStdout(t)
+		syncStdout := false
+		if err := Init(Config{Service: "test-stdout", SyncStdout: &syncStdout, FlushEvery: time.Hour}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+
+		Emit(context.Background(), "test.last", nil)
+		Shutdown()
+		if got := out.lines(); len(got) != 1 {
+			t.Errorf("stdout after Shutdown = %v, want 1 line", got)
+		}
+	})
+}
+
+// blockingWriter blocks every Write until release is closed, like a stalled
+// terminal or pipe. entered is closed on the first Write.
+type blockingWriter struct {
+	lockedBuffer
+	entered, release chan struct{}
+	once             sync.Once
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.release
+	return w.lockedBuffer.Write(p)
+}
+
+func TestAsyncStdoutDoesNotBlock(t *testing.T) {
+	w := &blockingWriter{entered: make(chan struct{}), release: make(chan struct{})}
+	var drops atomic.Int64
+	syncStdout := false
+	m, err := New(Config{
+		Service:         "test-async-stdout",
+		Output:          w,
+		SyncStdout:      &syncStdout,
+		FlushEvery:      time.Hour,
+		StdoutQueueSize: 4,
+		Transport:       &recordingTransport{},
+		OnDrop: func(event Event, reason string) {
+			if reason == DropReasonStdoutFull {
+				drops.Add(1)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+
+	// Stall the writer goroutine in a flush
+	m.Emit(context.Background(), "test.first", nil)
+	go m.Flush()
+	<-w.entered
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		m.Emit(context.Background(), "test.async", map[string]any{"i": i})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Emit took %v with a blocked writer, want it not to block", elapsed)
+	}
+	if got := drops.Load(); got != 16 {
+		t.Errorf("OnDrop(DropReasonStdoutFull) called %d times, want 16", got)
+	}
+	if got := m.Stats().TotalDropped; got != 16 {
+		t.Errorf("Stats().TotalDropped = %d, want 16", got)
+	}
+
+	close(w.release)
+	m.Flush()
+	got := w.lines()
+	if len(got) != 5 {
+		t.Fatalf("stdout after Flush = %d lines, want 5", len(got))
+	}
+	if !strings.Contains(got[0], "test.first") || !strings.Contains(got[4], `"i":3`) {
+		t.Errorf("stdout after Flush = %v, want test.first then the first 4 queued events", got)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	t.Run("concurrent emits write whole lines", func(t *testing.T) {
+		out := captureStdout(t)
+		var buf bytes.Buffer // not safe for concurrent use on its own
+		if err := Init(Config{Service: "test-output", Output: &buf}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+
+		const goroutines, perGoroutine = 20, 50
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < perGoroutine; i++ {
+					Emit(context.Background(), "test.output", map[string]any{"i": i})
+				}
+			}()
+		}
+		wg.Wait()
+		Shutdown()
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != goroutines*perGoroutine {
+			t.Fatalf("Output got %d lines, want %d", len(lines), goroutines*perGoroutine)
+		}
+		for _, line := range lines {
+			var e Event
+			if err := json.Unmarshal([]byte(line), &e); err != nil || e.Name != "test.output" {
+				t.Fatalf("Output line %q is not a whole event: %v", line, err)
+			}
+		}
+		if got := out.lines(); len(got) != 0 {
+			t.Errorf("stdout = %v, want nothing when Output is set", got)
+		}
+	})
+
+	t.Run("buffered", func(t *testing.T) {
+		var buf lockedBuffer
+		syncStdout := false
+		if err := Init(Config{Service: "test-output", Output: &buf, SyncStdout: &syncStdout, FlushEvery: time.Hour}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		Emit(context.Background(), "test.buffered", nil)
+		Shutdown()
+
+		if got := buf.lines(); len(got) != 1 || !strings.Contains(got[0], "test.buffered") {
+			t.Errorf("Output = %v, want test.buffered written on Shutdown", got)
+		}
+	})
+
+	t.Run("DisableStdout wins", func(t *testing.T) {
+		var buf lockedBuffer
+		if err := Init(Config{Service: "test-output", Output: &buf, DisableStdout: true}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		Emit(context.Background(), "test.hidden", nil)
+		if got := buf.lines(); len(got) != 0 {
+			t.Errorf("Output = %v, want nothing with DisableStdout", got)
+		}
+	})
+}
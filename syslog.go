@@ -0,0 +1,52 @@
+//go:build !windows && !plan9
+
+package monitor
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// syslogSink writes events to syslog, enabled by Config.SyslogNetwork or
+// Config.SyslogAddr.
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// dialSyslog connects to the syslog server cfg names, tagging messages with
+// the service name. UDP is assumed when only SyslogAddr is set.
+func dialSyslog(cfg *Config) (*syslogSink, error) {
+	network := cfg.SyslogNetwork
+	if network == "" {
+		network = "udp"
+	}
+	w, err := syslog.Dial(network, cfg.SyslogAddr, syslog.LOG_USER|syslog.LOG_INFO, cfg.Service)
+	if err != nil {
+		return nil, fmt.Errorf("monitor: dial syslog: %w", err)
+	}
+	return &syslogSink{w: w}, nil
+}
+
+// write sends line at the syslog severity for level. Errors are ignored, as
+// for stdout; the writer reconnects on the next write.
+func (s *syslogSink) write(level string, line []byte) {
+	msg := string(line)
+	switch level {
+	case LevelFatal:
+		s.w.Crit(msg)
+	case LevelError:
+		s.w.Err(msg)
+	case LevelWarn:
+		s.w.Warning(msg)
+	case LevelAudit:
+		s.w.Notice(msg)
+	case LevelDebug:
+		s.w.Debug(msg)
+	default:
+		s.w.Info(msg)
+	}
+}
+
+func (s *syslogSink) close() {
+	s.w.Close()
+}
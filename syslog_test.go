@@ -0,0 +1,75 @@
+//go:build !windows && !plan9
+
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer conn.Close()
+
+	m, err := New(Config{
+		Service:       "test-syslog",
+		DisableStdout: true,
+		CaptureSource: new(bool),
+		SyslogNetwork: "udp",
+		SyslogAddr:    conn.LocalAddr().String(),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Shutdown()
+
+	// Priority is facility (LOG_USER, 8) plus severity
+	tests := []struct {
+		level    string
+		priority string
+	}{
+		{LevelError, "<11>"},
+		{LevelWarn, "<12>"},
+		{LevelInfo, "<14>"},
+		{LevelDebug, "<15>"},
+	}
+	buf := make([]byte, 64<<10)
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			m.Emit(context.Background(), "test.syslog", map[string]any{"n": 1}, WithLevel(tt.level))
+
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+			msg := string(buf[:n])
+			if !strings.HasPrefix(msg, tt.priority) {
+				t.Errorf("message = %q, want priority %s", msg, tt.priority)
+			}
+			if !strings.Contains(msg, " test-syslog[") {
+				t.Errorf("message = %q, want the service as tag", msg)
+			}
+
+			// The payload follows the "tag[pid]: " header
+			_, payload, ok := strings.Cut(msg, "]: ")
+			if !ok {
+				t.Fatalf("message = %q, want a syslog header", msg)
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(strings.TrimSuffix(payload, "\n")), &event); err != nil {
+				t.Fatalf("payload %q is not one JSON event: %v", payload, err)
+			}
+			if event.Name != "test.syslog" || event.Level != tt.level || event.Service != "test-syslog" {
+				t.Errorf("event = %+v, want test.syslog at %s", event, tt.level)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+//go:build windows || plan9
+
+package monitor
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// syslogSink is unavailable where log/syslog is not implemented.
+type syslogSink struct{}
+
+func dialSyslog(*Config) (*syslogSink, error) {
+	return nil, fmt.Errorf("monitor: syslog is not supported on %s", runtime.GOOS)
+}
+
+func (*syslogSink) write(string, []byte) {}
+
+func (*syslogSink) close() {}
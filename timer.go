@@ -8,6 +8,7 @@ import (
 
 // Timer tracks duration for an operation and emits an event when ended.
 type Timer struct {
+	m     *Monitor
 	start time.Time
 	name  string
 	data  map[string]any
@@ -16,7 +17,13 @@ type Timer struct {
 
 // StartTimer creates a new Timer that begins tracking immediately.
 func StartTimer(name string) *Timer {
+	return defaultMonitor.StartTimer(name)
+}
+
+// StartTimer creates a Timer whose event is emitted through m.
+func (m *Monitor) StartTimer(name string) *Timer {
 	return &Timer{
+		m:     m,
 		start: time.Now(),
 		name:  name,
 		data:  make(map[string]any),
@@ -43,5 +50,5 @@ func (t *Timer) End(ctx context.Context) {
 	}
 	t.mu.Unlock()
 
-	emitWithCallerDepth(ctx, t.name, data, LevelInfo, 2)
+	t.m.emitWithCallerDepth(ctx, t.name, data, LevelInfo, 2)
 }
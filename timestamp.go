@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Timestamp formats for Config.TimestampFormat.
+const (
+	// TimestampRFC3339Nano writes timestamps as RFC 3339 strings with
+	// nanoseconds in UTC (e.g., "2024-05-01T12:00:00.123456789Z"). This is
+	// the default.
+	TimestampRFC3339Nano = "rfc3339nano"
+
+	// TimestampUnixMillis writes timestamps as a JSON number of milliseconds
+	// since the Unix epoch (e.g., 1714564800123).
+	TimestampUnixMillis = "unix_millis"
+
+	// TimestampUnixNanos writes timestamps as a JSON number of nanoseconds
+	// since the Unix epoch (e.g., 1714564800123456789).
+	TimestampUnixNanos = "unix_nanos"
+)
+
+// validTimestampFormat reports whether format is empty (the default) or a known timestamp format.
+func validTimestampFormat(format string) bool {
+	switch format {
+	case "", TimestampRFC3339Nano, TimestampUnixMillis, TimestampUnixNanos:
+		return true
+	}
+	return false
+}
+
+// formatTimestamp formats t for Event.Timestamp in cfg's TimestampFormat.
+// cfg may be nil.
+func formatTimestamp(cfg *Config, t time.Time) string {
+	if cfg != nil {
+		switch cfg.TimestampFormat {
+		case TimestampUnixMillis:
+			return strconv.FormatInt(t.UnixMilli(), 10)
+		case TimestampUnixNanos:
+			return strconv.FormatInt(t.UnixNano(), 10)
+		}
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// numericTimestamp reports whether ts is a Unix timestamp (all digits), which
+// is written to JSON as a number.
+func numericTimestamp(ts string) bool {
+	if ts == "" {
+		return false
+	}
+	for i := 0; i < len(ts); i++ {
+		if ts[i] < '0' || ts[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// unixNanosThreshold separates Unix milliseconds from nanoseconds: no
+// millisecond timestamp before the year 33000 reaches it, and no nanosecond
+// timestamp after 1970-01-12 falls below it.
+const unixNanosThreshold = 1e15
+
+// Time parses the event's timestamp in any Config.TimestampFormat. Numeric
+// timestamps are read as milliseconds or nanoseconds by magnitude.
+func (e Event) Time() (time.Time, error) {
+	if !numericTimestamp(e.Timestamp) {
+		return time.Parse(time.RFC3339Nano, e.Timestamp)
+	}
+	n, err := strconv.ParseInt(e.Timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("monitor: invalid timestamp %q: %w", e.Timestamp, err)
+	}
+	if n < unixNanosThreshold {
+		return time.UnixMilli(n).UTC(), nil
+	}
+	return time.Unix(0, n).UTC(), nil
+}
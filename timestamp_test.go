@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampFormat(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	tests := []struct {
+		format   string
+		wantJSON string
+		wantTime time.Time
+	}{
+		{"", `"timestamp":"2024-05-01T12:00:00.123456789Z"`, at},
+		{TimestampRFC3339Nano, `"timestamp":"2024-05-01T12:00:00.123456789Z"`, at},
+		{TimestampUnixMillis, `"timestamp":1714564800123,`, at.Truncate(time.Millisecond)},
+		{TimestampUnixNanos, `"timestamp":1714564800123456789,`, at},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			event := Event{Timestamp: formatTimestamp(&Config{TimestampFormat: tt.format}, at), Name: "test.ts", Level: LevelInfo}
+			b, err := json.Marshal(event)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !strings.Contains(string(b), tt.wantJSON) {
+				t.Errorf("JSON = %s, want it to contain %s", b, tt.wantJSON)
+			}
+
+			var decoded Event
+			if err := json.Unmarshal(b, &decoded); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if decoded.Timestamp != event.Timestamp {
+				t.Errorf("round trip Timestamp = %q, want %q", decoded.Timestamp, event.Timestamp)
+			}
+			got, err := decoded.Time()
+			if err != nil {
+				t.Fatalf("Time() error = %v", err)
+			}
+			if !got.Equal(tt.wantTime) {
+				t.Errorf("Time() = %v, want %v", got, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestTimestampFormatInit(t *testing.T) {
+	if err := Init(Config{Service: "test-ts", TimestampFormat: "unix_seconds"}); err == nil {
+		t.Error("Init() accepted an unknown TimestampFormat")
+	}
+
+	if err := Init(Config{Service: "test-ts", DisableStdout: true, TimestampFormat: TimestampUnixMillis}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Shutdown()
+	before := time.Now()
+	events := Captured(func() {
+		Emit(context.Background(), "test.ts", nil)
+	})
+	if len(events) != 1 {
+		t.Fatalf("captured %d events, want 1", len(events))
+	}
+	if !numericTimestamp(events[0].Timestamp) {
+		t.Fatalf("Timestamp = %q, want Unix milliseconds", events[0].Timestamp)
+	}
+	got, err := events[0].Time()
+	if err != nil {
+		t.Fatalf("Time() error = %v", err)
+	}
+	if d := got.Sub(before); d < -time.Millisecond || d > time.Second {
+		t.Errorf("Time() = %v, want about %v", got, before)
+	}
+}
@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// formatTraceparent builds a W3C traceparent header value
+// ("00-<trace-id>-<span-id>-01") from the context's trace and span IDs. UUID
+// trace IDs are accepted with their dashes removed. Returns empty string when
+// either ID can't be expressed in the W3C format.
+func formatTraceparent(traceID, spanID string) string {
+	traceID = strings.ToLower(strings.ReplaceAll(traceID, "-", ""))
+	spanID = strings.ToLower(spanID)
+	if !isHexID(traceID, 32) || !isHexID(spanID, 16) {
+		return ""
+	}
+	return "00-" + traceID + "-" + spanID + "-01"
+}
+
+// parseTraceparent extracts the trace ID and parent span ID from a W3C
+// traceparent header ("<version>-<trace-id>-<parent-id>-<flags>"). ok is false
+// when the header is absent or malformed, which the spec says to ignore.
+// Versions after 00 may append fields, which are skipped.
+func parseTraceparent(header string) (traceID, parentID string, ok bool) {
+	if len(header) < 55 || header != strings.ToLower(header) {
+		return "", "", false
+	}
+	version, flags := header[0:2], header[53:55]
+	if !isHex(version) || version == "ff" || !isHex(flags) {
+		return "", "", false
+	}
+	if (version == "00" && len(header) != 55) || (len(header) > 55 && header[55] != '-') {
+		return "", "", false
+	}
+	if header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return "", "", false
+	}
+	traceID, parentID = header[3:35], header[36:52]
+	if !isHexID(traceID, 32) || !isHexID(parentID, 16) {
+		return "", "", false
+	}
+	return traceID, parentID, true
+}
+
+// isHexID reports whether id is n lowercase hex characters and not all zeros,
+// which W3C Trace Context treats as invalid.
+func isHexID(id string, n int) bool {
+	return len(id) == n && strings.Trim(id, "0") != "" && isHex(id)
+}
+
+// isHex reports whether s is an even number of hex characters.
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,14 @@
+package monitor
+
+import "context"
+
+// Transport delivers batches of events to an ingest backend.
+//
+// When Config.Transport is set, the shipper hands each batch to the Transport
+// instead of POSTing NDJSON to IngestURL. Batching, flush triggers, and retries
+// are still handled by the shipper; a Transport only needs to deliver.
+type Transport interface {
+	// Send delivers a single batch. A non-nil error causes the batch to be
+	// retried with backoff before it is dropped.
+	Send(ctx context.Context, batch []Event) error
+}
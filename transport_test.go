@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingTransport is a Transport that records every batch it receives.
+type recordingTransport struct {
+	mu      sync.Mutex
+	batches [][]Event
+	failN   int
+}
+
+func (r *recordingTransport) Send(ctx context.Context, batch []Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failN > 0 {
+		r.failN--
+		return errors.New("transport unavailable")
+	}
+	r.batches = append(r.batches, batch)
+	return nil
+}
+
+func (r *recordingTransport) eventCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, b := range r.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func TestTransport(t *testing.T) {
+	t.Run("Init starts shipper without IngestURL", func(t *testing.T) {
+		rt := &recordingTransport{}
+		if err := Init(Config{Service: "test-transport", DisableStdout: true, Transport: rt}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		defer Shutdown()
+
+		ctx := context.Background()
+		Emit(ctx, "test.one", nil)
+		Emit(ctx, "test.two", nil)
+		Flush()
+
+		if got := rt.eventCount(); got != 2 {
+			t.Errorf("transport received %d events, want 2", got)
+		}
+	})
+
+	t.Run("retries failed sends", func(t *testing.T) {
+		rt := &recordingTransport{failN: 1}
+		s := newShipper(defaultMonitor, &Config{
+			Service:    "test-transport",
+			Transport:  rt,
+			BatchSize:  10,
+			FlushEvery: time.Second,
+		})
+		s.events.push(Event{Name: "test.retry", Service: "test", Level: "info"})
+
+		s.doFlush(context.Background())
+
+		if got := rt.eventCount(); got != 1 {
+			t.Errorf("transport received %d events, want 1 after retry", got)
+		}
+	})
+}
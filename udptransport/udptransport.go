@@ -0,0 +1,160 @@
+// Package udptransport sends go-monitor events as fire-and-forget UDP
+// datagrams, one event per datagram, in the style of statsd.
+//
+// UDP is unreliable by design: datagrams can be lost, reordered, or silently
+// discarded by the receiver, and Send never reports an error, so the shipper
+// never retries. That suits high-volume metric events where occasional loss
+// is acceptable and low latency matters. Don't use it for events you can't
+// afford to lose (errors, audits); ship those with the default HTTP delivery
+// or another transport.
+//
+// Usage:
+//
+//	t, err := udptransport.New(udptransport.Config{
+//	    Addr:   "127.0.0.1:8125",
+//	    Format: udptransport.FormatStatsd,
+//	})
+//	monitor.Init(monitor.Config{Service: "api", Transport: t})
+//	monitor.RegisterShutdownHook(func(ctx context.Context) error { return t.Close() })
+package udptransport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync/atomic"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+// Datagram formats.
+const (
+	// FormatJSON sends each event's full JSON encoding. This is the default.
+	FormatJSON = "json"
+
+	// FormatStatsd sends "<name>:<value>|<type>" statsd lines. The value is
+	// read from the event's data["value"] (a number) and the type from
+	// data["type"] ("c", "g", "ms", "h", or "s"; default "g"). Events without
+	// a numeric value are skipped.
+	FormatStatsd = "statsd"
+)
+
+// defaultMaxPacketSize keeps datagrams under a typical Internet path MTU.
+const defaultMaxPacketSize = 1432
+
+// ErrAddrRequired is returned when Config.Addr is empty.
+var ErrAddrRequired = errors.New("udptransport: Config.Addr is required")
+
+// Config configures the UDP transport.
+type Config struct {
+	// Addr is the host:port to send datagrams to (e.g., "127.0.0.1:8125"). Required.
+	Addr string
+
+	// Format is FormatJSON or FormatStatsd. Default: FormatJSON.
+	Format string
+
+	// MaxPacketSize is the largest datagram sent; larger events are dropped.
+	// Default: 1432 bytes.
+	MaxPacketSize int
+}
+
+// Transport implements monitor.Transport over UDP.
+type Transport struct {
+	cfg     Config
+	conn    net.Conn
+	dropped atomic.Uint64
+}
+
+// New creates a UDP transport. UDP is connectionless, so this succeeds even
+// if nothing is listening at Addr.
+func New(cfg Config) (*Transport, error) {
+	if cfg.Addr == "" {
+		return nil, ErrAddrRequired
+	}
+	if cfg.Format == "" {
+		cfg.Format = FormatJSON
+	}
+	if cfg.Format != FormatJSON && cfg.Format != FormatStatsd {
+		return nil, fmt.Errorf("udptransport: unknown Format %q", cfg.Format)
+	}
+	if cfg.MaxPacketSize <= 0 {
+		cfg.MaxPacketSize = defaultMaxPacketSize
+	}
+
+	conn, err := net.Dial("udp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("udptransport: dial %s: %w", cfg.Addr, err)
+	}
+	return &Transport{cfg: cfg, conn: conn}, nil
+}
+
+// Send implements monitor.Transport. Each event is written as its own
+// datagram. Events that can't be encoded or sent are dropped and counted;
+// Send always returns nil so the shipper never retries.
+func (t *Transport) Send(ctx context.Context, batch []monitor.Event) error {
+	for _, event := range batch {
+		packet, ok := t.encode(event)
+		if !ok || len(packet) > t.cfg.MaxPacketSize {
+			t.dropped.Add(1)
+			continue
+		}
+		if _, err := t.conn.Write(packet); err != nil {
+			t.dropped.Add(1)
+		}
+	}
+	return nil
+}
+
+// encode returns the datagram for an event in the configured format.
+func (t *Transport) encode(event monitor.Event) ([]byte, bool) {
+	if t.cfg.Format == FormatStatsd {
+		return statsdLine(event)
+	}
+	b, err := json.Marshal(event)
+	return b, err == nil
+}
+
+// statsdLine formats an event as "<name>:<value>|<type>".
+func statsdLine(event monitor.Event) ([]byte, bool) {
+	data, ok := event.Data.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	var value string
+	switch v := data["value"].(type) {
+	case int:
+		value = strconv.Itoa(v)
+	case int64:
+		value = strconv.FormatInt(v, 10)
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, false
+	}
+
+	metricType := "g"
+	if mt, ok := data["type"].(string); ok {
+		switch mt {
+		case "c", "g", "ms", "h", "s":
+			metricType = mt
+		}
+	}
+	return []byte(event.Name + ":" + value + "|" + metricType), true
+}
+
+// Dropped returns how many events were dropped because they couldn't be
+// encoded, exceeded MaxPacketSize, or failed to send.
+func (t *Transport) Dropped() uint64 {
+	return t.dropped.Load()
+}
+
+// Close closes the UDP socket.
+func (t *Transport) Close() error {
+	return t.conn.Close()
+}
+
+var _ monitor.Transport = (*Transport)(nil)
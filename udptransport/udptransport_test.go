@@ -0,0 +1,123 @@
+package udptransport
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	monitor "github.com/aidenappl/go-monitor"
+)
+
+// listen starts a UDP listener on an ephemeral localhost port.
+func listen(t *testing.T) *net.UDPConn {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// receive reads n datagrams from conn.
+func receive(t *testing.T, conn *net.UDPConn, n int) []string {
+	t.Helper()
+	var packets []string
+	buf := make([]byte, 65536)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for len(packets) < n {
+		k, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatalf("ReadFromUDP() error = %v (got %d of %d packets)", err, len(packets), n)
+		}
+		packets = append(packets, string(buf[:k]))
+	}
+	return packets
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{}); err != ErrAddrRequired {
+		t.Errorf("New() error = %v, want ErrAddrRequired", err)
+	}
+	if _, err := New(Config{Addr: "127.0.0.1:8125", Format: "xml"}); err == nil {
+		t.Error("New() should reject an unknown Format")
+	}
+}
+
+func TestSendJSON(t *testing.T) {
+	server := listen(t)
+	tr, err := New(Config{Addr: server.LocalAddr().String()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer tr.Close()
+
+	batch := []monitor.Event{
+		{Name: "cache.hits", Service: "api", Level: "info"},
+		{Name: "cache.misses", Service: "api", Level: "info"},
+	}
+	if err := tr.Send(context.Background(), batch); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	packets := receive(t, server, 2)
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(packets[1]), &decoded); err != nil {
+		t.Fatalf("packet is not JSON: %v", err)
+	}
+	if decoded["name"] != "cache.misses" {
+		t.Errorf("name = %v, want cache.misses", decoded["name"])
+	}
+}
+
+func TestSendStatsd(t *testing.T) {
+	server := listen(t)
+	tr, err := New(Config{Addr: server.LocalAddr().String(), Format: FormatStatsd})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer tr.Close()
+
+	batch := []monitor.Event{
+		{Name: "requests", Data: map[string]any{"value": 1, "type": "c"}},
+		{Name: "no.value", Data: map[string]any{"other": 1}},
+		{Name: "latency", Data: map[string]any{"value": 12.5, "type": "ms"}},
+		{Name: "queue.depth", Data: map[string]any{"value": int64(7)}},
+	}
+	if err := tr.Send(context.Background(), batch); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	packets := receive(t, server, 3)
+	want := []string{"requests:1|c", "latency:12.5|ms", "queue.depth:7|g"}
+	for i := range want {
+		if packets[i] != want[i] {
+			t.Errorf("packet %d = %q, want %q", i, packets[i], want[i])
+		}
+	}
+	if got := tr.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1 (event without a value)", got)
+	}
+}
+
+func TestSendDropsWithoutError(t *testing.T) {
+	server := listen(t)
+	addr := server.LocalAddr().String()
+	server.Close() // nothing listening
+
+	tr, err := New(Config{Addr: addr, MaxPacketSize: 16})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer tr.Close()
+
+	batch := []monitor.Event{{Name: "an.event.too.large.for.sixteen.bytes"}}
+	if err := tr.Send(context.Background(), batch); err != nil {
+		t.Errorf("Send() error = %v, want nil (UDP never retries)", err)
+	}
+	if got := tr.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+}
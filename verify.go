@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// verifyTimeout bounds the connectivity check performed by Verify.
+const verifyTimeout = 10 * time.Second
+
+// Verify validates cfg as Init does, returning the same error for a config
+// Init would reject, and, if IngestURL is set, checks that the ingest endpoint
+// is reachable and accepts the configured credentials by POSTing an empty
+// NDJSON batch. Each of IngestURLs and Endpoints is checked the same way. No
+// events are sent and no shipper is started, so it is safe to call from CI or
+// deploy smoke tests before Init.
+func Verify(cfg Config) error {
+	if err := validateConfig(&cfg); err != nil {
+		return err
+	}
+	if cfg.IngestURL != "" {
+		if err := verifyEndpoint(&cfg); err != nil {
+			return err
+		}
+	}
+	for _, ep := range extraEndpoints(&cfg) {
+		if err := verifyEndpoint(endpointConfig(&cfg, ep, "")); err != nil {
+			return fmt.Errorf("%w (endpoint %s)", err, redactURL(ep.URL))
+		}
+	}
+	return nil
+}
+
+// verifyEndpoint performs Verify's check against cfg.IngestURL, which
+// validateConfig has already parsed.
+func verifyEndpoint(cfg *Config) error {
+	if _, err := ingestTransport(cfg.IngestProtocol); err != nil {
+		return err
+	}
+
+	// An OTLP collector rejects an empty body, so send an empty request object
+	var body []byte
+	if cfg.ExportFormat == ExportFormatOTLPLogs {
+		body = []byte("{}")
+	}
+	req, err := http.NewRequest(http.MethodPost, cfg.IngestURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("monitor: failed to create verify request: %w", err)
+	}
+	setIngestHeaders(req, cfg)
+
+	client := ingestClient(cfg, verifyTimeout)
+	if client.Timeout == 0 || client.Timeout > verifyTimeout {
+		bounded := *client
+		bounded.Timeout = verifyTimeout
+		client = &bounded
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("monitor: ingest endpoint unreachable: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("monitor: ingest endpoint rejected credentials (status %d)", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("monitor: ingest endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	t.Run("missing service", func(t *testing.T) {
+		if err := Verify(Config{}); err != ErrServiceRequired {
+			t.Errorf("Verify() error = %v, want ErrServiceRequired", err)
+		}
+	})
+
+	t.Run("rejects what Init rejects", func(t *testing.T) {
+		for name, cfg := range map[string]Config{
+			"MinLevel":   {Service: "test-verify", MinLevel: "loud"},
+			"QueueSize":  {Service: "test-verify", BatchSize: 10, QueueSize: 5},
+			"Encoder":    {Service: "test-verify", Encoder: LogfmtEncoder{}, ExportFormat: ExportFormatOTLPLogs},
+			"SampleRate": {Service: "test-verify", SampleRate: 2},
+			"IngestURL":  {Service: "test-verify", IngestURL: "ftp://ingest.example.com"},
+			"IngestURLs": {Service: "test-verify", IngestURLs: []string{"ingest.example.com/v1/events"}},
+			"Endpoints":  {Service: "test-verify", Endpoints: []Endpoint{{URL: "http://"}}},
+		} {
+			verifyErr := Verify(cfg)
+			if verifyErr == nil {
+				t.Errorf("%s: Verify() error = nil, want an error", name)
+				continue
+			}
+			if _, initErr := New(cfg); initErr == nil || initErr.Error() != verifyErr.Error() {
+				t.Errorf("%s: Verify() error = %v, New() error = %v, want the same", name, verifyErr, initErr)
+			}
+		}
+	})
+
+	t.Run("stdout only", func(t *testing.T) {
+		if err := Verify(Config{Service: "test-verify"}); err != nil {
+			t.Errorf("Verify() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("invalid scheme", func(t *testing.T) {
+		err := Verify(Config{Service: "test-verify", IngestURL: "ftp://ingest.example.com"})
+		if err == nil || !strings.Contains(err.Error(), "scheme") {
+			t.Errorf("Verify() error = %v, want scheme error", err)
+		}
+	})
+
+	t.Run("reachable and authenticated", func(t *testing.T) {
+		var gotKey, gotBody string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotKey = r.Header.Get("X-Api-Key")
+			body, _ := io.ReadAll(r.Body)
+			gotBody = string(body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		if err := Verify(Config{Service: "test-verify", IngestURL: server.URL, APIKey: "key-1"}); err != nil {
+			t.Fatalf("Verify() error = %v, want nil", err)
+		}
+		if gotKey != "key-1" {
+			t.Errorf("X-Api-Key = %v, want key-1", gotKey)
+		}
+		if gotBody != "" {
+			t.Errorf("body = %q, want empty batch", gotBody)
+		}
+	})
+
+	t.Run("rejected credentials", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		err := Verify(Config{Service: "test-verify", IngestURL: server.URL, APIKey: "bad"})
+		if err == nil || !strings.Contains(err.Error(), "credentials") {
+			t.Errorf("Verify() error = %v, want credentials error", err)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url := server.URL
+		server.Close()
+
+		err := Verify(Config{Service: "test-verify", IngestURL: url})
+		if err == nil || !strings.Contains(err.Error(), "unreachable") {
+			t.Errorf("Verify() error = %v, want unreachable error", err)
+		}
+	})
+
+	t.Run("does not start shipper", func(t *testing.T) {
+		defaultMonitor.shipper.Store(nil)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		_ = Verify(Config{Service: "test-verify", IngestURL: server.URL})
+		if defaultMonitor.shipper.Load() != nil {
+			t.Error("Verify() should not start the shipper")
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package monitor
+
+import "strings"
+
+// HeaderXRayTraceID is the header AWS load balancers and X-Ray-instrumented
+// services use to propagate trace context. Set Config.XRayHeader to it to
+// enable parsing in Middleware.
+const HeaderXRayTraceID = "X-Amzn-Trace-Id"
+
+// parseXRayHeader extracts the trace root and parent segment ID from an X-Ray
+// trace header such as "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1".
+// The root is returned as-is so trace_id matches the X-Ray trace ID. parent is
+// empty when absent or malformed; ok is false when there's no valid root.
+func parseXRayHeader(header string) (root, parent string, ok bool) {
+	for _, field := range strings.Split(header, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "Root":
+			root = value
+		case "Parent":
+			parent = strings.ToLower(value)
+		}
+	}
+
+	if !validXRayRoot(root) {
+		return "", "", false
+	}
+	if !isHexID(parent, 16) {
+		parent = ""
+	}
+	return root, parent, true
+}
+
+// validXRayRoot reports whether root has the X-Ray form
+// "1-<8 hex epoch>-<24 hex random>".
+func validXRayRoot(root string) bool {
+	parts := strings.Split(root, "-")
+	if len(parts) != 3 || parts[0] != "1" {
+		return false
+	}
+	return isHexID(strings.ToLower(parts[1]), 8) && isHexID(strings.ToLower(parts[2]), 24)
+}
@@ -0,0 +1,10 @@
+module github.com/aidenappl/go-monitor/zstdcompress
+
+go 1.25.5
+
+require (
+	github.com/aidenappl/go-monitor v0.0.0-20260206144105-41b30528e24e
+	github.com/klauspost/compress v1.18.0
+)
+
+replace github.com/aidenappl/go-monitor => ../
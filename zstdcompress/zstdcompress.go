@@ -0,0 +1,70 @@
+// Package zstdcompress registers zstd as a shipping compression algorithm.
+// Import it for its side effect and set Config.Compression:
+//
+//	import _ "github.com/aidenappl/go-monitor/zstdcompress"
+//
+//	monitor.Init(monitor.Config{
+//	    Service:     "my-service",
+//	    IngestURL:   "https://ingest.example.com/events",
+//	    Compression: monitor.CompressionZstd,
+//	})
+//
+// Config.CompressionLevel takes zstd levels 1 (fastest) to 22 (smallest),
+// mapped onto the encoder's speed presets; 0 selects the default.
+//
+// This package lives in its own module so the zstd dependency stays out of
+// the core go-monitor module.
+package zstdcompress
+
+import (
+	"sync"
+
+	monitor "github.com/aidenappl/go-monitor"
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	monitor.RegisterCompressor(monitor.CompressionZstd, compressor{})
+}
+
+// compressor implements monitor.Compressor with zstd.
+type compressor struct{}
+
+// encoders caches one *zstd.Encoder per encoder level. An Encoder used only
+// through EncodeAll is safe for concurrent use and pools its own state, so
+// batches don't allocate a new encoder each time.
+var encoders sync.Map // zstd.EncoderLevel -> *zstd.Encoder
+
+// ValidLevel implements monitor.Compressor.
+func (compressor) ValidLevel(level int) bool {
+	return level >= 0 && level <= 22
+}
+
+// Compress implements monitor.Compressor.
+func (compressor) Compress(payload []byte, level int) ([]byte, error) {
+	encLevel := zstd.SpeedDefault
+	if level > 0 {
+		encLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	enc, err := encoder(encLevel)
+	if err != nil {
+		return nil, err
+	}
+	return enc.EncodeAll(payload, make([]byte, 0, len(payload)/2)), nil
+}
+
+// encoder returns the shared encoder for level, creating it on first use.
+func encoder(level zstd.EncoderLevel) (*zstd.Encoder, error) {
+	if enc, ok := encoders.Load(level); ok {
+		return enc.(*zstd.Encoder), nil
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+	if err != nil {
+		return nil, err
+	}
+	actual, loaded := encoders.LoadOrStore(level, enc)
+	if loaded {
+		enc.Close()
+	}
+	return actual.(*zstd.Encoder), nil
+}
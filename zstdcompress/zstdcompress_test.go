@@ -0,0 +1,93 @@
+package zstdcompress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	monitor "github.com/aidenappl/go-monitor"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	payload := []byte(strings.Repeat(`{"name":"test.zstd","level":"info"}`+"\n", 50))
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatalf("zstd.NewReader() error = %v", err)
+	}
+	defer dec.Close()
+
+	for _, level := range []int{0, 1, 3, 22} {
+		compressed, err := compressor{}.Compress(payload, level)
+		if err != nil {
+			t.Fatalf("Compress(level %d) error = %v", level, err)
+		}
+		if len(compressed) >= len(payload) {
+			t.Errorf("level %d: compressed %d bytes to %d", level, len(payload), len(compressed))
+		}
+		got, err := dec.DecodeAll(compressed, nil)
+		if err != nil {
+			t.Fatalf("DecodeAll(level %d) error = %v", level, err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Errorf("level %d: round trip mismatch", level)
+		}
+	}
+	if (compressor{}).ValidLevel(23) || (compressor{}).ValidLevel(-1) {
+		t.Error("ValidLevel should reject levels outside 0..22")
+	}
+}
+
+func TestShipZstd(t *testing.T) {
+	type request struct {
+		encoding string
+		body     []byte
+	}
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{r.Header.Get("Content-Encoding"), body}
+	}))
+	defer server.Close()
+
+	if err := monitor.Init(monitor.Config{
+		Service:       "test-zstd",
+		IngestURL:     server.URL,
+		Compression:   monitor.CompressionZstd,
+		DisableStdout: true,
+	}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer monitor.Shutdown()
+
+	monitor.Emit(context.Background(), "test.zstd", map[string]any{"key": "value"})
+	monitor.Flush()
+
+	req := <-requests
+	if req.encoding != "zstd" {
+		t.Errorf("Content-Encoding = %q, want zstd", req.encoding)
+	}
+	dec, err := zstd.NewReader(bytes.NewReader(req.body))
+	if err != nil {
+		t.Fatalf("zstd.NewReader() error = %v", err)
+	}
+	defer dec.Close()
+	ndjson, err := io.ReadAll(dec)
+	if err != nil {
+		t.Fatalf("decompress error = %v", err)
+	}
+	if !strings.Contains(string(ndjson), `"name":"test.zstd"`) || !strings.HasSuffix(string(ndjson), "\n") {
+		t.Errorf("payload = %s, want the NDJSON event", ndjson)
+	}
+}
+
+func TestInitRejectsBadLevel(t *testing.T) {
+	err := monitor.Init(monitor.Config{Service: "test-zstd", Compression: monitor.CompressionZstd, CompressionLevel: 30})
+	if err == nil {
+		t.Error("Init() should reject zstd level 30")
+	}
+}